@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,11 +17,15 @@ import (
 	"auth-service/constants"
 	"auth-service/controllers"
 	"auth-service/db"
+	"auth-service/grpcserver"
+	"auth-service/middleware"
 	"auth-service/repositories"
 	"auth-service/router"
 	"auth-service/services"
 
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 func main() {
@@ -35,11 +40,65 @@ func main() {
 	svc := services.NewServices(repo, conf)
 	ctrl := controllers.NewController(svc, l)
 
-	r := router.InitUserRouter(ctrl)
-	srv := createServer(fmt.Sprintf("0.0.0.0:%s", os.Getenv(constants.AppPort)), r)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartRevocationStore(ctx, svc.RevocationStore())
+	mustStartKeyManager(ctx, svc.KeyManager())
+	mustLoadPasswordPolicy(svc.PasswordPolicy())
+	mustBootstrapAdmin(ctx, svc.RoleService(), conf.AppConfig().BootstrapAdminEmail())
+	go svc.LoginThrottle().Run(ctx)
+	go svc.AccountPurger().Run(ctx)
+
+	r := router.InitUserRouter(
+		ctrl,
+		middleware.Authenticate(svc.TokenService()),
+		middleware.AuthenticateClient(svc.OAuthService(), constants.ScopeTokensIntrospect),
+		middleware.AuthenticateClient(svc.OAuthService(), constants.ScopeEventsRead),
+		func(permission string) middleware.Middleware {
+			return middleware.RequirePermission(svc.RoleService(), permission)
+		},
+	)
+	srv := createServer(fmt.Sprintf("0.0.0.0:%s", os.Getenv(constants.AppPort)), middleware.RequestID(middleware.ClientIP(middleware.UserAgent(r))))
+
+	grpcSrv, healthSrv := grpcserver.NewServer(svc.UserService(), svc.OAuthService())
+	grpcListener := mustListen(fmt.Sprintf("0.0.0.0:%s", conf.AppConfig().GRPCPort()))
 
 	go startServer(srv)
-	shutdownServerGracefully(srv)
+	go startGRPCServer(grpcSrv, grpcListener)
+	shutdownServerGracefully(srv, grpcSrv, healthSrv)
+}
+
+// mustStartRevocationStore loads revoked tokens into memory and keeps them in sync in the background.
+func mustStartRevocationStore(ctx context.Context, store services.RevocationStore) {
+	if err := store.Load(ctx); err != nil {
+		log.Fatalf("failed to load revoked tokens: %v", err)
+	}
+	go store.Run(ctx)
+}
+
+// mustStartKeyManager loads the signing keys from disk and rotates them in the background.
+func mustStartKeyManager(ctx context.Context, keys services.KeyManager) {
+	if err := keys.Load(); err != nil {
+		log.Fatalf("failed to load signing keys: %v", err)
+	}
+	go keys.Run(ctx)
+}
+
+// mustLoadPasswordPolicy reads the breached password list, if one is configured.
+func mustLoadPasswordPolicy(policy services.PasswordPolicy) {
+	if err := policy.Load(); err != nil {
+		log.Fatalf("failed to load the breached password list: %v", err)
+	}
+}
+
+// mustBootstrapAdmin grants the admin role to the configured bootstrap admin, if any.
+func mustBootstrapAdmin(ctx context.Context, roles services.RoleService, email string) {
+	if email == "" {
+		return
+	}
+	if err := roles.BootstrapAdmin(ctx, email); err != nil {
+		log.Fatalf("failed to grant the bootstrap admin role: %v", err)
+	}
 }
 
 // mustConnectDB establishes a database connection and panics if it fails.
@@ -90,13 +149,33 @@ func startServer(srv *http.Server) {
 	}
 }
 
-// shutdownServerGracefully handles graceful server shutdown on interrupt signal.
-func shutdownServerGracefully(srv *http.Server) {
+// mustListen opens the TCP listener of the gRPC server and panics if it fails.
+func mustListen(addr string) net.Listener {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", addr, err)
+	}
+	return listener
+}
+
+// startGRPCServer serves the gRPC API on listener and logs fatal errors.
+func startGRPCServer(srv *grpc.Server, listener net.Listener) {
+	log.Println("starting gRPC server on..", listener.Addr())
+	if err := srv.Serve(listener); err != nil {
+		log.Fatalf("failed to start the gRPC server: %v", err)
+	}
+}
+
+// shutdownServerGracefully handles graceful server shutdown on interrupt signal. Health checks
+// report the gRPC API as not serving first, so that clients stop sending calls to it.
+func shutdownServerGracefully(srv *http.Server, grpcSrv *grpc.Server, healthSrv *health.Server) {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt)
 	<-stop
 
 	log.Println("shutting down server...")
+	healthSrv.Shutdown()
+	stopGRPCServer(grpcSrv)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -107,3 +186,18 @@ func shutdownServerGracefully(srv *http.Server) {
 
 	log.Println("server exited gracefully")
 }
+
+// stopGRPCServer waits for in-flight gRPC calls to finish, cancelling them after GRPCShutdownTimeout.
+func stopGRPCServer(srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(constants.GRPCShutdownTimeout):
+		srv.Stop()
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"strings"
+	"time"
+
 	"auth-service/constants"
 
 	"github.com/spf13/viper"
@@ -31,7 +34,48 @@ func NewConfiguration(appConf AppConfig) Configuration {
 type AppConfig interface {
 	BuildEnv() string
 	SecretKey() string
+	LegacyTokensUntil() time.Time
 	Port() string
+	AccessTokenTTL() time.Duration
+	RefreshTokenTTL() time.Duration
+	Issuer() string
+	Audience() string
+	SigningAlgorithm() string
+	SigningKeysDir() string
+	KeyRotationInterval() time.Duration
+	BootstrapAdminEmail() string
+	MailerDriver() string
+	MailOutboxDir() string
+	MailFrom() string
+	PasswordResetURL() string
+	PasswordResetTTL() time.Duration
+	PasswordHashAlgorithm() string
+	BcryptCost() int
+	Argon2Memory() uint32
+	Argon2Iterations() uint32
+	Argon2Parallelism() uint8
+	PasswordMinLength() int
+	PasswordMaxLength() int
+	PasswordBreachedList() string
+	EmailVerificationPolicy() string
+	EmailVerificationTTL() time.Duration
+	LoginAttemptStore() string
+	LoginLockoutThreshold() int
+	LoginIPThreshold() int
+	LoginLockoutDuration() time.Duration
+	LoginBackoffBase() time.Duration
+	MFAIssuer() string
+	MFAChallengeTTL() time.Duration
+	MagicLinkTTL() time.Duration
+	MagicLinkRateLimit() int
+	MagicLinkRateWindow() time.Duration
+	RegistrationMode() string
+	RegistrationAllowedDomains() []string
+	InviteTTL() time.Duration
+	AccountDeletionGrace() time.Duration
+	BlogServiceURL() string
+	ImpersonationTokenTTL() time.Duration
+	GRPCPort() string
 }
 
 type appConfig struct {
@@ -56,7 +100,312 @@ func (ac *appConfig) SecretKey() string {
 	return ac.env.GetString(constants.SecretKey)
 }
 
+// LegacyTokensUntil returns when HS256 tokens signed with SECRET_KEY stop being accepted; they
+// are refused from the start when it is unset
+func (ac *appConfig) LegacyTokensUntil() time.Time {
+	ac.env.AutomaticEnv()
+	return ac.env.GetTime(constants.LegacyTokensUntil)
+}
+
 func (ac *appConfig) Port() string {
 	ac.env.AutomaticEnv()
 	return ac.env.GetString(constants.AppPort)
 }
+
+// AccessTokenTTL returns the lifetime of access tokens
+func (ac *appConfig) AccessTokenTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.AccessTokenTTL), constants.DefaultAccessTokenTTL)
+}
+
+// RefreshTokenTTL returns the lifetime of refresh tokens
+func (ac *appConfig) RefreshTokenTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.RefreshTokenTTL), constants.DefaultRefreshTokenTTL)
+}
+
+// Issuer returns the iss claim of issued tokens, without a trailing slash
+func (ac *appConfig) Issuer() string {
+	ac.env.AutomaticEnv()
+	return strings.TrimSuffix(stringOrDefault(ac.env.GetString(constants.TokenIssuer), constants.DefaultTokenIssuer), "/")
+}
+
+// Audience returns the aud claim of access tokens issued at login
+func (ac *appConfig) Audience() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.TokenAudience), constants.DefaultTokenAudience)
+}
+
+// SigningAlgorithm returns the algorithm new signing keys are generated for (RS256 or EdDSA)
+func (ac *appConfig) SigningAlgorithm() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.JWTSigningAlg), constants.DefaultJWTSigningAlg)
+}
+
+// SigningKeysDir returns the directory holding the PEM encoded signing keys
+func (ac *appConfig) SigningKeysDir() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.JWTKeysDir), constants.DefaultJWTKeysDir)
+}
+
+// KeyRotationInterval returns how long a signing key is used before a new one is generated
+func (ac *appConfig) KeyRotationInterval() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.JWTKeyRotationInterval), constants.DefaultJWTKeyRotationInterval)
+}
+
+// BootstrapAdminEmail returns the email of a user that is granted the admin role at startup
+func (ac *appConfig) BootstrapAdminEmail() string {
+	ac.env.AutomaticEnv()
+	return strings.TrimSpace(ac.env.GetString(constants.BootstrapAdminEmail))
+}
+
+// MailerDriver returns how emails are delivered (stdout or file)
+func (ac *appConfig) MailerDriver() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.MailerDriver), constants.DefaultMailerDriver)
+}
+
+// MailOutboxDir returns the directory the file mailer writes messages to
+func (ac *appConfig) MailOutboxDir() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.MailOutboxDir), constants.DefaultMailOutboxDir)
+}
+
+// MailFrom returns the sender address of outgoing emails
+func (ac *appConfig) MailFrom() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.MailFrom), constants.DefaultMailFrom)
+}
+
+// PasswordResetURL returns the page password reset links point to
+func (ac *appConfig) PasswordResetURL() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.PasswordResetURL), constants.DefaultPasswordResetURL)
+}
+
+// PasswordResetTTL returns how long a password reset token can be used
+func (ac *appConfig) PasswordResetTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.PasswordResetTTL), constants.DefaultPasswordResetTTL)
+}
+
+// PasswordHashAlgorithm returns the algorithm new password hashes use (argon2id or bcrypt)
+func (ac *appConfig) PasswordHashAlgorithm() string {
+	ac.env.AutomaticEnv()
+	if ac.env.GetString(constants.PasswordHashAlgorithm) == constants.PasswordHashBcrypt {
+		return constants.PasswordHashBcrypt
+	}
+	return constants.DefaultPasswordHashAlgorithm
+}
+
+// BcryptCost returns the cost of new bcrypt hashes; values bcrypt does not accept fall back to the default
+func (ac *appConfig) BcryptCost() int {
+	ac.env.AutomaticEnv()
+	cost := ac.env.GetInt(constants.BcryptCost)
+	if cost < 4 || cost > 31 {
+		return constants.DefaultBcryptCost
+	}
+	return cost
+}
+
+// Argon2Memory returns the memory of new argon2id hashes in KiB
+func (ac *appConfig) Argon2Memory() uint32 {
+	ac.env.AutomaticEnv()
+	return uint32(intOrDefault(ac.env.GetInt(constants.Argon2Memory), constants.DefaultArgon2Memory))
+}
+
+// Argon2Iterations returns the number of passes of new argon2id hashes
+func (ac *appConfig) Argon2Iterations() uint32 {
+	ac.env.AutomaticEnv()
+	return uint32(intOrDefault(ac.env.GetInt(constants.Argon2Iterations), constants.DefaultArgon2Iterations))
+}
+
+// Argon2Parallelism returns the number of lanes of new argon2id hashes
+func (ac *appConfig) Argon2Parallelism() uint8 {
+	ac.env.AutomaticEnv()
+	p := ac.env.GetInt(constants.Argon2Parallelism)
+	if p <= 0 || p > 255 {
+		return constants.DefaultArgon2Parallelism
+	}
+	return uint8(p)
+}
+
+// PasswordMinLength returns the shortest password accepted when a password is set
+func (ac *appConfig) PasswordMinLength() int {
+	ac.env.AutomaticEnv()
+	return intOrDefault(ac.env.GetInt(constants.PasswordMinLength), constants.MinPasswordLength)
+}
+
+// PasswordMaxLength returns the longest password accepted when a password is set
+func (ac *appConfig) PasswordMaxLength() int {
+	ac.env.AutomaticEnv()
+	return intOrDefault(ac.env.GetInt(constants.PasswordMaxLength), constants.MaxPasswordLength)
+}
+
+// PasswordBreachedList returns the path of a file listing breached passwords, one per line.
+// It is empty when no list is configured.
+func (ac *appConfig) PasswordBreachedList() string {
+	ac.env.AutomaticEnv()
+	return strings.TrimSpace(ac.env.GetString(constants.PasswordBreachedList))
+}
+
+// EmailVerificationPolicy returns how users who have not verified their email are treated
+// (off, restricted or required). Unknown values fall back to the default.
+func (ac *appConfig) EmailVerificationPolicy() string {
+	ac.env.AutomaticEnv()
+	switch policy := ac.env.GetString(constants.EmailVerificationPolicy); policy {
+	case constants.EmailVerificationOff, constants.EmailVerificationRestricted, constants.EmailVerificationRequired:
+		return policy
+	default:
+		return constants.DefaultEmailVerificationPolicy
+	}
+}
+
+// EmailVerificationTTL returns how long an email verification token can be used
+func (ac *appConfig) EmailVerificationTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.EmailVerificationTTL), constants.DefaultEmailVerificationTTL)
+}
+
+// LoginAttemptStore returns where failed login counters are kept (postgres or memory)
+func (ac *appConfig) LoginAttemptStore() string {
+	ac.env.AutomaticEnv()
+	if ac.env.GetString(constants.LoginAttemptStore) == constants.LoginAttemptStoreMemory {
+		return constants.LoginAttemptStoreMemory
+	}
+	return constants.DefaultLoginAttemptStore
+}
+
+// LoginLockoutThreshold returns after how many failed logins an account is locked
+func (ac *appConfig) LoginLockoutThreshold() int {
+	ac.env.AutomaticEnv()
+	return intOrDefault(ac.env.GetInt(constants.LoginLockoutThreshold), constants.DefaultLoginLockoutThreshold)
+}
+
+// LoginIPThreshold returns after how many failed logins an IP is blocked
+func (ac *appConfig) LoginIPThreshold() int {
+	ac.env.AutomaticEnv()
+	return intOrDefault(ac.env.GetInt(constants.LoginIPThreshold), constants.DefaultLoginIPThreshold)
+}
+
+// LoginLockoutDuration returns how long a lockout lasts and failed logins are remembered
+func (ac *appConfig) LoginLockoutDuration() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.LoginLockoutDuration), constants.DefaultLoginLockoutDuration)
+}
+
+// LoginBackoffBase returns the delay after the first failed login; it doubles with every failure
+func (ac *appConfig) LoginBackoffBase() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.LoginBackoffBase), constants.DefaultLoginBackoffBase)
+}
+
+// MFAIssuer returns the issuer name authenticator apps show next to TOTP codes
+func (ac *appConfig) MFAIssuer() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.MFAIssuer), constants.DefaultMFAIssuer)
+}
+
+// MFAChallengeTTL returns how long the mfa_token of a password login can be exchanged
+func (ac *appConfig) MFAChallengeTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.MFAChallengeTTL), constants.DefaultMFAChallengeTTL)
+}
+
+// MagicLinkTTL returns how long a passwordless sign-in link can be used
+func (ac *appConfig) MagicLinkTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.MagicLinkTTL), constants.DefaultMagicLinkTTL)
+}
+
+// MagicLinkRateLimit returns how many sign-in links are mailed to an account per MagicLinkRateWindow
+func (ac *appConfig) MagicLinkRateLimit() int {
+	ac.env.AutomaticEnv()
+	return intOrDefault(ac.env.GetInt(constants.MagicLinkRateLimit), constants.DefaultMagicLinkRateLimit)
+}
+
+// MagicLinkRateWindow returns the window MagicLinkRateLimit applies to
+func (ac *appConfig) MagicLinkRateWindow() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.MagicLinkRateWindow), constants.DefaultMagicLinkRateWindow)
+}
+
+// RegistrationMode returns who may register (open, disabled, invite or domain). Unknown values
+// fall back to the default.
+func (ac *appConfig) RegistrationMode() string {
+	ac.env.AutomaticEnv()
+	switch mode := ac.env.GetString(constants.RegistrationMode); mode {
+	case constants.RegistrationOpen, constants.RegistrationDisabled, constants.RegistrationInvite, constants.RegistrationDomain:
+		return mode
+	default:
+		return constants.DefaultRegistrationMode
+	}
+}
+
+// RegistrationAllowedDomains returns the lowercased email domains that may register under the
+// domain registration mode, read from a comma-separated list
+func (ac *appConfig) RegistrationAllowedDomains() []string {
+	ac.env.AutomaticEnv()
+	domains := []string{}
+	for _, domain := range strings.Split(ac.env.GetString(constants.RegistrationAllowedDomains), ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// InviteTTL returns how long an invite code can be used
+func (ac *appConfig) InviteTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.InviteTTL), constants.DefaultInviteTTL)
+}
+
+// AccountDeletionGrace returns how long after DELETE /me an account is deleted
+func (ac *appConfig) AccountDeletionGrace() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.AccountDeletionGrace), constants.DefaultAccountDeletionGrace)
+}
+
+// BlogServiceURL returns the base URL of blog-service, or an empty string when exports leave out posts
+func (ac *appConfig) BlogServiceURL() string {
+	ac.env.AutomaticEnv()
+	return strings.TrimSuffix(ac.env.GetString(constants.BlogServiceURL), "/")
+}
+
+// stringOrDefault returns fallback when s is empty
+func stringOrDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// durationOrDefault returns fallback when d is not a positive duration
+func durationOrDefault(d, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// intOrDefault returns fallback when n is not positive
+func intOrDefault(n, fallback int) int {
+	if n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// ImpersonationTokenTTL returns the lifetime of access tokens issued to admins impersonating a user
+func (ac *appConfig) ImpersonationTokenTTL() time.Duration {
+	ac.env.AutomaticEnv()
+	return durationOrDefault(ac.env.GetDuration(constants.ImpersonationTokenTTL), constants.DefaultImpersonationTokenTTL)
+}
+
+// GRPCPort returns the port of the gRPC API for internal services
+func (ac *appConfig) GRPCPort() string {
+	ac.env.AutomaticEnv()
+	return stringOrDefault(ac.env.GetString(constants.GRPCPort), constants.DefaultGRPCPort)
+}
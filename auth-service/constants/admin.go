@@ -0,0 +1,22 @@
+package constants
+
+// Pagination of the admin list endpoints; the defaults match those of blog-service
+const (
+	DefaultPage     = 1
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Actions recorded in the audit log
+const (
+	AuditUserDisabled            = "user.disabled"
+	AuditUserEnabled             = "user.enabled"
+	AuditUserPasswordResetForced = "user.password_reset_forced"
+	AuditUserDeleted             = "user.deleted"
+	AuditUserUnlocked            = "user.unlocked"
+	AuditUserImpersonated        = "user.impersonated"
+	AuditRoleAssigned            = "role.assigned"
+	AuditRoleRemoved             = "role.removed"
+	AuditInviteCreated           = "invite.created"
+	AuditInviteRevoked           = "invite.revoked"
+)
@@ -0,0 +1,58 @@
+package constants
+
+// Types of the security events recorded in the audit log, next to the admin actions
+const (
+	EventLoginSucceeded     = "login.succeeded"
+	EventLoginFailed        = "login.failed"
+	EventUserRegistered     = "user.registered"
+	EventTokenRefreshed     = "token.refreshed"
+	EventRefreshTokenReused = "token.reuse_detected"
+	EventSessionRevoked     = "session.revoked"
+	EventAllTokensRevoked   = "tokens.revoked_all"
+	EventAccessTokenCreated = "personal_access_token.created"
+	EventAccessTokenRevoked = "personal_access_token.revoked"
+	EventDeletionScheduled  = "account.deletion_scheduled"
+	EventDeletionCancelled  = "account.deletion_cancelled"
+	EventAccountDeleted     = "account.deleted"
+	EventDataExported       = "account.exported"
+)
+
+// AuditActions are the actions the audit log can be filtered by
+var AuditActions = []string{
+	AuditUserDisabled, AuditUserEnabled, AuditUserPasswordResetForced, AuditUserDeleted, AuditUserUnlocked,
+	AuditUserImpersonated, AuditRoleAssigned, AuditRoleRemoved, AuditInviteCreated, AuditInviteRevoked,
+	EventLoginSucceeded, EventLoginFailed, EventUserRegistered, EventTokenRefreshed, EventRefreshTokenReused,
+	EventSessionRevoked, EventAllTokensRevoked, EventAccessTokenCreated, EventAccessTokenRevoked,
+	EventDeletionScheduled, EventDeletionCancelled, EventAccountDeleted, EventDataExported,
+}
+
+// Keys and values of the details of security events
+const (
+	EventDetailMethod   = "method"
+	EventDetailReason   = "reason"
+	EventDetailSession  = "session_id"
+	EventDetailTokenID  = "token_id"
+	EventDetailInviteID = "invite_id"
+
+	LoginMethodPassword  = "password"
+	LoginMethodMFA       = "mfa"
+	LoginMethodMagicLink = "magic_link"
+
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureThrottled          = "throttled"
+	LoginFailureDisabled           = "account_disabled"
+	LoginFailureResetRequired      = "password_reset_required"
+	LoginFailureEmailNotVerified   = "email_not_verified"
+	LoginFailureInvalidMFACode     = "invalid_mfa_code"
+)
+
+// Types of the events other services consume from the outbox_events table
+const (
+	// OutboxUserDeleted is written in the transaction that deletes a user; blog-service removes
+	// their posts when it reads it
+	OutboxUserDeleted = "user.deleted"
+
+	// OutboxAdvisoryLockKey names the Postgres advisory lock that makes outbox events commit in
+	// id order
+	OutboxAdvisoryLockKey = 7324071
+)
@@ -0,0 +1,16 @@
+package constants
+
+// contextKey is the type of keys stored in request contexts
+type contextKey string
+
+// ClaimsKey is the key used to store the authenticated token claims in the context.
+const ClaimsKey contextKey = "claims"
+
+// ClientIPKey is the key used to store the IP address of the caller in the context.
+const ClientIPKey contextKey = "client_ip"
+
+// UserAgentKey is the key used to store the User-Agent header of the caller in the context.
+const UserAgentKey contextKey = "user_agent"
+
+// RequestIDKey is the key used to store the request ID in the context.
+const RequestIDKey contextKey = "request_id"
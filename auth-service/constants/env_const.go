@@ -1,10 +1,69 @@
 package constants
 
+import "time"
+
 const (
-	AppDebug  = "APP_DEBUG"
-	SecretKey = "SECRET_KEY"
-	BuildEnv  = "BUILD_ENV"
-	AppPort   = "APP_PORT"
+	AppDebug          = "APP_DEBUG"
+	SecretKey         = "SECRET_KEY"
+	LegacyTokensUntil = "LEGACY_TOKENS_UNTIL"
+	BuildEnv          = "BUILD_ENV"
+	AppPort           = "APP_PORT"
+
+	AccessTokenTTL  = "ACCESS_TOKEN_TTL"
+	RefreshTokenTTL = "REFRESH_TOKEN_TTL"
+
+	TokenIssuer   = "TOKEN_ISSUER"
+	TokenAudience = "TOKEN_AUDIENCE"
+
+	JWTSigningAlg          = "JWT_SIGNING_ALG"
+	JWTKeysDir             = "JWT_KEYS_DIR"
+	JWTKeyRotationInterval = "JWT_KEY_ROTATION_INTERVAL"
+
+	MailerDriver     = "MAILER_DRIVER"
+	MailOutboxDir    = "MAIL_OUTBOX_DIR"
+	MailFrom         = "MAIL_FROM"
+	PasswordResetURL = "PASSWORD_RESET_URL"
+	PasswordResetTTL = "PASSWORD_RESET_TTL"
+
+	PasswordHashAlgorithm = "PASSWORD_HASH_ALGORITHM"
+	BcryptCost            = "BCRYPT_COST"
+	Argon2Memory          = "ARGON2_MEMORY_KIB"
+	Argon2Iterations      = "ARGON2_ITERATIONS"
+	Argon2Parallelism     = "ARGON2_PARALLELISM"
+	PasswordMinLength     = "PASSWORD_MIN_LENGTH"
+	PasswordMaxLength     = "PASSWORD_MAX_LENGTH"
+	PasswordBreachedList  = "PASSWORD_BREACHED_LIST"
+
+	EmailVerificationPolicy = "EMAIL_VERIFICATION_POLICY"
+	EmailVerificationTTL    = "EMAIL_VERIFICATION_TTL"
+
+	LoginAttemptStore     = "LOGIN_ATTEMPT_STORE"
+	LoginLockoutThreshold = "LOGIN_LOCKOUT_THRESHOLD"
+	LoginIPThreshold      = "LOGIN_IP_THRESHOLD"
+	LoginLockoutDuration  = "LOGIN_LOCKOUT_DURATION"
+	LoginBackoffBase      = "LOGIN_BACKOFF_BASE"
+
+	MFAIssuer       = "MFA_ISSUER"
+	MFAChallengeTTL = "MFA_CHALLENGE_TTL"
+
+	MagicLinkTTL        = "MAGIC_LINK_TTL"
+	MagicLinkRateLimit  = "MAGIC_LINK_RATE_LIMIT"
+	MagicLinkRateWindow = "MAGIC_LINK_RATE_WINDOW"
+
+	RegistrationMode           = "REGISTRATION_MODE"
+	RegistrationAllowedDomains = "REGISTRATION_ALLOWED_DOMAINS"
+	InviteTTL                  = "INVITE_TTL"
+
+	AccountDeletionGrace = "ACCOUNT_DELETION_GRACE"
+	BlogServiceURL       = "BLOG_SERVICE_URL"
+
+	ImpersonationTokenTTL = "IMPERSONATION_TOKEN_TTL"
+
+	GRPCPort = "GRPC_PORT"
+
+	// BootstrapAdminEmail names an existing user who gets the admin role at startup, so that the
+	// first admin can be created without database access
+	BootstrapAdminEmail = "BOOTSTRAP_ADMIN_EMAIL"
 
 	PostgresHost       = "POSTGRES_HOST"
 	PostgresPort       = "POSTGRES_PORT"
@@ -14,3 +73,146 @@ const (
 	DatabaseSSLMode    = "DB_SSL_MODE"
 	DatabaseDefaultSSL = "disable"
 )
+
+// Token lifetimes used when the corresponding environment variables are not set
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	// RevocationSyncInterval is how often the in-memory revocation cache is reloaded from the database
+	RevocationSyncInterval = 30 * time.Second
+)
+
+// Token claim defaults. The issuer must be the URL auth-service is reachable at, since OIDC
+// clients fetch the discovery document from it.
+const (
+	DefaultTokenIssuer   = "http://localhost:8081"
+	DefaultTokenAudience = "blog-service"
+)
+
+// Mail delivery. Only local development drivers exist: stdout logs messages and file writes
+// each message to MAIL_OUTBOX_DIR.
+const (
+	MailerDriverStdout = "stdout"
+	MailerDriverFile   = "file"
+
+	DefaultMailerDriver  = MailerDriverStdout
+	DefaultMailOutboxDir = "mail"
+	DefaultMailFrom      = "no-reply@localhost"
+)
+
+// Password reset defaults. The reset URL is the page that reads the token from its query string
+// and posts it to /password/reset together with the new password.
+const (
+	DefaultPasswordResetURL = "http://localhost:3000/reset-password"
+	DefaultPasswordResetTTL = 30 * time.Minute
+
+	// MinPasswordLength is the shortest password accepted when a password is set, unless
+	// PASSWORD_MIN_LENGTH says otherwise
+	MinPasswordLength = 8
+	// MaxPasswordLength caps passwords so that hashing stays cheap to request
+	MaxPasswordLength = 128
+)
+
+// Password hashing. New hashes use PASSWORD_HASH_ALGORITHM; stored hashes of the other algorithm or
+// with other parameters still verify and are replaced on the next successful login. The argon2id
+// defaults follow the second recommendation of RFC 9106 section 4.
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+
+	DefaultPasswordHashAlgorithm = PasswordHashArgon2id
+	DefaultBcryptCost            = 12
+	DefaultArgon2Memory          = 64 * 1024 // KiB
+	DefaultArgon2Iterations      = 3
+	DefaultArgon2Parallelism     = 4
+)
+
+// Email verification policies. Under off, unverified users are treated like verified ones;
+// under restricted they can log in but their access tokens only carry blog:read; under required
+// they cannot log in until they verified their email.
+const (
+	EmailVerificationOff        = "off"
+	EmailVerificationRestricted = "restricted"
+	EmailVerificationRequired   = "required"
+
+	DefaultEmailVerificationPolicy = EmailVerificationRestricted
+	DefaultEmailVerificationTTL    = 24 * time.Hour
+)
+
+// Brute-force protection. Every failed login of an account or from an IP delays the next attempt
+// exponentially, starting at LOGIN_BACKOFF_BASE. Reaching the threshold locks the account (or
+// blocks the IP) for LOGIN_LOCKOUT_DURATION, which is also how long failures are remembered.
+const (
+	LoginAttemptStorePostgres = "postgres"
+	LoginAttemptStoreMemory   = "memory"
+
+	DefaultLoginAttemptStore     = LoginAttemptStorePostgres
+	DefaultLoginLockoutThreshold = 5
+	DefaultLoginIPThreshold      = 20
+	DefaultLoginLockoutDuration  = 15 * time.Minute
+	DefaultLoginBackoffBase      = time.Second
+
+	// LoginAttemptPruneInterval is how often counters without recent failures are deleted
+	LoginAttemptPruneInterval = 10 * time.Minute
+)
+
+// Two-factor authentication. MFA_ISSUER is the account issuer shown by authenticator apps;
+// MFA_CHALLENGE_TTL is how long the mfa_token returned by a password login can be exchanged.
+const (
+	DefaultMFAIssuer       = "blog-services"
+	DefaultMFAChallengeTTL = 5 * time.Minute
+)
+
+// Signing key defaults and rotation timings
+const (
+	DefaultJWTSigningAlg          = SigningAlgRS256
+	DefaultJWTKeysDir             = "keys"
+	DefaultJWTKeyRotationInterval = 30 * 24 * time.Hour
+
+	// KeyPublishLead is how long a new key is published in the JWKS before it signs tokens,
+	// so verifiers that cache the JWKS learn about it first. It must exceed JWKSMaxAge.
+	KeyPublishLead = 10 * time.Minute
+	// KeySyncInterval is how often the key directory is reloaded and rotation is checked
+	KeySyncInterval = time.Minute
+	// JWKSMaxAge is how long clients may cache the JWKS response
+	JWKSMaxAge = 5 * time.Minute
+)
+
+// Passwordless login. A sign-in link can be used once within MAGIC_LINK_TTL, from the browser that
+// requested it. At most MAGIC_LINK_RATE_LIMIT links are mailed to an account per MAGIC_LINK_RATE_WINDOW.
+const (
+	DefaultMagicLinkTTL        = 15 * time.Minute
+	DefaultMagicLinkRateLimit  = 3
+	DefaultMagicLinkRateWindow = time.Hour
+)
+
+// Registration modes. Under open anyone can register; under disabled nobody can; under invite a
+// valid invite code is required; under domain the email must belong to one of
+// REGISTRATION_ALLOWED_DOMAINS unless a valid invite code is given. Invite codes can be used
+// once within INVITE_TTL.
+const (
+	RegistrationOpen     = "open"
+	RegistrationDisabled = "disabled"
+	RegistrationInvite   = "invite"
+	RegistrationDomain   = "domain"
+
+	DefaultRegistrationMode = RegistrationOpen
+	DefaultInviteTTL        = 7 * 24 * time.Hour
+)
+
+// Account deletion and data export. DELETE /me schedules the deletion ACCOUNT_DELETION_GRACE
+// ahead; until then the user can cancel it. Due accounts are purged every AccountPurgeInterval.
+// Exports include the posts of the user fetched from BLOG_SERVICE_URL, if it is set.
+const (
+	DefaultAccountDeletionGrace = 7 * 24 * time.Hour
+	AccountPurgeInterval        = 10 * time.Minute
+	AccountPurgeBatchSize       = 100
+	BlogServiceTimeout          = 10 * time.Second
+	// BlogServicePostsPath is the blog-service endpoint that returns every post of the caller
+	BlogServicePostsPath = "/api/v1/me/blogs"
+)
+
+// DefaultImpersonationTokenTTL is how long the access token of an admin impersonating a user
+// lasts. It cannot be refreshed, so a longer session takes a new token and a new audit entry.
+const DefaultImpersonationTokenTTL = 10 * time.Minute
@@ -9,6 +9,109 @@ const (
 	TokenNotValidYet             = "token is not valid yet"
 	TokenMalformed               = "token is malformed"
 	TokenInvalidIssuer           = "invalid issuer"
+	TokenInvalidAudience         = "invalid audience"
+	TokenInvalidType             = "invalid token type"
+	TokenRevoked                 = "token revoked"
+	TokenMissing                 = "missing or malformed authorization header"
+	TokenUnknownKey              = "token signed with an unknown key"
 
 	ErrInvalidEmailOrPass = "username or password error"
+
+	// RFC 6749 error codes
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrUnauthorizedClient      = "unauthorized_client"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrAccessDenied            = "access_denied"
+	OAuthErrServerError             = "server_error"
+
+	ErrInvalidClientName     = "client name is required"
+	ErrInvalidRedirectURI    = "redirect URIs must be absolute https URLs (http is allowed for localhost) without a fragment"
+	ErrInvalidAllowedScope   = "allowed scopes must be service scopes and are only available to confidential clients"
+	ErrClientNotFound        = "client not found"
+	ErrClientNotConfidential = "client is not confidential"
+	ErrClientSecretNotFound  = "client secret not found"
+	ErrUserTokenRequired     = "this endpoint requires a user access token"
+	ErrClientTokenNotAllowed = "tokens issued to OAuth clients cannot be used for this endpoint"
+
+	ErrUserNotFound            = "user not found"
+	ErrRoleNotFound            = "role not found"
+	ErrPermissionDenied        = "permission denied"
+	ErrCannotRemoveOwnAdmin    = "admins cannot remove their own admin role"
+	ErrCannotDisableSelf       = "admins cannot disable their own account"
+	ErrCannotDeleteSelf        = "admins cannot delete their own account"
+	ErrCannotImpersonateSelf   = "admins cannot impersonate themselves"
+	ErrImpersonationReason     = "a reason is required to impersonate a user"
+	ErrImpersonationNotAllowed = "this endpoint cannot be used while impersonating a user"
+	ErrInvalidPage             = "page must be a positive number"
+	ErrInvalidPageSize         = "page_size must be between 1 and %d"
+	ErrTooManyUserIDs          = "at most %d users can be fetched at once"
+
+	ErrAccountDisabled       = "account is disabled"
+	ErrPasswordResetRequired = "a password reset is required, use the link mailed to you"
+
+	ErrInvalidResetToken = "invalid or expired password reset token"
+	ErrPasswordTooShort  = "password must be at least %d characters long"
+	ErrPasswordTooLong   = "password must be at most %d characters long"
+	ErrPasswordBreached  = "password appears in a list of breached passwords, choose another one"
+	ErrUnknownHashFormat = "unknown password hash format"
+
+	ErrInvalidUsername         = "usernames are 3 to 30 characters of lowercase letters, digits and underscores"
+	ErrUsernameTaken           = "username is already taken"
+	ErrFieldTooLong            = "%s must be at most %d characters long"
+	ErrInvalidAvatarURL        = "avatar URL must be an absolute http or https URL"
+	ErrInvalidEmail            = "invalid email address"
+	ErrEmailTaken              = "email address is already in use"
+	ErrSameEmail               = "new email address is the same as the current one"
+	ErrInvalidCurrentPassword  = "current password is incorrect"
+	ErrInvalidEmailChangeToken = "invalid or expired email change token"
+
+	ErrInvalidVerificationToken = "invalid or expired email verification token"
+	ErrInvalidMagicLink         = "invalid or expired sign-in link"
+	ErrMagicLinkOtherBrowser    = "open the sign-in link in the browser you requested it from"
+	ErrEmailNotVerified         = "email address is not verified"
+
+	ErrAccountLocked        = "account is temporarily locked after too many failed login attempts"
+	ErrTooManyLoginAttempts = "too many failed login attempts, try again later"
+
+	ErrMFAAlreadyEnabled = "two-factor authentication is already enabled"
+	ErrMFANotEnrolled    = "two-factor enrollment has not been started"
+	ErrMFANotEnabled     = "two-factor authentication is not enabled"
+	ErrMFACodeRequired   = "authentication code required"
+	ErrInvalidMFACode    = "invalid authentication code"
+	ErrInvalidMFAToken   = "invalid or expired mfa token"
+
+	ErrUnsupportedSigningAlg = "unsupported signing algorithm"
+	ErrUnsupportedKeyType    = "unsupported private key type"
+	ErrInvalidKeyPEM         = "invalid private key PEM"
+	ErrNoSigningKey          = "no signing key available"
+
+	ErrSessionNotFound     = "session not found"
+	ErrInvalidRefreshToken = "invalid refresh token"
+	ErrRefreshTokenReused  = "refresh token reuse detected"
+
+	ErrPersonalAccessTokenNotFound   = "personal access token not found"
+	ErrInvalidPersonalAccessToken    = "invalid, expired or revoked personal access token"
+	ErrInvalidTokenScope             = "scope must be one or more of: %s"
+	ErrInvalidTokenExpiry            = "expires_in_days must be between 1 and %d"
+	ErrTooManyPersonalAccessTokens   = "at most %d personal access tokens can be active at once"
+	ErrPersonalAccessTokenNotAllowed = "personal access tokens cannot be used for this endpoint"
+
+	ErrInvalidAuditAction = "action must be one of: %s"
+	ErrInvalidTimeRange   = "from and to must be RFC 3339 times, with from before to"
+
+	ErrRegistrationClosed    = "registration is closed"
+	ErrInviteRequired        = "an invite code is required to register"
+	ErrInvalidInvite         = "invalid, expired or already used invite code"
+	ErrEmailDomainNotAllowed = "registration is limited to email addresses of: %s"
+	ErrInviteNotFound        = "invite not found"
+
+	ErrDeletionAlreadyScheduled = "the account is already scheduled for deletion"
+	ErrDeletionNotScheduled     = "the account is not scheduled for deletion"
+	ErrBlogServiceUnavailable   = "posts could not be fetched from blog-service"
+	ErrInvalidEventCursor       = "after must be a non-negative event id"
+	ErrInvalidEventLimit        = "limit must be between 1 and %d"
 )
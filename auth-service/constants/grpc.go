@@ -0,0 +1,21 @@
+package constants
+
+import "time"
+
+// gRPC API for internal services. It listens on GRPC_PORT next to the HTTP server; callers
+// authenticate as confidential clients with HTTP Basic credentials in the authorization metadata.
+const (
+	DefaultGRPCPort = "9081"
+
+	// GRPCMetadataAuthorization is the metadata key carrying the client credentials
+	GRPCMetadataAuthorization = "authorization"
+
+	// GRPCServiceName is the name health checks report the status of the auth API under
+	GRPCServiceName = "auth.v1.AuthService"
+
+	// GRPCShutdownTimeout is how long in-flight calls may take once the server is stopping
+	GRPCShutdownTimeout = 5 * time.Second
+
+	// MaxBatchGetUsers is how many users internal services can look up in one call
+	MaxBatchGetUsers = 100
+)
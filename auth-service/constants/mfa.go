@@ -0,0 +1,21 @@
+package constants
+
+import "time"
+
+// TOTP parameters (RFC 6238). These are the defaults every authenticator app supports, so they
+// are not configurable.
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 * time.Second
+	// TOTPSkew is how many steps before and after the current one are accepted for clock drift
+	TOTPSkew = 1
+)
+
+const (
+	// RecoveryCodeCount is how many one-time recovery codes are generated at once
+	RecoveryCodeCount = 10
+	// MFAChallengeMaxAttempts is how many codes can be tried with one mfa_token
+	MFAChallengeMaxAttempts = 5
+	// MFAQRCodeSize is the width and height in pixels of enrollment QR codes
+	MFAQRCodeSize = 256
+)
@@ -0,0 +1,25 @@
+package constants
+
+import "time"
+
+// Personal access tokens
+const (
+	// PersonalAccessTokenPrefix starts every personal access token, so that they are told apart
+	// from JWTs and recognized by secret scanners
+	PersonalAccessTokenPrefix = "pat_"
+	// PersonalAccessTokenDisplayLength is how many leading characters of a token are kept to show it
+	PersonalAccessTokenDisplayLength = 12
+	// MaxPersonalAccessTokenNameLength matches the name column of the personal_access_tokens table
+	MaxPersonalAccessTokenNameLength = 100
+	// MaxPersonalAccessTokenDays is the longest expiry a token can be created with
+	MaxPersonalAccessTokenDays = 366
+	// MaxPersonalAccessTokens is how many unrevoked, unexpired tokens a user may hold
+	MaxPersonalAccessTokens = 50
+	// PersonalAccessTokenTouchInterval limits how often the last use of a token is written
+	PersonalAccessTokenTouchInterval = time.Minute
+	// TokenTypePersonalAccessToken is the token_type introspection reports for personal access tokens
+	TokenTypePersonalAccessToken = "personal_access_token"
+)
+
+// PersonalAccessTokenScopes are the scopes a personal access token may be created with
+var PersonalAccessTokenScopes = []string{ScopeBlogRead, ScopeBlogWrite}
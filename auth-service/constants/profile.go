@@ -0,0 +1,12 @@
+package constants
+
+// Profile field limits; they match the column sizes of the users table
+const (
+	MaxNameLength        = 100
+	MaxDisplayNameLength = 100
+	MaxBioLength         = 500
+	MaxAvatarURLLength   = 2048
+
+	// UsernamePattern is what usernames look like; they are stored in lower case
+	UsernamePattern = `^[a-z0-9_]{3,30}$`
+)
@@ -0,0 +1,15 @@
+package constants
+
+// Roles seeded by the roles migration
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+)
+
+// Permissions granted through roles. They are carried in the permissions claim of access tokens
+// so that other services can authorize without calling auth-service.
+const (
+	PermissionRolesManage   = "roles:manage"
+	PermissionUsersManage   = "users:manage"
+	PermissionBlogDeleteAny = "blog:delete:any"
+)
@@ -0,0 +1,103 @@
+package constants
+
+import "time"
+
+// Token type hints and types used by introspection (RFC 7662)
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+	TokenTypeBearer           = "Bearer"
+)
+
+// Access token signing algorithms
+const (
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+// MaxUserAgentLength is how much of the User-Agent header is kept with a session
+const MaxUserAgentLength = 512
+
+// JWTHeaderKeyID is the JOSE header naming the key a token was signed with
+const JWTHeaderKeyID = "kid"
+
+// JWTHeaderType is the JOSE header naming the type of a token. Access tokens are typed
+// AccessTokenJWTType (RFC 9068) so that an ID token, signed with the same keys, never passes for one.
+const (
+	JWTHeaderType      = "typ"
+	AccessTokenJWTType = "at+jwt"
+)
+
+// Endpoint paths advertised in the OpenID Connect discovery document
+const (
+	PathOpenIDConfiguration = "/.well-known/openid-configuration"
+	PathJWKS                = "/.well-known/jwks.json"
+	PathUserInfo            = "/userinfo"
+	PathIntrospect          = "/introspect"
+	PathAuthorize           = "/authorize"
+	PathToken               = "/token"
+	PathClients             = "/clients"
+	PathVerifyEmail         = "/verify-email"
+	PathConfirmEmailChange  = "/me/email/confirm"
+	PathMagicLink           = "/login/magic"
+	PathMagicLinkCallback   = "/login/magic/callback"
+	PathEvents              = "/events"
+)
+
+// MagicLinkNonceCookie binds a sign-in link to the browser that asked for it
+const MagicLinkNonceCookie = "magic_link_nonce"
+
+// OAuth 2.0 grant and response types
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+	ResponseTypeCode           = "code"
+
+	// CodeChallengeMethodS256 is the only PKCE method accepted; "plain" offers no protection
+	CodeChallengeMethodS256 = "S256"
+
+	// AuthorizationCodeTTL is how long an authorization code can be exchanged
+	AuthorizationCodeTTL = time.Minute
+
+	// MaxActiveClientSecrets is how many secrets a confidential client holds at once; rotating
+	// revokes the oldest so that the previous secret keeps working while it is being replaced
+	MaxActiveClientSecrets = 2
+)
+
+// Client authentication methods at the token endpoint (RFC 7591 section 2)
+const (
+	ClientAuthNone  = "none"
+	ClientAuthBasic = "client_secret_basic"
+	ClientAuthPost  = "client_secret_post"
+)
+
+// SupportedScopes are the scopes clients may request at the authorization endpoint
+var SupportedScopes = []string{ScopeOpenID, ScopeEmail, ScopeProfile, ScopeBlogRead, ScopeBlogWrite}
+
+// ServiceScopes are the scopes confidential clients may be allowed for the client_credentials grant
+var ServiceScopes = []string{ScopeBlogRead, ScopeBlogWrite, ScopeUsersRead, ScopeTokensIntrospect, ScopeEventsRead}
+
+// API scopes of access tokens issued to clients
+const (
+	ScopeBlogRead         = "blog:read"
+	ScopeBlogWrite        = "blog:write"
+	ScopeUsersRead        = "users:read"
+	ScopeTokensIntrospect = "tokens:introspect"
+	ScopeEventsRead       = "events:read"
+)
+
+// OpenID Connect scopes and claims
+const (
+	ScopeOpenID       = "openid"
+	ScopeEmail        = "email"
+	ScopeProfile      = "profile"
+	SubjectTypePublic = "public"
+)
+
+// Request IDs. A valid X-Request-ID header of the caller is kept, so that a request can be followed
+// across services; otherwise one is generated. Either way it is echoed in the response.
+const (
+	HeaderRequestID    = "X-Request-ID"
+	MaxRequestIDLength = 64
+)
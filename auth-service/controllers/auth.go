@@ -2,10 +2,15 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 
+	"auth-service/constants"
 	"auth-service/models"
 	"auth-service/services"
+	"auth-service/utils"
 
 	"github.com/sirupsen/logrus"
 )
@@ -14,22 +19,28 @@ import (
 type AuthController interface {
 	Register(w http.ResponseWriter, r *http.Request)
 	Login(w http.ResponseWriter, r *http.Request)
+	LoginMFA(w http.ResponseWriter, r *http.Request)
 	Verify(w http.ResponseWriter, r *http.Request)
+	Introspect(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
+	LogoutAll(w http.ResponseWriter, r *http.Request)
 	RefreshToken(w http.ResponseWriter, r *http.Request)
+	UserInfo(w http.ResponseWriter, r *http.Request)
 }
 
 // implement UserController interface
 type authController struct {
-	service services.UserService
-	log     *logrus.Logger
+	service  services.UserService
+	tokenSvc services.TokenService
+	log      *logrus.Logger
 }
 
 // NewAuthController returns a new instance of authController
-func NewAuthController(svc services.UserService, l *logrus.Logger) AuthController {
+func NewAuthController(svc services.UserService, tokenSvc services.TokenService, l *logrus.Logger) AuthController {
 	return &authController{
-		service: svc,
-		log:     l,
+		service:  svc,
+		tokenSvc: tokenSvc,
+		log:      l,
 	}
 }
 
@@ -67,6 +78,7 @@ func (c *authController) Login(w http.ResponseWriter, r *http.Request) {
 	status, data, err := c.service.Login(ctx, req)
 	if err != nil {
 		logrus.Warnf("Error logging in user: %v", err)
+		setRetryAfter(w, err)
 		RespondWithError(w, status, err.Error())
 		return
 	}
@@ -74,31 +86,156 @@ func (c *authController) Login(w http.ResponseWriter, r *http.Request) {
 	RespondWithJSON(w, status, data, "")
 }
 
-// Verify handles  JWT token verification
+// LoginMFA handles the second login step of users with two-factor authentication: it exchanges
+// the mfa_token returned by Login and a TOTP or recovery code for tokens
+func (c *authController) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req models.MFALoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MFAToken == "" || strings.TrimSpace(req.Code) == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, data, err := c.service.LoginMFA(r.Context(), req)
+	if err != nil {
+		c.log.Warnf("Error completing mfa login: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, data, "")
+}
+
+// Verify handles  JWT token verification of the bearer token in the Authorization header
 func (c *authController) Verify(w http.ResponseWriter, r *http.Request) {
-	tokenString := r.Header.Get("Authorization")
-	if tokenString == "" {
-		RespondWithError(w, http.StatusUnauthorized, "Missing Authorization header")
+	tokenString, ok := utils.ExtractBearerToken(r)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
 		return
 	}
 
 	// call the service to verify the token
-	verified, err := c.service.VerifyToken(r.Context(), tokenString)
+	status, claims, err := c.service.VerifyToken(r.Context(), tokenString)
 	if err != nil {
-		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		RespondWithError(w, status, err.Error())
 		return
 	}
 
-	RespondWithJSON(w, http.StatusOK, verified, "")
+	RespondWithJSON(w, status, claims, "")
+}
+
+// Introspect handles RFC 7662 token introspection for authenticated clients. The token is read from a form encoded
+// body as the RFC prescribes, or from a JSON body for internal callers.
+func (c *authController) Introspect(w http.ResponseWriter, r *http.Request) {
+	var req models.IntrospectionRequest
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			RespondWithOAuthError(w, http.StatusBadRequest, constants.OAuthErrInvalidRequest, "invalid request payload")
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			RespondWithOAuthError(w, http.StatusBadRequest, constants.OAuthErrInvalidRequest, "invalid form body")
+			return
+		}
+		req.Token = r.PostForm.Get("token")
+		req.TokenTypeHint = r.PostForm.Get("token_type_hint")
+	}
 
+	if req.Token == "" {
+		RespondWithOAuthError(w, http.StatusBadRequest, constants.OAuthErrInvalidRequest, "token is required")
+		return
+	}
+
+	resp, err := c.tokenSvc.Introspect(r.Context(), req.Token, req.TokenTypeHint)
+	if err != nil {
+		c.log.Errorf("Error introspecting token: %v", err)
+		RespondWithError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	RespondWithRawJSON(w, http.StatusOK, resp)
 }
 
-// Logout  handles user logout
+// Logout  revokes the caller's access token and, if provided, its refresh token family
 func (c *authController) Logout(w http.ResponseWriter, r *http.Request) {
-	panic("implement")
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	// the body is optional; an access token alone logs out the current token only
+	var req models.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.log.Warn("Failed to decode request body: ", err)
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.tokenSvc.Logout(r.Context(), claims, req.RefreshToken)
+	if err != nil {
+		c.log.Warnf("Error logging out user: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// LogoutAll  revokes every access and refresh token of the caller ("logout everywhere")
+func (c *authController) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, err := c.tokenSvc.LogoutEverywhere(r.Context(), claims.UserID)
+	if err != nil {
+		c.log.Warnf("Error logging out user everywhere: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
 }
 
-// RefreshToken  refreshes  JWT token refresh
+// UserInfo  returns the OpenID Connect claims of the caller
+func (c *authController) UserInfo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, info, err := c.service.UserInfo(r.Context(), claims.UserID)
+	if err != nil {
+		c.log.Warnf("Error fetching user info: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithRawJSON(w, status, info)
+}
+
+// RefreshToken  rotates a refresh token and returns a new access/refresh token pair
 func (c *authController) RefreshToken(w http.ResponseWriter, r *http.Request) {
-	panic("implement")
+	var req models.RefreshRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.log.Warn("Failed to decode request body: ", err)
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, data, err := c.service.RefreshToken(r.Context(), req.RefreshToken)
+	if err != nil {
+		c.log.Warnf("Error refreshing token: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, data, "")
 }
@@ -7,10 +7,38 @@ import (
 
 type Controller interface {
 	AuthController() AuthController
+	WellKnownController() WellKnownController
+	OAuthController() OAuthController
+	RoleController() RoleController
+	PasswordController() PasswordController
+	EmailVerificationController() EmailVerificationController
+	UserAdminController() UserAdminController
+	MFAController() MFAController
+	ProfileController() ProfileController
+	SessionController() SessionController
+	PersonalAccessTokenController() PersonalAccessTokenController
+	MagicLinkController() MagicLinkController
+	InviteController() InviteController
+	DataExportController() DataExportController
+	EventController() EventController
 }
 
 type controller struct {
-	authCtrl AuthController
+	authCtrl      AuthController
+	wellKnownCtrl WellKnownController
+	oauthCtrl     OAuthController
+	roleCtrl      RoleController
+	passwordCtrl  PasswordController
+	verifyCtrl    EmailVerificationController
+	userAdminCtrl UserAdminController
+	mfaCtrl       MFAController
+	profileCtrl   ProfileController
+	sessionCtrl   SessionController
+	patCtrl       PersonalAccessTokenController
+	magicCtrl     MagicLinkController
+	inviteCtrl    InviteController
+	exportCtrl    DataExportController
+	eventCtrl     EventController
 }
 
 // AuthController ...
@@ -18,10 +46,94 @@ func (c *controller) AuthController() AuthController {
 	return c.authCtrl
 }
 
+// WellKnownController ...
+func (c *controller) WellKnownController() WellKnownController {
+	return c.wellKnownCtrl
+}
+
+// OAuthController ...
+func (c *controller) OAuthController() OAuthController {
+	return c.oauthCtrl
+}
+
+// RoleController ...
+func (c *controller) RoleController() RoleController {
+	return c.roleCtrl
+}
+
+// PasswordController ...
+func (c *controller) PasswordController() PasswordController {
+	return c.passwordCtrl
+}
+
+// EmailVerificationController ...
+func (c *controller) EmailVerificationController() EmailVerificationController {
+	return c.verifyCtrl
+}
+
+// UserAdminController ...
+func (c *controller) UserAdminController() UserAdminController {
+	return c.userAdminCtrl
+}
+
+// MFAController ...
+func (c *controller) MFAController() MFAController {
+	return c.mfaCtrl
+}
+
+// ProfileController ...
+func (c *controller) ProfileController() ProfileController {
+	return c.profileCtrl
+}
+
+// SessionController ...
+func (c *controller) SessionController() SessionController {
+	return c.sessionCtrl
+}
+
+// PersonalAccessTokenController ...
+func (c *controller) PersonalAccessTokenController() PersonalAccessTokenController {
+	return c.patCtrl
+}
+
+// MagicLinkController ...
+func (c *controller) MagicLinkController() MagicLinkController {
+	return c.magicCtrl
+}
+
+// InviteController ...
+func (c *controller) InviteController() InviteController {
+	return c.inviteCtrl
+}
+
+// DataExportController ...
+func (c *controller) DataExportController() DataExportController {
+	return c.exportCtrl
+}
+
+// EventController ...
+func (c *controller) EventController() EventController {
+	return c.eventCtrl
+}
+
 // NewController  returns a new instance of controller
 func NewController(svc services.Services, l *logrus.Logger) Controller {
 	uSvc := svc.UserService()
 	return &controller{
-		authCtrl: NewAuthController(uSvc, l),
+		authCtrl:      NewAuthController(uSvc, svc.TokenService(), l),
+		wellKnownCtrl: NewWellKnownController(svc.KeyManager(), svc.TokenService(), l),
+		oauthCtrl:     NewOAuthController(svc.OAuthService(), l),
+		roleCtrl:      NewRoleController(svc.RoleService(), l),
+		passwordCtrl:  NewPasswordController(svc.PasswordService(), l),
+		verifyCtrl:    NewEmailVerificationController(svc.EmailVerificationService(), l),
+		userAdminCtrl: NewUserAdminController(svc.UserAdminService(), svc.AuditService(), l),
+		mfaCtrl:       NewMFAController(svc.MFAService(), l),
+		profileCtrl:   NewProfileController(svc.ProfileService(), l),
+		sessionCtrl:   NewSessionController(svc.SessionService(), l),
+		patCtrl:       NewPersonalAccessTokenController(svc.PersonalAccessTokenService(), l),
+		magicCtrl:     NewMagicLinkController(svc.MagicLinkService(), l),
+		inviteCtrl:    NewInviteController(svc.InviteService(), l),
+		exportCtrl:    NewDataExportController(svc.DataExportService(), l),
+		eventCtrl:     NewEventController(svc.OutboxService(), l),
 	}
 }
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DataExportController handles the endpoint users download their data with
+type DataExportController interface {
+	ExportData(w http.ResponseWriter, r *http.Request)
+}
+
+// dataExportController is an implementation of DataExportController
+type dataExportController struct {
+	service services.DataExportService
+	log     *logrus.Logger
+}
+
+// NewDataExportController returns a new instance of the data export controller
+func NewDataExportController(svc services.DataExportService, l *logrus.Logger) DataExportController {
+	return &dataExportController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// ExportData sends the authenticated user a ZIP archive of their data. Their access token is
+// passed on to blog-service to fetch their posts.
+func (c *dataExportController) ExportData(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	accessToken, _ := utils.ExtractBearerToken(r)
+
+	status, archive, err := c.service.Export(r.Context(), claims.UserID, accessToken)
+	if err != nil {
+		c.log.Errorf("Error exporting user data: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("account-export-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if _, err := w.Write(archive); err != nil {
+		c.log.Warnf("Error writing data export: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"auth-service/models"
+	"auth-service/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EmailVerificationController handles the verify email and resend verification endpoints
+type EmailVerificationController interface {
+	VerifyEmail(w http.ResponseWriter, r *http.Request)
+	ResendVerification(w http.ResponseWriter, r *http.Request)
+}
+
+// emailVerificationController is an implementation of EmailVerificationController
+type emailVerificationController struct {
+	service services.EmailVerificationService
+	log     *logrus.Logger
+}
+
+// NewEmailVerificationController returns a new instance of the email verification controller
+func NewEmailVerificationController(svc services.EmailVerificationService, l *logrus.Logger) EmailVerificationController {
+	return &emailVerificationController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// VerifyEmail verifies the email of a user with the token from a verification link
+func (c *emailVerificationController) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	status, err := c.service.VerifyEmail(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		c.log.Warnf("Error verifying email: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// ResendVerification mails a new verification link if the email is registered and not verified
+// yet. It answers 202 either way.
+func (c *emailVerificationController) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req models.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.service.ResendVerification(r.Context(), strings.TrimSpace(req.Email))
+	if err != nil {
+		c.log.Errorf("Error handling resend verification request: %v", err)
+		RespondWithError(w, status, "Internal server error")
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
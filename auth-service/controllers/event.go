@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"auth-service/constants"
+	"auth-service/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventController handles the endpoint other services read outbox events from
+type EventController interface {
+	ListEvents(w http.ResponseWriter, r *http.Request)
+}
+
+// eventController is an implementation of EventController
+type eventController struct {
+	service services.OutboxService
+	log     *logrus.Logger
+}
+
+// NewEventController returns a new instance of the event controller
+func NewEventController(svc services.OutboxService, l *logrus.Logger) EventController {
+	return &eventController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// ListEvents returns the events after the id in the after query parameter, oldest first. The
+// limit query parameter caps how many are returned.
+func (c *eventController) ListEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var afterID int64
+	if raw := query.Get("after"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			RespondWithError(w, http.StatusBadRequest, constants.ErrInvalidEventCursor)
+			return
+		}
+		afterID = parsed
+	}
+	limit := constants.MaxPageSize
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > constants.MaxPageSize {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf(constants.ErrInvalidEventLimit, constants.MaxPageSize))
+			return
+		}
+		limit = parsed
+	}
+
+	status, events, err := c.service.ListEvents(r.Context(), afterID, limit)
+	if err != nil {
+		c.log.Errorf("Error listing outbox events: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, events, "")
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InviteController handles the admin endpoints that manage registration invites
+type InviteController interface {
+	ListInvites(w http.ResponseWriter, r *http.Request)
+	CreateInvite(w http.ResponseWriter, r *http.Request)
+	RevokeInvite(w http.ResponseWriter, r *http.Request)
+}
+
+// inviteController is an implementation of InviteController
+type inviteController struct {
+	service services.InviteService
+	log     *logrus.Logger
+}
+
+// NewInviteController returns a new instance of the invite controller
+func NewInviteController(svc services.InviteService, l *logrus.Logger) InviteController {
+	return &inviteController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// ListInvites returns a page of invites, newest first
+func (c *inviteController) ListInvites(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, ok := queryPage(w, r)
+	if !ok {
+		return
+	}
+
+	status, invites, err := c.service.ListInvites(r.Context(), models.InviteListRequest{Page: page, PageSize: pageSize})
+	if err != nil {
+		c.log.Errorf("Error listing invites: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, invites, "")
+}
+
+// CreateInvite creates an invite on behalf of the authenticated admin and returns its code once.
+// The request body may limit it to an email and name a role to grant.
+func (c *inviteController) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	var req models.CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, invite, err := c.service.CreateInvite(r.Context(), claims.UserID, req)
+	if err != nil {
+		c.log.Warnf("Error creating invite: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, invite, "")
+}
+
+// RevokeInvite revokes the unused invite in the path
+func (c *inviteController) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	inviteID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || inviteID <= 0 {
+		RespondWithError(w, http.StatusNotFound, constants.ErrInviteNotFound)
+		return
+	}
+
+	status, err := c.service.RevokeInvite(r.Context(), claims.UserID, inviteID)
+	if err != nil {
+		c.log.Warnf("Error revoking invite: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MagicLinkController handles passwordless login through mailed sign-in links
+type MagicLinkController interface {
+	RequestLink(w http.ResponseWriter, r *http.Request)
+	Callback(w http.ResponseWriter, r *http.Request)
+}
+
+// magicLinkController is an implementation of MagicLinkController
+type magicLinkController struct {
+	service services.MagicLinkService
+	log     *logrus.Logger
+}
+
+// NewMagicLinkController returns a new instance of the magic link controller
+func NewMagicLinkController(svc services.MagicLinkService, l *logrus.Logger) MagicLinkController {
+	return &magicLinkController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// RequestLink mails a sign-in link if the email is registered and sets the nonce cookie the link
+// must be opened with. It answers 202 either way.
+func (c *magicLinkController) RequestLink(w http.ResponseWriter, r *http.Request) {
+	var req models.MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, nonce, err := c.service.RequestLink(r.Context(), strings.TrimSpace(req.Email))
+	if err != nil {
+		c.log.Errorf("Error handling sign-in link request: %v", err)
+		RespondWithError(w, status, "Internal server error")
+		return
+	}
+
+	// the path stays "/" since auth-service may be served below a prefix
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.MagicLinkNonceCookie,
+		Value:    nonce.Value,
+		Path:     "/",
+		Expires:  nonce.ExpiresAt,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	RespondWithJSON(w, status, nil, "")
+}
+
+// Callback logs the user in with the token of a sign-in link and the nonce cookie of the browser
+func (c *magicLinkController) Callback(w http.ResponseWriter, r *http.Request) {
+	var nonce string
+	if cookie, err := r.Cookie(constants.MagicLinkNonceCookie); err == nil {
+		nonce = cookie.Value
+	}
+
+	status, data, err := c.service.CompleteLogin(r.Context(), r.URL.Query().Get("token"), nonce)
+	if err != nil {
+		c.log.Warnf("Error completing sign-in link login: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	// the nonce is spent along with the link
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.MagicLinkNonceCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	RespondWithJSON(w, status, data, "")
+}
+
+// isHTTPS reports whether the client reached auth-service over HTTPS, directly or through a proxy
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
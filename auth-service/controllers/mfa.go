@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MFAController handles TOTP enrollment and the management of two-factor authentication of the
+// authenticated user
+type MFAController interface {
+	Enroll(w http.ResponseWriter, r *http.Request)
+	QRCode(w http.ResponseWriter, r *http.Request)
+	Confirm(w http.ResponseWriter, r *http.Request)
+	Disable(w http.ResponseWriter, r *http.Request)
+	RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request)
+}
+
+// mfaController is an implementation of MFAController
+type mfaController struct {
+	service services.MFAService
+	log     *logrus.Logger
+}
+
+// NewMFAController returns a new instance of the two-factor authentication controller
+func NewMFAController(svc services.MFAService, l *logrus.Logger) MFAController {
+	return &mfaController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// Enroll starts a TOTP enrollment and returns the secret and its otpauth:// URI
+func (c *mfaController) Enroll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, enrollment, err := c.service.Enroll(r.Context(), claims.UserID)
+	if err != nil {
+		c.log.Warnf("Error starting mfa enrollment: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	RespondWithJSON(w, status, enrollment, "")
+}
+
+// QRCode returns the pending enrollment as a QR code PNG for authenticator apps to scan
+func (c *mfaController) QRCode(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, png, err := c.service.QRCode(r.Context(), claims.UserID)
+	if err != nil {
+		c.log.Warnf("Error rendering mfa qr code: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if _, err := w.Write(png); err != nil {
+		c.log.Errorf("Unable to write qr code: %v", err)
+	}
+}
+
+// Confirm enables two-factor authentication with a code from the authenticator app and returns
+// the recovery codes
+func (c *mfaController) Confirm(w http.ResponseWriter, r *http.Request) {
+	claims, code, ok := c.codeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	status, codes, err := c.service.Confirm(r.Context(), claims.UserID, code)
+	if err != nil {
+		c.log.Warnf("Error confirming mfa: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	RespondWithJSON(w, status, codes, "")
+}
+
+// Disable turns two-factor authentication off; it requires a current code or a recovery code
+func (c *mfaController) Disable(w http.ResponseWriter, r *http.Request) {
+	claims, code, ok := c.codeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := c.service.Disable(r.Context(), claims.UserID, code)
+	if err != nil {
+		c.log.Warnf("Error disabling mfa: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes; it requires a current code or a recovery code
+func (c *mfaController) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	claims, code, ok := c.codeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	status, codes, err := c.service.RegenerateRecoveryCodes(r.Context(), claims.UserID, code)
+	if err != nil {
+		c.log.Warnf("Error regenerating recovery codes: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	RespondWithJSON(w, status, codes, "")
+}
+
+// codeRequest reads the claims of the authenticated user and the code from the request body. It
+// responds with an error and returns false when either is missing.
+func (c *mfaController) codeRequest(w http.ResponseWriter, r *http.Request) (*utils.TokenClaims, string, bool) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return nil, "", false
+	}
+
+	var req models.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Code) == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return nil, "", false
+	}
+	return claims, strings.TrimSpace(req.Code), true
+}
@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed templates/authorize.html
+var templatesFS embed.FS
+
+// authorizeTemplate renders the login and consent page of the authorization endpoint
+var authorizeTemplate = template.Must(template.ParseFS(templatesFS, "templates/authorize.html"))
+
+// authorizePage is the data of the authorize template
+type authorizePage struct {
+	Request    models.AuthorizeRequest
+	ClientName string
+	Scopes     []string
+	Email      string
+	Error      string
+	// Fatal pages only show the error, since the request cannot be sent back to the client
+	Fatal bool
+}
+
+// OAuthController handles client registration and the OAuth 2.0 authorization code flow
+type OAuthController interface {
+	RegisterClient(w http.ResponseWriter, r *http.Request)
+	RotateClientSecret(w http.ResponseWriter, r *http.Request)
+	RevokeClientSecret(w http.ResponseWriter, r *http.Request)
+	Authorize(w http.ResponseWriter, r *http.Request)
+	AuthorizeSubmit(w http.ResponseWriter, r *http.Request)
+	Token(w http.ResponseWriter, r *http.Request)
+}
+
+// oauthController is an implementation of OAuthController
+type oauthController struct {
+	service services.OAuthService
+	log     *logrus.Logger
+}
+
+// NewOAuthController returns a new instance of the OAuth controller
+func NewOAuthController(svc services.OAuthService, l *logrus.Logger) OAuthController {
+	return &oauthController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// RegisterClient registers a public or confidential OAuth client owned by the caller
+func (c *oauthController) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	var req models.ClientRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, client, err := c.service.RegisterClient(r.Context(), claims.UserID, req)
+	if err != nil {
+		c.log.Warnf("Error registering client: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, client, "")
+}
+
+// RotateClientSecret issues a new secret for a confidential client of the caller
+func (c *oauthController) RotateClientSecret(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, secret, err := c.service.RotateClientSecret(r.Context(), claims.UserID, r.PathValue("client_id"))
+	if err != nil {
+		c.log.Warnf("Error rotating client secret: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, secret, "")
+}
+
+// RevokeClientSecret revokes a secret of a confidential client of the caller
+func (c *oauthController) RevokeClientSecret(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	secretID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusNotFound, constants.ErrClientSecretNotFound)
+		return
+	}
+
+	status, err := c.service.RevokeClientSecret(r.Context(), claims.UserID, r.PathValue("client_id"), secretID)
+	if err != nil {
+		c.log.Warnf("Error revoking client secret: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// Authorize shows the login and consent page for a valid authorization request
+func (c *oauthController) Authorize(w http.ResponseWriter, r *http.Request) {
+	req := authorizeRequestFrom(r.URL.Query())
+
+	client, req, err := c.service.ValidateAuthorizeRequest(r.Context(), req)
+	if err != nil {
+		c.respondAuthorizeError(w, r, client, req, err)
+		return
+	}
+
+	c.renderAuthorizePage(w, http.StatusOK, authorizePage{
+		Request:    req,
+		ClientName: client.Name,
+		Scopes:     utils.ParseScope(req.Scope),
+	})
+}
+
+// AuthorizeSubmit handles the login form: it redirects back to the client with a code, with
+// access_denied when the user declined, or shows the form again after a wrong password or
+// authentication code
+func (c *oauthController) AuthorizeSubmit(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		c.renderAuthorizePage(w, http.StatusBadRequest, authorizePage{Error: "invalid form body", Fatal: true})
+		return
+	}
+	req := authorizeRequestFrom(r.PostForm)
+
+	client, req, err := c.service.ValidateAuthorizeRequest(r.Context(), req)
+	if err != nil {
+		c.respondAuthorizeError(w, r, client, req, err)
+		return
+	}
+
+	if r.PostForm.Get("decision") != "allow" {
+		denied := models.NewOAuthError(constants.OAuthErrAccessDenied, "the user denied the request")
+		c.respondAuthorizeError(w, r, client, req, denied)
+		return
+	}
+
+	email := r.PostForm.Get("email")
+	redirectURL, err := c.service.Authorize(r.Context(), req, email, r.PostForm.Get("password"), r.PostForm.Get("mfa_code"))
+	if err != nil {
+		var oauthErr *models.OAuthError
+		if errors.As(err, &oauthErr) {
+			c.respondAuthorizeError(w, r, client, req, oauthErr)
+			return
+		}
+		var throttled *models.LoginThrottledError
+		if errors.As(err, &throttled) {
+			setRetryAfter(w, throttled)
+			c.renderAuthorizePage(w, throttled.Status, authorizePage{
+				Request:    req,
+				ClientName: client.Name,
+				Scopes:     utils.ParseScope(req.Scope),
+				Email:      email,
+				Error:      throttled.Error(),
+			})
+			return
+		}
+		message := constants.ErrInvalidEmailOrPass
+		if msg := err.Error(); msg == constants.ErrMFACodeRequired || msg == constants.ErrInvalidMFACode {
+			message = msg
+		}
+		c.renderAuthorizePage(w, http.StatusUnauthorized, authorizePage{
+			Request:    req,
+			ClientName: client.Name,
+			Scopes:     utils.ParseScope(req.Scope),
+			Email:      email,
+			Error:      message,
+		})
+		return
+	}
+
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+// Token is the OAuth 2.0 token endpoint. Requests are form encoded as RFC 6749 prescribes.
+func (c *oauthController) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		RespondWithOAuthError(w, http.StatusBadRequest, constants.OAuthErrInvalidRequest, "invalid form body")
+		return
+	}
+
+	req := models.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		Scope:        r.PostForm.Get("scope"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+	}
+	// client_secret_basic takes precedence over credentials in the body (client_secret_post)
+	usedBasic := false
+	if clientID, secret, ok := utils.ExtractClientCredentials(r); ok {
+		req.ClientID, req.ClientSecret = clientID, secret
+		usedBasic = true
+	}
+
+	status, resp, err := c.service.Token(r.Context(), req)
+	if err != nil {
+		c.log.Warnf("Rejected token request: %v", err)
+		var oauthErr *models.OAuthError
+		if !errors.As(err, &oauthErr) {
+			oauthErr = models.NewOAuthError(constants.OAuthErrServerError, "")
+		}
+		if usedBasic && status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Basic realm="auth-service"`)
+		}
+		RespondWithRawJSON(w, status, oauthErr)
+		return
+	}
+
+	RespondWithRawJSON(w, status, resp)
+}
+
+// respondAuthorizeError sends the error back to the client's redirect URI, or shows it to the user
+// when the client or redirect URI could not be validated
+func (c *oauthController) respondAuthorizeError(w http.ResponseWriter, r *http.Request, client models.Client, req models.AuthorizeRequest, err error) {
+	var oauthErr *models.OAuthError
+	if !errors.As(err, &oauthErr) {
+		oauthErr = models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+
+	if client.ID == 0 {
+		c.log.Warnf("Rejected authorization request: %v", oauthErr)
+		c.renderAuthorizePage(w, http.StatusBadRequest, authorizePage{Error: oauthErr.Error(), Fatal: true})
+		return
+	}
+
+	params := url.Values{"error": {oauthErr.Code}}
+	if oauthErr.Description != "" {
+		params.Set("error_description", oauthErr.Description)
+	}
+	http.Redirect(w, r, services.AuthorizeRedirectURL(req, params), http.StatusSeeOther)
+}
+
+// renderAuthorizePage writes the authorize page; it must never be framed by another site
+func (c *oauthController) renderAuthorizePage(w http.ResponseWriter, status int, page authorizePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	w.WriteHeader(status)
+
+	if err := authorizeTemplate.Execute(w, page); err != nil {
+		c.log.Errorf("Unable to render authorize page: %v", err)
+	}
+}
+
+// authorizeRequestFrom reads the authorization request parameters
+func authorizeRequestFrom(values url.Values) models.AuthorizeRequest {
+	return models.AuthorizeRequest{
+		ResponseType:        values.Get("response_type"),
+		ClientID:            values.Get("client_id"),
+		RedirectURI:         values.Get("redirect_uri"),
+		Scope:               values.Get("scope"),
+		State:               values.Get("state"),
+		Nonce:               values.Get("nonce"),
+		CodeChallenge:       values.Get("code_challenge"),
+		CodeChallengeMethod: values.Get("code_challenge_method"),
+	}
+}
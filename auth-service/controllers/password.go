@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"auth-service/models"
+	"auth-service/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PasswordController handles the forgot and reset password endpoints
+type PasswordController interface {
+	ForgotPassword(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
+}
+
+// passwordController is an implementation of PasswordController
+type passwordController struct {
+	service services.PasswordService
+	log     *logrus.Logger
+}
+
+// NewPasswordController returns a new instance of the password controller
+func NewPasswordController(svc services.PasswordService, l *logrus.Logger) PasswordController {
+	return &passwordController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// ForgotPassword mails a reset link if the email is registered. It answers 202 either way.
+func (c *passwordController) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.service.ForgotPassword(r.Context(), strings.TrimSpace(req.Email))
+	if err != nil {
+		c.log.Errorf("Error handling forgot password request: %v", err)
+		RespondWithError(w, status, "Internal server error")
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// ResetPassword sets a new password with a token from a reset link
+func (c *passwordController) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.service.ResetPassword(r.Context(), req.Token, req.Password)
+	if err != nil {
+		c.log.Warnf("Error resetting password: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
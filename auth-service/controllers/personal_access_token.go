@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PersonalAccessTokenController handles the endpoints users manage their personal access tokens with
+type PersonalAccessTokenController interface {
+	ListTokens(w http.ResponseWriter, r *http.Request)
+	CreateToken(w http.ResponseWriter, r *http.Request)
+	RevokeToken(w http.ResponseWriter, r *http.Request)
+}
+
+// personalAccessTokenController is an implementation of PersonalAccessTokenController
+type personalAccessTokenController struct {
+	service services.PersonalAccessTokenService
+	log     *logrus.Logger
+}
+
+// NewPersonalAccessTokenController returns a new instance of the personal access token controller
+func NewPersonalAccessTokenController(svc services.PersonalAccessTokenService, l *logrus.Logger) PersonalAccessTokenController {
+	return &personalAccessTokenController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// ListTokens returns the personal access tokens of the authenticated user
+func (c *personalAccessTokenController) ListTokens(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, tokens, err := c.service.ListTokens(r.Context(), claims.UserID)
+	if err != nil {
+		c.log.Errorf("Error listing personal access tokens: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, tokens, "")
+}
+
+// CreateToken creates a personal access token for the authenticated user and returns it once
+func (c *personalAccessTokenController) CreateToken(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	var req models.CreatePersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, token, err := c.service.CreateToken(r.Context(), claims.UserID, req)
+	if err != nil {
+		c.log.Warnf("Error creating personal access token: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, token, "")
+}
+
+// RevokeToken revokes the personal access token in the path
+func (c *personalAccessTokenController) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	tokenID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || tokenID <= 0 {
+		RespondWithError(w, http.StatusNotFound, constants.ErrPersonalAccessTokenNotFound)
+		return
+	}
+
+	status, err := c.service.RevokeToken(r.Context(), claims.UserID, tokenID)
+	if err != nil {
+		c.log.Warnf("Error revoking personal access token: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProfileController handles the endpoints users manage their own account with, and public profiles
+type ProfileController interface {
+	GetProfile(w http.ResponseWriter, r *http.Request)
+	UpdateProfile(w http.ResponseWriter, r *http.Request)
+	ChangePassword(w http.ResponseWriter, r *http.Request)
+	ChangeEmail(w http.ResponseWriter, r *http.Request)
+	ConfirmEmailChange(w http.ResponseWriter, r *http.Request)
+	PublicProfile(w http.ResponseWriter, r *http.Request)
+	DeleteAccount(w http.ResponseWriter, r *http.Request)
+	CancelDeletion(w http.ResponseWriter, r *http.Request)
+}
+
+// profileController is an implementation of ProfileController
+type profileController struct {
+	service services.ProfileService
+	log     *logrus.Logger
+}
+
+// NewProfileController returns a new instance of the profile controller
+func NewProfileController(svc services.ProfileService, l *logrus.Logger) ProfileController {
+	return &profileController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// GetProfile returns the profile of the authenticated user
+func (c *profileController) GetProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, profile, err := c.service.GetProfile(r.Context(), claims.UserID)
+	if err != nil {
+		c.log.Warnf("Error fetching profile: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, profile, "")
+}
+
+// UpdateProfile changes the profile fields present in the request body
+func (c *profileController) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, profile, err := c.service.UpdateProfile(r.Context(), claims.UserID, req)
+	if err != nil {
+		c.log.Warnf("Error updating profile: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, profile, "")
+}
+
+// ChangePassword sets a new password after checking the current one and logs out every session
+func (c *profileController) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CurrentPassword == "" || req.NewPassword == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.service.ChangePassword(r.Context(), claims.UserID, req)
+	if err != nil {
+		c.log.Warnf("Error changing password: %v", err)
+		setRetryAfter(w, err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// ChangeEmail mails a confirmation link to the requested new email address
+func (c *profileController) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.NewEmail == "" || req.Password == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.service.RequestEmailChange(r.Context(), claims.UserID, req)
+	if err != nil {
+		c.log.Warnf("Error requesting email change: %v", err)
+		setRetryAfter(w, err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// ConfirmEmailChange changes the email with the token from a confirmation link
+func (c *profileController) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	status, err := c.service.ConfirmEmailChange(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		c.log.Warnf("Error confirming email change: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// PublicProfile returns the public profile of the user named in the path
+func (c *profileController) PublicProfile(w http.ResponseWriter, r *http.Request) {
+	status, profile, err := c.service.PublicProfile(r.Context(), r.PathValue("username"))
+	if err != nil {
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, profile, "")
+}
+
+// DeleteAccount schedules the deletion of the authenticated user's account after checking their password
+func (c *profileController) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Password == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, deletion, err := c.service.ScheduleDeletion(r.Context(), claims.UserID, req)
+	if err != nil {
+		c.log.Warnf("Error scheduling account deletion: %v", err)
+		setRetryAfter(w, err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, deletion, "")
+}
+
+// CancelDeletion keeps the authenticated user's account if it was scheduled for deletion
+func (c *profileController) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, err := c.service.CancelDeletion(r.Context(), claims.UserID)
+	if err != nil {
+		c.log.Warnf("Error cancelling account deletion: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RoleController handles the admin endpoints that manage the roles of users
+type RoleController interface {
+	ListRoles(w http.ResponseWriter, r *http.Request)
+	UserRoles(w http.ResponseWriter, r *http.Request)
+	AssignRole(w http.ResponseWriter, r *http.Request)
+	RemoveRole(w http.ResponseWriter, r *http.Request)
+}
+
+// roleController is an implementation of RoleController
+type roleController struct {
+	service services.RoleService
+	log     *logrus.Logger
+}
+
+// NewRoleController returns a new instance of the role controller
+func NewRoleController(svc services.RoleService, l *logrus.Logger) RoleController {
+	return &roleController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// ListRoles returns every role with its permissions
+func (c *roleController) ListRoles(w http.ResponseWriter, r *http.Request) {
+	status, roles, err := c.service.ListRoles(r.Context())
+	if err != nil {
+		c.log.Errorf("Error listing roles: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, roles, "")
+}
+
+// UserRoles returns the roles and permissions of the user in the path
+func (c *roleController) UserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	status, roles, err := c.service.UserRoles(r.Context(), userID)
+	if err != nil {
+		c.log.Warnf("Error fetching user roles: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, roles, "")
+}
+
+// AssignRole grants the role in the request body to the user in the path
+func (c *roleController) AssignRole(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.RoleAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Role) == "" {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.service.AssignRole(r.Context(), claims.UserID, userID, strings.TrimSpace(req.Role))
+	if err != nil {
+		c.log.Warnf("Error assigning role: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// RemoveRole takes the role in the path away from the user in the path
+func (c *roleController) RemoveRole(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := c.service.RemoveRole(r.Context(), claims.UserID, userID, r.PathValue("role"))
+	if err != nil {
+		c.log.Warnf("Error removing role: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// pathUserID parses the {id} path value, responding with 404 when it is not a user id
+func pathUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		RespondWithError(w, http.StatusNotFound, constants.ErrUserNotFound)
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"auth-service/constants"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SessionController handles the endpoints users manage their login sessions with
+type SessionController interface {
+	ListSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+}
+
+// sessionController is an implementation of SessionController
+type sessionController struct {
+	service services.SessionService
+	log     *logrus.Logger
+}
+
+// NewSessionController returns a new instance of the session controller
+func NewSessionController(svc services.SessionService, l *logrus.Logger) SessionController {
+	return &sessionController{
+		service: svc,
+		log:     l,
+	}
+}
+
+// ListSessions returns the sessions of the authenticated user
+func (c *sessionController) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+
+	status, sessions, err := c.service.ListSessions(r.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		c.log.Errorf("Error listing sessions: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, sessions, "")
+}
+
+// RevokeSession logs the authenticated user out of the session in the path
+func (c *sessionController) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	sessionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || sessionID <= 0 {
+		RespondWithError(w, http.StatusNotFound, constants.ErrSessionNotFound)
+		return
+	}
+
+	status, err := c.service.RevokeSession(r.Context(), claims.UserID, sessionID)
+	if err != nil {
+		c.log.Warnf("Error revoking session: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
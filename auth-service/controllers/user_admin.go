@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UserAdminController handles the admin endpoints that manage user accounts
+type UserAdminController interface {
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	DisableUser(w http.ResponseWriter, r *http.Request)
+	EnableUser(w http.ResponseWriter, r *http.Request)
+	ForcePasswordReset(w http.ResponseWriter, r *http.Request)
+	DeleteUser(w http.ResponseWriter, r *http.Request)
+	UnlockUser(w http.ResponseWriter, r *http.Request)
+	ImpersonateUser(w http.ResponseWriter, r *http.Request)
+	AuditLog(w http.ResponseWriter, r *http.Request)
+}
+
+// userAdminController is an implementation of UserAdminController
+type userAdminController struct {
+	service  services.UserAdminService
+	auditSvc services.AuditService
+	log      *logrus.Logger
+}
+
+// NewUserAdminController returns a new instance of the user admin controller
+func NewUserAdminController(
+	svc services.UserAdminService,
+	auditSvc services.AuditService,
+	l *logrus.Logger,
+) UserAdminController {
+	return &userAdminController{
+		service:  svc,
+		auditSvc: auditSvc,
+		log:      l,
+	}
+}
+
+// ListUsers returns a page of users. The q query parameter searches emails, usernames and
+// display names.
+func (c *userAdminController) ListUsers(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, ok := queryPage(w, r)
+	if !ok {
+		return
+	}
+
+	req := models.UserListRequest{Query: strings.TrimSpace(r.URL.Query().Get("q")), Page: page, PageSize: pageSize}
+	status, users, err := c.service.ListUsers(r.Context(), req)
+	if err != nil {
+		c.log.Errorf("Error listing users: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, users, "")
+}
+
+// GetUser returns the account of the user in the path
+func (c *userAdminController) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	status, user, err := c.service.GetUser(r.Context(), userID)
+	if err != nil {
+		c.log.Warnf("Error fetching user: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, user, "")
+}
+
+// DisableUser disables the user in the path. The request body may give a reason for the audit log.
+func (c *userAdminController) DisableUser(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.DisableUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := c.service.DisableUser(r.Context(), claims.UserID, userID, strings.TrimSpace(req.Reason))
+	if err != nil {
+		c.log.Warnf("Error disabling user: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// EnableUser re-enables the user in the path
+func (c *userAdminController) EnableUser(w http.ResponseWriter, r *http.Request) {
+	c.userAction(w, r, "enabling user", c.service.EnableUser)
+}
+
+// ForcePasswordReset makes the user in the path set a new password before they can log in again
+func (c *userAdminController) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	c.userAction(w, r, "forcing password reset", c.service.ForcePasswordReset)
+}
+
+// DeleteUser deletes the user in the path
+func (c *userAdminController) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	c.userAction(w, r, "deleting user", c.service.DeleteUser)
+}
+
+// UnlockUser lifts the lockout of the user in the path after failed logins
+func (c *userAdminController) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	c.userAction(w, r, "unlocking user", c.service.UnlockUser)
+}
+
+// ImpersonateUser returns a short-lived access token for acting as the user in the path. The
+// request body must give a reason for the audit log.
+func (c *userAdminController) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ImpersonateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, resp, err := c.service.ImpersonateUser(r.Context(), claims.UserID, userID, strings.TrimSpace(req.Reason))
+	if err != nil {
+		c.log.Warnf("Error impersonating user: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, resp, "")
+}
+
+// AuditLog returns a page of the audit log, newest first. The user_id and action query
+// parameters limit it to the entries about one user or of one action, and from and to
+// (RFC 3339) to a time range.
+func (c *userAdminController) AuditLog(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, ok := queryPage(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	req := models.AuditLogRequest{Page: page, PageSize: pageSize}
+
+	if raw := query.Get("user_id"); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || userID <= 0 {
+			RespondWithError(w, http.StatusBadRequest, "Invalid user_id")
+			return
+		}
+		req.Filter.TargetUserID = userID
+	}
+	if action := query.Get("action"); action != "" {
+		if !slices.Contains(constants.AuditActions, action) {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf(constants.ErrInvalidAuditAction, strings.Join(constants.AuditActions, ", ")))
+			return
+		}
+		req.Filter.Action = action
+	}
+	if req.Filter.From, ok = queryTime(w, r, "from"); !ok {
+		return
+	}
+	if req.Filter.To, ok = queryTime(w, r, "to"); !ok {
+		return
+	}
+	if req.Filter.From != nil && req.Filter.To != nil && !req.Filter.From.Before(*req.Filter.To) {
+		RespondWithError(w, http.StatusBadRequest, constants.ErrInvalidTimeRange)
+		return
+	}
+
+	status, entries, err := c.auditSvc.List(r.Context(), req)
+	if err != nil {
+		c.log.Errorf("Error listing audit log: %v", err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, entries, "")
+}
+
+// userAction runs an admin action on the user in the path on behalf of the authenticated admin
+func (c *userAdminController) userAction(
+	w http.ResponseWriter,
+	r *http.Request,
+	description string,
+	action func(ctx context.Context, actorID, userID int64) (int, error),
+) {
+	claims, ok := utils.ClaimsFromContext(r.Context())
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+		return
+	}
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := action(r.Context(), claims.UserID, userID)
+	if err != nil {
+		c.log.Warnf("Error %s: %v", description, err)
+		RespondWithError(w, status, err.Error())
+		return
+	}
+
+	RespondWithJSON(w, status, nil, "")
+}
+
+// queryTime parses an optional RFC 3339 query parameter, responding with 400 when it is malformed
+func queryTime(w http.ResponseWriter, r *http.Request, name string) (*time.Time, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, constants.ErrInvalidTimeRange)
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// queryPage parses the page and page_size query parameters, responding with 400 when they are
+// out of range. Missing parameters take the defaults.
+func queryPage(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	page, pageSize := constants.DefaultPage, constants.DefaultPageSize
+	query := r.URL.Query()
+
+	if raw := query.Get("page"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			RespondWithError(w, http.StatusBadRequest, constants.ErrInvalidPage)
+			return 0, 0, false
+		}
+		page = parsed
+	}
+	if raw := query.Get("page_size"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > constants.MaxPageSize {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf(constants.ErrInvalidPageSize, constants.MaxPageSize))
+			return 0, 0, false
+		}
+		pageSize = parsed
+	}
+	return page, pageSize, true
+}
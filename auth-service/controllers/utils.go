@@ -2,7 +2,12 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+
+	"auth-service/models"
 
 	"github.com/sirupsen/logrus"
 )
@@ -42,3 +47,35 @@ func RespondWithError(w http.ResponseWriter, code int, message string) {
 	RespondWithJSON(w, code, nil, message)
 	return
 }
+
+// RespondWithRawJSON sends data as the whole JSON body, without the StandardResponse envelope.
+// It is used by endpoints whose response format is fixed by a standard, e.g. OAuth 2.0.
+// Responses are not cacheable unless the handler set its own Cache-Control header.
+func RespondWithRawJSON(w http.ResponseWriter, code int, data interface{}) {
+	SetJSONHeader(w)
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logrus.Errorf("Unable to encode response: %v", err)
+	}
+}
+
+// RespondWithOAuthError sends an RFC 6749 error response.
+func RespondWithOAuthError(w http.ResponseWriter, code int, errorCode, description string) {
+	RespondWithRawJSON(w, code, models.NewOAuthError(errorCode, description))
+}
+
+// setRetryAfter sets the Retry-After header when err is a *models.LoginThrottledError and
+// reports whether it was one.
+func setRetryAfter(w http.ResponseWriter, err error) bool {
+	var throttled *models.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	return true
+}
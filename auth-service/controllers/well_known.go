@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"auth-service/constants"
+	"auth-service/services"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WellKnownController serves the /.well-known documents other services use to verify our tokens
+type WellKnownController interface {
+	JWKS(w http.ResponseWriter, r *http.Request)
+	OpenIDConfiguration(w http.ResponseWriter, r *http.Request)
+}
+
+// wellKnownController is an implementation of WellKnownController
+type wellKnownController struct {
+	keys     services.KeyManager
+	tokenSvc services.TokenService
+	log      *logrus.Logger
+}
+
+// NewWellKnownController returns a new instance of the well-known controller
+func NewWellKnownController(keys services.KeyManager, tokenSvc services.TokenService, l *logrus.Logger) WellKnownController {
+	return &wellKnownController{
+		keys:     keys,
+		tokenSvc: tokenSvc,
+		log:      l,
+	}
+}
+
+// JWKS serves the public signing keys as a JSON Web Key Set
+func (c *wellKnownController) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(constants.JWKSMaxAge.Seconds())))
+	RespondWithRawJSON(w, http.StatusOK, c.keys.JWKS())
+}
+
+// OpenIDConfiguration serves the OpenID Connect discovery document
+func (c *wellKnownController) OpenIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(constants.JWKSMaxAge.Seconds())))
+	RespondWithRawJSON(w, http.StatusOK, c.tokenSvc.Discovery())
+}
@@ -0,0 +1,118 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	"auth-service/models"
+	"auth-service/pkg/authpb"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// authServer implements the auth API on top of the same UserService the HTTP endpoints use
+type authServer struct {
+	authpb.UnimplementedAuthServiceServer
+	userSvc services.UserService
+}
+
+// NewAuthServer returns a new instance of the auth API server
+func NewAuthServer(userSvc services.UserService) authpb.AuthServiceServer {
+	return &authServer{userSvc: userSvc}
+}
+
+// VerifyToken returns the claims of a valid access token or personal access token
+func (s *authServer) VerifyToken(ctx context.Context, req *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	code, claims, err := s.userSvc.VerifyToken(ctx, req.GetToken())
+	if err != nil {
+		return nil, statusError(code, err)
+	}
+	return &authpb.VerifyTokenResponse{Claims: claimsOf(claims)}, nil
+}
+
+// GetUser returns the user with the id in the request
+func (s *authServer) GetUser(ctx context.Context, req *authpb.GetUserRequest) (*authpb.GetUserResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be a positive number")
+	}
+
+	code, user, err := s.userSvc.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, statusError(code, err)
+	}
+	return &authpb.GetUserResponse{User: userOf(user)}, nil
+}
+
+// BatchGetUsers returns the users with the ids in the request that exist
+func (s *authServer) BatchGetUsers(ctx context.Context, req *authpb.BatchGetUsersRequest) (*authpb.BatchGetUsersResponse, error) {
+	code, users, err := s.userSvc.GetUsers(ctx, req.GetIds())
+	if err != nil {
+		return nil, statusError(code, err)
+	}
+
+	resp := &authpb.BatchGetUsersResponse{Users: make([]*authpb.User, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, userOf(user))
+	}
+	return resp, nil
+}
+
+// statusError maps the HTTP status a service returned with err to a gRPC status. Internal errors
+// are not passed on, as they may reveal details of the database.
+func statusError(code int, err error) error {
+	switch code {
+	case http.StatusBadRequest:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case http.StatusUnauthorized:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+// claimsOf maps verified token claims to their protobuf message
+func claimsOf(claims *utils.TokenClaims) *authpb.TokenClaims {
+	msg := &authpb.TokenClaims{
+		Subject:     claims.Subject,
+		UserId:      claims.UserID,
+		Email:       claims.Email,
+		ClientId:    claims.ClientID,
+		Scope:       claims.Scope,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		SessionId:   claims.SessionID,
+		IssuedAt:    claims.IssuedAt,
+		ExpiresAt:   claims.ExpiresAt,
+		Jti:         claims.Id,
+	}
+	if claims.Actor != nil {
+		msg.ActorUserId = claims.Actor.UserID
+	}
+	return msg
+}
+
+// userOf maps a user to their protobuf message; the password hash and other credentials stay out
+func userOf(user models.User) *authpb.User {
+	return &authpb.User{
+		Id:            user.ID,
+		Email:         user.Email,
+		Username:      user.Username,
+		DisplayName:   user.DisplayName,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		AvatarUrl:     user.AvatarURL,
+		EmailVerified: user.EmailVerified(),
+		Disabled:      user.Disabled(),
+	}
+}
@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"slices"
+
+	"auth-service/constants"
+	"auth-service/pkg/authpb"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes are the scopes a client must be allowed to call the methods of the auth API. The
+// same scopes guard the equivalent HTTP endpoints.
+var methodScopes = map[string]string{
+	authpb.AuthService_VerifyToken_FullMethodName:   constants.ScopeTokensIntrospect,
+	authpb.AuthService_GetUser_FullMethodName:       constants.ScopeUsersRead,
+	authpb.AuthService_BatchGetUsers_FullMethodName: constants.ScopeUsersRead,
+}
+
+// AuthenticateClient returns an interceptor that only lets confidential clients call the auth API
+// that authenticate with HTTP Basic credentials in the authorization metadata and are allowed the
+// scope of the method. Health checks and reflection are open. The client IP of the call is stored
+// in the context, like the ClientIP middleware does for HTTP requests.
+func AuthenticateClient(oauthSvc services.OAuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if p, ok := peer.FromContext(ctx); ok {
+			ip, _, err := net.SplitHostPort(p.Addr.String())
+			if err != nil {
+				ip = p.Addr.String()
+			}
+			ctx = utils.ContextWithClientIP(ctx, ip)
+		}
+
+		scope, ok := methodScopes[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		var authorization string
+		if values := metadata.ValueFromIncomingContext(ctx, constants.GRPCMetadataAuthorization); len(values) > 0 {
+			authorization = values[0]
+		}
+		clientID, secret, ok := utils.ParseClientCredentials(authorization)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "client authentication is required")
+		}
+
+		_, client, err := oauthSvc.AuthenticateClient(ctx, clientID, secret)
+		if err != nil {
+			logrus.Warnf("Rejected gRPC client %s: %v", clientID, err)
+			return nil, status.Error(codes.Unauthenticated, "client authentication failed")
+		}
+		if !slices.Contains(client.AllowedScopes, scope) {
+			logrus.Warnf("Rejected gRPC client %s without scope %s", clientID, scope)
+			return nil, status.Error(codes.PermissionDenied, "client is not allowed "+scope)
+		}
+
+		return handler(ctx, req)
+	}
+}
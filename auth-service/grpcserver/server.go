@@ -0,0 +1,27 @@
+package grpcserver
+
+import (
+	"auth-service/constants"
+	"auth-service/pkg/authpb"
+	"auth-service/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer returns a gRPC server exposing the auth API of internal services along with the
+// standard health and reflection services. The health server is returned too, so that it can
+// report the API as not serving while the server shuts down.
+func NewServer(userSvc services.UserService, oauthSvc services.OAuthService) (*grpc.Server, *health.Server) {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(AuthenticateClient(oauthSvc)))
+	authpb.RegisterAuthServiceServer(srv, NewAuthServer(userSvc))
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(constants.GRPCServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+
+	reflection.Register(srv)
+	return srv, healthSrv
+}
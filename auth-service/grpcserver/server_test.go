@@ -0,0 +1,149 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/pkg/authclient"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeUserService knows one user and one valid token; other methods are not used by the server
+type fakeUserService struct {
+	services.UserService
+}
+
+func (fakeUserService) VerifyToken(_ context.Context, token string) (int, *utils.TokenClaims, error) {
+	if token != "valid-token" {
+		return http.StatusUnauthorized, nil, errors.New(constants.TokenInvalid)
+	}
+	claims := utils.NewTokenClaims("asif@example.com", 1)
+	claims.Subject = "7"
+	claims.UserID = 7
+	claims.Actor = &models.Actor{Subject: "1", UserID: 1}
+	return http.StatusOK, &claims, nil
+}
+
+func (fakeUserService) GetUser(_ context.Context, userID int64) (int, models.User, error) {
+	if userID != 7 {
+		return http.StatusNotFound, models.User{}, errors.New(constants.ErrUserNotFound)
+	}
+	return http.StatusOK, models.User{ID: 7, Email: "asif@example.com", Password: "hash", Username: "asif"}, nil
+}
+
+func (fakeUserService) GetUsers(_ context.Context, userIDs []int64) (int, []models.User, error) {
+	return http.StatusOK, []models.User{{ID: 7, Username: "asif"}}, nil
+}
+
+// fakeOAuthService accepts the client "blog-service" with the secret "secret" and the given scopes
+type fakeOAuthService struct {
+	services.OAuthService
+	scopes []string
+}
+
+func (f fakeOAuthService) AuthenticateClient(_ context.Context, clientID, secret string) (int, models.Client, error) {
+	if clientID != "blog-service" || secret != "secret" {
+		return http.StatusUnauthorized, models.Client{}, errors.New("client authentication failed")
+	}
+	return http.StatusOK, models.Client{ClientID: clientID, AllowedScopes: f.scopes}, nil
+}
+
+// newTestClient serves the API on an in-memory listener and returns a client authenticating as
+// clientID with secret
+func newTestClient(t *testing.T, scopes []string, clientID, secret string) (*authclient.Client, *grpc.ClientConn) {
+	listener := bufconn.Listen(1 << 20)
+	srv, _ := NewServer(fakeUserService{}, fakeOAuthService{scopes: scopes})
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	})
+	client, err := authclient.New("passthrough:///bufnet", clientID, secret, dialer)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialer, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return client, conn
+}
+
+func TestServer(t *testing.T) {
+	allScopes := []string{constants.ScopeTokensIntrospect, constants.ScopeUsersRead}
+	ctx := context.Background()
+
+	t.Run("verified token carries the impersonating admin", func(t *testing.T) {
+		client, _ := newTestClient(t, allScopes, "blog-service", "secret")
+
+		claims, err := client.VerifyToken(ctx, "valid-token")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), claims.GetUserId())
+		assert.Equal(t, int64(1), claims.GetActorUserId())
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		client, _ := newTestClient(t, allScopes, "blog-service", "secret")
+
+		_, err := client.VerifyToken(ctx, "forged")
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("user lookups", func(t *testing.T) {
+		client, _ := newTestClient(t, allScopes, "blog-service", "secret")
+
+		user, err := client.GetUser(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, "asif", user.GetUsername())
+
+		_, err = client.GetUser(ctx, 99)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		users, err := client.BatchGetUsers(ctx, []int64{7, 99})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, int64(7), users[0].GetId())
+	})
+
+	t.Run("wrong client secret", func(t *testing.T) {
+		client, _ := newTestClient(t, allScopes, "blog-service", "wrong")
+
+		_, err := client.GetUser(ctx, 7)
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("client without the scope of the method", func(t *testing.T) {
+		client, _ := newTestClient(t, []string{constants.ScopeUsersRead}, "blog-service", "secret")
+
+		_, err := client.VerifyToken(ctx, "valid-token")
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("health checks need no credentials", func(t *testing.T) {
+		_, conn := newTestClient(t, nil, "", "")
+
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: constants.GRPCServiceName})
+
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	})
+}
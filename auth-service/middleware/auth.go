@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"auth-service/constants"
+	"auth-service/controllers"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Middleware defines a function type for HTTP middleware.
+type Middleware func(http.Handler) http.Handler
+
+// Authenticate returns a middleware that rejects requests without a valid, unrevoked user access token
+// and stores the token claims in the request context. Client tokens are refused since the routes it
+// guards act on the calling user, and so are personal access tokens: they are scoped to the APIs of
+// other services and must not manage the account, e.g. create more tokens.
+func Authenticate(tokenSvc services.TokenService) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString, ok := utils.ExtractBearerToken(r)
+			if !ok {
+				controllers.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+				return
+			}
+			if strings.HasPrefix(tokenString, constants.PersonalAccessTokenPrefix) {
+				controllers.RespondWithError(w, http.StatusForbidden, constants.ErrPersonalAccessTokenNotAllowed)
+				return
+			}
+
+			claims, err := tokenSvc.ValidateAccessToken(r.Context(), tokenString)
+			if err != nil {
+				logrus.Warnf("Rejected request with invalid token: %v", err)
+				controllers.RespondWithError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if claims.UserID == 0 {
+				controllers.RespondWithError(w, http.StatusForbidden, constants.ErrUserTokenRequired)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(utils.ContextWithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// AuthenticateClient returns a middleware that only lets confidential clients through that authenticate
+// with HTTP Basic and are allowed scope. Failures are answered with an RFC 6749 invalid_client error.
+func AuthenticateClient(oauthSvc services.OAuthService, scope string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientID, secret, ok := utils.ExtractClientCredentials(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Basic realm="auth-service"`)
+				controllers.RespondWithOAuthError(w, http.StatusUnauthorized, constants.OAuthErrInvalidClient, "client authentication is required")
+				return
+			}
+
+			status, client, err := oauthSvc.AuthenticateClient(r.Context(), clientID, secret)
+			if err != nil {
+				logrus.Warnf("Rejected client %s: %v", clientID, err)
+				if status == http.StatusUnauthorized {
+					w.Header().Set("WWW-Authenticate", `Basic realm="auth-service"`)
+				}
+				controllers.RespondWithRawJSON(w, status, err)
+				return
+			}
+			if !slices.Contains(client.AllowedScopes, scope) {
+				logrus.Warnf("Rejected client %s without scope %s", clientID, scope)
+				controllers.RespondWithOAuthError(w, http.StatusForbidden, constants.OAuthErrInvalidScope, "client is not allowed "+scope)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission returns a middleware that only lets users through whose roles grant permission.
+// It must run after Authenticate. Roles are checked against the database rather than the token
+// claims, so removing a role locks its holder out of admin endpoints immediately. Impersonation
+// tokens are refused whatever the roles of the impersonated user, so that an admin cannot use
+// them to gain permissions or to start another impersonation.
+func RequirePermission(roleSvc services.RoleService, permission string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := utils.ClaimsFromContext(r.Context())
+			if !ok {
+				controllers.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+				return
+			}
+			if claims.Impersonated() {
+				logrus.Warnf("Rejected user %d impersonating user %d at an admin endpoint", claims.Actor.UserID, claims.UserID)
+				controllers.RespondWithError(w, http.StatusForbidden, constants.ErrImpersonationNotAllowed)
+				return
+			}
+
+			allowed, err := roleSvc.HasPermission(r.Context(), claims.UserID, permission)
+			if err != nil {
+				logrus.Errorf("Error checking permission %s: %v", permission, err)
+				controllers.RespondWithError(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			if !allowed {
+				logrus.Warnf("Rejected user %d without permission %s", claims.UserID, permission)
+				controllers.RespondWithError(w, http.StatusForbidden, constants.ErrPermissionDenied)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RejectImpersonation refuses impersonation tokens. It must run after Authenticate and guards the
+// routes that change how a user signs in or that take their data or account away, which support
+// staff must never do on a user's behalf.
+func RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			controllers.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+			return
+		}
+		if claims.Impersonated() {
+			logrus.Warnf("Rejected user %d impersonating user %d at %s", claims.Actor.UserID, claims.UserID, r.URL.Path)
+			controllers.RespondWithError(w, http.StatusForbidden, constants.ErrImpersonationNotAllowed)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RejectClientTokens refuses user tokens issued to OAuth clients. It must run after Authenticate
+// and guards the routes that manage the account, its credentials or other users: a client is
+// only granted the scopes the user consented to, which never include acting as the user here,
+// e.g. to create a personal access token with more scopes than the client has.
+func RejectClientTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := utils.ClaimsFromContext(r.Context())
+		if !ok {
+			controllers.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+			return
+		}
+		if claims.ClientID != "" {
+			logrus.Warnf("Rejected token of client %s for user %d at %s", claims.ClientID, claims.UserID, r.URL.Path)
+			controllers.RespondWithError(w, http.StatusForbidden, constants.ErrClientTokenNotAllowed)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
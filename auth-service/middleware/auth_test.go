@@ -0,0 +1,88 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"auth-service/constants"
+	"auth-service/middleware"
+	"auth-service/services"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubTokenService accepts every bearer token as an access token carrying claims
+type stubTokenService struct {
+	services.TokenService
+	claims utils.TokenClaims
+}
+
+func (s stubTokenService) ValidateAccessToken(context.Context, string) (*utils.TokenClaims, error) {
+	claims := s.claims
+	return &claims, nil
+}
+
+// stubRoleService grants every permission to every user
+type stubRoleService struct {
+	services.RoleService
+}
+
+func (stubRoleService) HasPermission(context.Context, int64, string) (bool, error) {
+	return true, nil
+}
+
+func TestRejectClientTokens(t *testing.T) {
+	firstParty := utils.TokenClaims{UserID: 7}
+	thirdParty := utils.TokenClaims{UserID: 7, ClientID: "client-1", Scope: "openid blog:read"}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	// the chains the router wraps routes in
+	chains := map[string]func(authenticate middleware.Middleware) http.Handler{
+		"userinfo": func(authenticate middleware.Middleware) http.Handler {
+			return authenticate(ok)
+		},
+		"account": func(authenticate middleware.Middleware) http.Handler {
+			return authenticate(middleware.RejectClientTokens(ok))
+		},
+		"owner only": func(authenticate middleware.Middleware) http.Handler {
+			return authenticate(middleware.RejectClientTokens(middleware.RejectImpersonation(ok)))
+		},
+		"admin": func(authenticate middleware.Middleware) http.Handler {
+			requirePermission := middleware.RequirePermission(stubRoleService{}, constants.PermissionUsersManage)
+			return authenticate(middleware.RejectClientTokens(requirePermission(ok)))
+		},
+	}
+
+	tests := []struct {
+		name       string
+		chain      string
+		claims     utils.TokenClaims
+		wantStatus int
+	}{
+		{name: "client token reads userinfo", chain: "userinfo", claims: thirdParty, wantStatus: http.StatusOK},
+		{name: "first-party token manages the account", chain: "account", claims: firstParty, wantStatus: http.StatusOK},
+		{name: "client token cannot manage the account", chain: "account", claims: thirdParty, wantStatus: http.StatusForbidden},
+		{name: "first-party token creates credentials", chain: "owner only", claims: firstParty, wantStatus: http.StatusOK},
+		{name: "client token cannot create credentials", chain: "owner only", claims: thirdParty, wantStatus: http.StatusForbidden},
+		{name: "first-party token of an admin", chain: "admin", claims: firstParty, wantStatus: http.StatusOK},
+		{name: "client token of an admin", chain: "admin", claims: thirdParty, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := chains[tt.chain](middleware.Authenticate(stubTokenService{claims: tt.claims}))
+			req := httptest.NewRequest(http.MethodPost, "/tokens", nil)
+			req.Header.Set("Authorization", "Bearer access-token")
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.Contains(t, rec.Body.String(), constants.ErrClientTokenNotAllowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"auth-service/utils"
+)
+
+// ClientIP is a middleware that stores the IP address of the caller in the request context, so
+// services can throttle by it. auth-service is not deployed behind a proxy, so the address of the
+// connection is used and forwarding headers are ignored; they could be forged by anyone.
+func ClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		next.ServeHTTP(w, r.WithContext(utils.ContextWithClientIP(r.Context(), ip)))
+	})
+}
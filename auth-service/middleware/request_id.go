@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"auth-service/constants"
+	"auth-service/utils"
+)
+
+// RequestID is a middleware that stores the ID of the request in the request context, so audit
+// events can be matched with the logs of other services. The X-Request-ID header of the caller is
+// used when it is a sane identifier; otherwise a new ID is generated. The ID is sent back in the
+// response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(constants.HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID, _ = utils.GenerateRandomID()
+		}
+		w.Header().Set(constants.HeaderRequestID, requestID)
+		next.ServeHTTP(w, r.WithContext(utils.ContextWithRequestID(r.Context(), requestID)))
+	})
+}
+
+// validRequestID accepts IDs of letters, digits, dashes, underscores and dots up to MaxRequestIDLength
+func validRequestID(id string) bool {
+	if id == "" || len(id) > constants.MaxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
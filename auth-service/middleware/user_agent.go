@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"auth-service/constants"
+	"auth-service/utils"
+)
+
+// UserAgent is a middleware that stores the User-Agent header of the caller in the request
+// context, so sessions can show which device they belong to. Long headers are cut to the size
+// stored with a session.
+func UserAgent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent := strings.ToValidUTF8(r.UserAgent(), "")
+		if len(userAgent) > constants.MaxUserAgentLength {
+			userAgent = strings.ToValidUTF8(userAgent[:constants.MaxUserAgentLength], "")
+		}
+		next.ServeHTTP(w, r.WithContext(utils.ContextWithUserAgent(r.Context(), userAgent)))
+	})
+}
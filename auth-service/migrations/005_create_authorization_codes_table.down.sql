@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS scope;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS client_id;
+
+DROP TABLE IF EXISTS authorization_codes;
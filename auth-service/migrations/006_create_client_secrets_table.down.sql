@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_client_secrets_client_id;
+DROP TABLE IF EXISTS client_secrets;
+
+ALTER TABLE clients DROP COLUMN IF EXISTS allowed_scopes;
+ALTER TABLE clients DROP COLUMN IF EXISTS confidential;
@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'users:manage';
+
+DROP INDEX IF EXISTS idx_login_attempts_last_failure_at;
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_email_change_tokens_user_id;
+DROP TABLE IF EXISTS email_change_tokens;
+
+DROP INDEX IF EXISTS idx_users_username;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
+ALTER TABLE users DROP COLUMN IF EXISTS bio;
+ALTER TABLE users DROP COLUMN IF EXISTS username;
+ALTER TABLE users DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_audit_log_action_created_at;
+DROP INDEX IF EXISTS idx_audit_log_target_user_id_created_at;
+
+DELETE FROM audit_log WHERE actor_user_id IS NULL;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS request_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS client_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS email;
+ALTER TABLE audit_log ALTER COLUMN actor_user_id SET NOT NULL;
+ALTER SEQUENCE audit_log_id_seq AS INTEGER;
+ALTER TABLE audit_log ALTER COLUMN id TYPE INTEGER;
@@ -0,0 +1,13 @@
+-- the audit log also records security events: logins, registrations, token refreshes and
+-- revocations and personal access tokens. These have no actor, and come with the user agent and
+-- request ID of the request they happened in.
+ALTER TABLE audit_log ALTER COLUMN id TYPE BIGINT;
+ALTER SEQUENCE audit_log_id_seq AS BIGINT;
+ALTER TABLE audit_log ALTER COLUMN actor_user_id DROP NOT NULL;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS client_id VARCHAR(64);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS user_agent VARCHAR(512);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS request_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target_user_id_created_at ON audit_log(target_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action_created_at ON audit_log(action, created_at);
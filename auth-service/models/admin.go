@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// AdminUser is the view of a user account that admins get from the user management endpoints.
+// The list endpoint leaves Roles and MFAEnabled empty.
+type AdminUser struct {
+	ID                    int64      `json:"id"`
+	Email                 string     `json:"email"`
+	FirstName             string     `json:"first_name"`
+	LastName              string     `json:"last_name"`
+	DisplayName           string     `json:"display_name,omitempty"`
+	Username              string     `json:"username,omitempty"`
+	EmailVerified         bool       `json:"email_verified"`
+	Disabled              bool       `json:"disabled"`
+	DisabledAt            *time.Time `json:"disabled_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	MFAEnabled            bool       `json:"mfa_enabled"`
+	Roles                 []string   `json:"roles,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// UserListRequest holds the query parameters of the admin user list. Query matches emails,
+// usernames and display names case-insensitively.
+type UserListRequest struct {
+	Query    string
+	Page     int
+	PageSize int
+}
+
+// UserListResponse is a page of user accounts
+type UserListResponse struct {
+	Items      []AdminUser `json:"items"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// DisableUserRequest is the optional request body of the disable user endpoint
+type DisableUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonateUserRequest is the request body of the impersonation endpoint. The reason, e.g. the
+// support ticket being worked on, is kept in the audit log.
+type ImpersonateUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AuditEntry is an entry of the audit log: an action an admin took on a user account, or a
+// security event of an account, such as a login. Security events have no actor; their target is
+// the account the event is about.
+type AuditEntry struct {
+	ID           int64     `json:"id"`
+	ActorUserID  int64     `json:"actor_user_id,omitempty"`
+	Action       string    `json:"action"`
+	TargetUserID int64     `json:"target_user_id,omitempty"`
+	Email        string    `json:"email,omitempty"`
+	ClientID     string    `json:"client_id,omitempty"`
+	Details      string    `json:"details,omitempty"`
+	IP           string    `json:"ip,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	RequestID    string    `json:"request_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AuditLogFilter selects audit entries. Zero fields match every entry; From is inclusive and To
+// exclusive.
+type AuditLogFilter struct {
+	TargetUserID int64
+	Action       string
+	From         *time.Time
+	To           *time.Time
+}
+
+// AuditLogRequest holds the query parameters of the audit log
+type AuditLogRequest struct {
+	Filter   AuditLogFilter
+	Page     int
+	PageSize int
+}
+
+// AuditLogResponse is a page of audit entries, newest first
+type AuditLogResponse struct {
+	Items      []AuditEntry `json:"items"`
+	Pagination Pagination   `json:"pagination"`
+}
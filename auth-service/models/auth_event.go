@@ -0,0 +1,12 @@
+package models
+
+// AuthEvent is a security event of auth-service, such as a login or a password change, as it is
+// handed to the audit log. UserID is zero for events that cannot be attributed to an account,
+// e.g. a failed login with an unknown email; Email then holds what was tried.
+type AuthEvent struct {
+	Type     string
+	UserID   int64
+	Email    string
+	ClientID string
+	Details  map[string]string
+}
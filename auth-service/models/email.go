@@ -0,0 +1,9 @@
+package models
+
+// Email is a plain text message sent through a Mailer
+type Email struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
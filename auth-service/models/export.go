@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// AccountExport is the account.json file of a data export: the profile of the user and what
+// auth-service knows about their account besides
+type AccountExport struct {
+	Profile
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"created_at"`
+}
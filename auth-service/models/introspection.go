@@ -0,0 +1,34 @@
+package models
+
+// IntrospectionRequest is the JSON form of an RFC 7662 introspection request
+type IntrospectionRequest struct {
+	Token         string `json:"token" validate:"required"`
+	TokenTypeHint string `json:"token_type_hint,omitempty"`
+}
+
+// IntrospectionResponse is an RFC 7662 token introspection response.
+// Inactive tokens are reported with Active set to false and every other field empty.
+type IntrospectionResponse struct {
+	Active      bool     `json:"active"`
+	Subject     string   `json:"sub,omitempty"`
+	Issuer      string   `json:"iss,omitempty"`
+	Audience    string   `json:"aud,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	ExpiresAt   int64    `json:"exp,omitempty"`
+	IssuedAt    int64    `json:"iat,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	TokenType   string   `json:"token_type,omitempty"`
+	JTI         string   `json:"jti,omitempty"`
+	Actor       *Actor   `json:"act,omitempty"`
+}
+
+// Actor is the act claim of RFC 8693: the party acting as the subject of a token, set when an
+// admin impersonates a user. Like the claims of the token itself, sub is the user id as a string
+// and user_id repeats it as a number.
+type Actor struct {
+	Subject string `json:"sub"`
+	UserID  int64  `json:"user_id,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Invite is a single-use code that lets someone register while registration is restricted. Only
+// the SHA-256 hash of the code is persisted. When Email is set, only that address can use the code;
+// Role, when set, is granted to the user who registers with it.
+type Invite struct {
+	ID        int64      `json:"id"`
+	CodeHash  string     `json:"-"`
+	Email     string     `json:"email,omitempty"`
+	RoleID    int64      `json:"-"`
+	Role      string     `json:"role,omitempty"`
+	CreatedBy int64      `json:"created_by,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	UsedBy    int64      `json:"used_by,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Usable reports whether the invite can still be used to register at now
+func (i Invite) Usable(now time.Time) bool {
+	return i.ID != 0 && i.UsedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// CreateInviteRequest is the request body of the create invite endpoint. Both fields are optional.
+type CreateInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// CreateInviteResponse carries the plain invite code; it is only ever returned here
+type CreateInviteResponse struct {
+	Invite
+	Code string `json:"code"`
+}
+
+// InviteListRequest holds the query parameters of the invite list
+type InviteListRequest struct {
+	Page     int
+	PageSize int
+}
+
+// InviteListResponse is a page of invites, newest first
+type InviteListResponse struct {
+	Items      []Invite   `json:"items"`
+	Pagination Pagination `json:"pagination"`
+}
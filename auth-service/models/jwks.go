@@ -0,0 +1,18 @@
+package models
+
+// JWKS is a JSON Web Key Set (RFC 7517) holding the public keys that verify access tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a single public JSON Web Key. RSA keys use N and E, Ed25519 keys use Crv and X.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// LoginAttempt counts the recent failed logins of an account or a client IP, identified by Key.
+// Logins are refused until LockedUntil.
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// LoginThrottledError is returned when a login is refused because of earlier failed attempts.
+// Status is 423 for a locked account and 429 while backing off; RetryAfter says when to try again.
+type LoginThrottledError struct {
+	Status     int
+	Message    string
+	RetryAfter time.Duration
+}
+
+// NewLoginThrottledError returns a LoginThrottledError
+func NewLoginThrottledError(status int, message string, retryAfter time.Duration) *LoginThrottledError {
+	return &LoginThrottledError{Status: status, Message: message, RetryAfter: retryAfter}
+}
+
+// Error implements the error interface
+func (e *LoginThrottledError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// MagicLinkToken is a single-use passwordless sign-in token mailed to a user. Only the SHA-256
+// hashes of the token and of the nonce cookie of the requesting browser are persisted.
+type MagicLinkToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	NonceHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// MagicLinkRequest is the request body of the passwordless login endpoint
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// MagicLinkNonce is the value of the cookie that binds sign-in links to the browser that requested them
+type MagicLinkNonce struct {
+	Value     string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// UserMFA is the TOTP enrollment of a user. Two-factor authentication is enabled once the user
+// confirmed the enrollment with a code. LastUsedStep is the last accepted time step, so a code
+// cannot be replayed.
+type UserMFA struct {
+	UserID       int64
+	TOTPSecret   string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+// Enabled reports whether the enrollment was confirmed
+func (m UserMFA) Enabled() bool {
+	return m.ConfirmedAt != nil
+}
+
+// MFAChallenge is the second step of a login of a user with two-factor authentication. It is
+// identified by the mfa_token returned from the password login; only the SHA-256 hash of the
+// token is persisted.
+type MFAChallenge struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	Attempts  int
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// MFAEnrollment is the response of the TOTP enrollment endpoint. The secret is shown once so that
+// it can be typed into an authenticator app which cannot scan the QR code.
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFACodeRequest is the request body of endpoints that require a current authentication code
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFALoginRequest is the request body of the second login step
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse carries newly generated recovery codes. They are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+// OAuthError is an RFC 6749 error. It is both the JSON error response and an error value,
+// so services can return it and controllers can send it as is.
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// NewOAuthError returns an OAuthError with the given RFC 6749 error code
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// Error implements the error interface
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// Client is an application registered to obtain tokens through the OAuth 2.0 flows.
+// Public clients hold no secret and must use PKCE. Confidential clients authenticate with a
+// secret and may use the client_credentials grant for the scopes in AllowedScopes.
+type Client struct {
+	ID            int64     `json:"-"`
+	ClientID      string    `json:"client_id"`
+	Name          string    `json:"name"`
+	RedirectURIs  []string  `json:"redirect_uris"`
+	Confidential  bool      `json:"confidential"`
+	AllowedScopes []string  `json:"allowed_scopes,omitempty"`
+	OwnerUserID   int64     `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// ClientSecret is only set in the registration response; it cannot be retrieved later
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// ClientRegistrationRequest is the request body of the client registration endpoint.
+// Redirect URIs are only required for public clients.
+type ClientRegistrationRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	RedirectURIs  []string `json:"redirect_uris"`
+	Confidential  bool     `json:"confidential"`
+	AllowedScopes []string `json:"allowed_scopes"`
+}
+
+// ClientSecret is a credential of a confidential client. Only the SHA-256 hash is persisted.
+// A client has at most two active secrets so that a secret can be rotated without downtime.
+type ClientSecret struct {
+	ID         int64      `json:"id"`
+	ClientID   string     `json:"-"`
+	SecretHash string     `json:"-"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Secret is only set in the response that created it
+	Secret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizationCode is a short-lived, single-use code exchanged at the token endpoint.
+// Only the SHA-256 hash of the code is persisted.
+type AuthorizationCode struct {
+	ID                  int64
+	CodeHash            string
+	ClientID            string
+	UserID              int64
+	RedirectURI         string
+	Scope               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	ExpiresAt           time.Time
+	UsedAt              *time.Time
+	CreatedAt           time.Time
+}
+
+// AuthorizeRequest holds the parameters of an authorization request
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// TokenRequest holds the form parameters of a token request
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+}
+
+// TokenResponse is an RFC 6749 access token response
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// TokenGrant describes what a token pair was granted for. The zero value is a first-party
+// login that is not bound to any client.
+type TokenGrant struct {
+	ClientID string
+	Scope    string
+	Nonce    string
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent announces a change of auth-service to other services. Events are written in the
+// same transaction as the change and read by consumers in id order, each remembering the id of the
+// last event it handled.
+type OutboxEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// UserDeletedPayload is the payload of user.deleted events
+type UserDeletedPayload struct {
+	UserID int64 `json:"user_id"`
+}
+
+// OutboxEventListResponse is the response body of the outbox events endpoint
+type OutboxEventListResponse struct {
+	Events []OutboxEvent `json:"events"`
+}
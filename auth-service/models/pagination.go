@@ -0,0 +1,27 @@
+package models
+
+// Pagination describes a page of a list response. It has the shape of the pagination blocks of
+// blog-service, so clients can page through both services alike.
+type Pagination struct {
+	CurrentPage    int   `json:"current_page"`
+	PageSize       int   `json:"page_size"`
+	TotalItemCount int64 `json:"total_item_count"`
+	TotalPages     int   `json:"total_pages"`
+	HasMore        bool  `json:"has_more"`
+	IsFirstPage    bool  `json:"is_first_page"`
+	IsLastPage     bool  `json:"is_last_page"`
+}
+
+// NewPagination returns the pagination of the given page out of total items
+func NewPagination(page, pageSize int, total int64) Pagination {
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return Pagination{
+		CurrentPage:    page,
+		PageSize:       pageSize,
+		TotalItemCount: total,
+		TotalPages:     totalPages,
+		HasMore:        page < totalPages,
+		IsFirstPage:    page == 1,
+		IsLastPage:     page >= totalPages,
+	}
+}
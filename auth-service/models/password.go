@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token mailed to a user who forgot their password.
+// Only the SHA-256 hash of the token is persisted.
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// ForgotPasswordRequest is the request body of the forgot password endpoint
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest is the request body of the reset password endpoint
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// PersonalAccessToken is a long-lived token a user creates to call the APIs from scripts.
+// Only the hash of the token is stored; Prefix keeps its first characters so the user can
+// tell their tokens apart.
+type PersonalAccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // nil for tokens that do not expire
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Expired reports whether the token has passed its expiry
+func (t PersonalAccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
+
+// CreatePersonalAccessTokenRequest is the payload to create a personal access token.
+// ExpiresInDays is optional; tokens without it never expire.
+type CreatePersonalAccessTokenRequest struct {
+	Name          string `json:"name" validate:"required"`
+	Scope         string `json:"scope" validate:"required"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"`
+}
+
+// CreatePersonalAccessTokenResponse returns a new token. The plain token is only shown here.
+type CreatePersonalAccessTokenResponse struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Profile is the authenticated user's own view of their account
+type Profile struct {
+	ID            int64  `json:"id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	DisplayName   string `json:"display_name"`
+	Username      string `json:"username"`
+	Bio           string `json:"bio"`
+	AvatarURL     string `json:"avatar_url"`
+	// DeletionScheduledAt is when the account is deleted, if the user asked for it
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// PublicProfile is what anyone can see of a user with a username. It never includes the email.
+type PublicProfile struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+// UpdateProfileRequest is the request body of PATCH /me. Fields that are left out are not
+// changed; an empty string clears a field.
+type UpdateProfileRequest struct {
+	FirstName   *string `json:"first_name"`
+	LastName    *string `json:"last_name"`
+	DisplayName *string `json:"display_name"`
+	Username    *string `json:"username"`
+	Bio         *string `json:"bio"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+// ChangePasswordRequest is the request body of the change password endpoint
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// ChangeEmailRequest is the request body of the change email endpoint. The current password is
+// required so that a stolen access token cannot take over the account.
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// DeleteAccountRequest is the request body of DELETE /me. The password is required so that a
+// stolen access token cannot delete the account.
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// AccountDeletionResponse tells the user when their account is deleted
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// EmailChangeToken is a single-use token mailed to the new address of a user who wants to change
+// their email. Only the SHA-256 hash of the token is persisted.
+type EmailChangeToken struct {
+	ID        int64
+	UserID    int64
+	NewEmail  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
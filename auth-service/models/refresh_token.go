@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// RefreshToken is an opaque, DB-stored credential used to obtain new access tokens.
+// Only the SHA-256 hash of the token is persisted. Tokens rotated from the same
+// login share a FamilyID so that a replayed token can revoke the whole chain.
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	FamilyID  string
+	ClientID  string // empty for first-party logins
+	Scope     string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// RefreshRequest is the request body of the refresh endpoint
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
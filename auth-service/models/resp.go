@@ -1,8 +1,16 @@
 package models
 
 type LoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token,omitempty"`
-	ExpiresAt    int64  `json:"expires_at"`
-	Email        string `json:"email"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at,omitempty"`
+	Email            string `json:"email"`
+	Scope            string `json:"scope,omitempty"`
+	IDToken          string `json:"id_token,omitempty"`
+
+	// MFARequired is set instead of tokens when the user has two-factor authentication enabled;
+	// the MFAToken must then be exchanged with a code at /login/mfa before ExpiresAt
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RevokedToken is an access token that was invalidated before its expiry, identified by its jti
+type RevokedToken struct {
+	JTI       string
+	UserID    int64
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// UserTokenCutoff invalidates every access token issued to a user before RevokedBefore
+type UserTokenCutoff struct {
+	UserID        int64
+	RevokedBefore time.Time
+}
+
+// LogoutRequest is the optional request body of the logout endpoint
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// Role groups permissions that can be granted to users
+type Role struct {
+	ID          int64    `json:"-"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleAssignmentRequest is the request body of the role assignment endpoint
+type RoleAssignmentRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// UserRoles lists the roles of a user and the permissions they add up to
+type UserRoles struct {
+	UserID      int64    `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
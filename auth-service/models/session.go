@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session is one login of a user on one device. Its refresh tokens form the family with the
+// session's FamilyID, and its access tokens carry the FamilyID in the sid claim.
+type Session struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	FamilyID   string     `json:"-"`
+	ClientID   string     `json:"client_id,omitempty"` // empty for first-party logins
+	UserAgent  string     `json:"user_agent,omitempty"`
+	IP         string     `json:"ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-"`
+
+	// Current marks the session of the access token the list was requested with
+	Current bool `json:"current"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type User struct {
 	ID       int64  `json:"id"`
 	Email    string `json:"email"`
@@ -7,6 +9,45 @@ type User struct {
 
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+
+	// Profile fields; they are set through PATCH /me, never at registration
+	DisplayName string `json:"display_name,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Bio         string `json:"bio,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+
+	// EmailVerifiedAt is nil until the user followed a verification link. It is never read
+	// from request bodies, so a user cannot register as verified.
+	EmailVerifiedAt *time.Time `json:"-"`
+
+	// DisabledAt is set while an admin has disabled the account; disabled users cannot log in
+	DisabledAt *time.Time `json:"-"`
+	// PasswordResetRequired is set by an admin and cleared when the user sets a new password
+	PasswordResetRequired bool `json:"-"`
+
+	// DeletionScheduledAt is set while the user asked for their account to be deleted; the account
+	// is deleted once it has passed, unless the user cancels first
+	DeletionScheduledAt *time.Time `json:"-"`
+
+	// InviteCode is only read at registration, where it may be required by the registration mode
+	InviteCode string `json:"invite_code,omitempty"`
+
+	CreatedAt time.Time `json:"-"`
+}
+
+// EmailVerified reports whether the user proved they own their email address
+func (u User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Disabled reports whether an admin disabled the account
+func (u User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// DeletionScheduled reports whether the user asked for their account to be deleted
+func (u User) DeletionScheduled() bool {
+	return u.DeletionScheduledAt != nil
 }
 
 type LoginRequest struct {
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// EmailVerificationToken is a single-use token mailed to a user to prove they own their email
+// address. Only the SHA-256 hash of the token is persisted.
+type EmailVerificationToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// ResendVerificationRequest is the request body of the resend verification email endpoint
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
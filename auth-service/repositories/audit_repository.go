@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"auth-service/models"
+)
+
+// AuditRepository is a repository for the audit log of admin actions and security events
+type AuditRepository interface {
+	Create(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, filter models.AuditLogFilter, limit, offset int) ([]models.AuditEntry, int64, error)
+}
+
+// auditRepository is a concrete implementation of AuditRepository
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository returns a new instance of auditRepository
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create inserts a new audit entry into the database
+func (r auditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	query := `INSERT INTO audit_log (actor_user_id, action, target_user_id, email, client_id, details, ip, user_agent, request_id)
+		VALUES (NULLIF($1, 0), $2, NULLIF($3, 0), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''))
+		RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, entry.ActorUserID, entry.Action, entry.TargetUserID, entry.Email, entry.ClientID,
+		entry.Details, entry.IP, entry.UserAgent, entry.RequestID).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// List returns a page of the audit entries matching filter, newest first, together with the
+// number of matching entries
+func (r auditRepository) List(ctx context.Context, filter models.AuditLogFilter, limit, offset int) ([]models.AuditEntry, int64, error) {
+	conditions := []string{}
+	args := []any{}
+	if filter.TargetUserID != 0 {
+		args = append(args, filter.TargetUserID)
+		conditions = append(conditions, fmt.Sprintf("target_user_id = $%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	where := ``
+	if len(conditions) > 0 {
+		where = ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		log.Printf("Error counting audit entries: %v", err)
+		return nil, 0, err
+	}
+
+	queryStr := fmt.Sprintf(`SELECT id, COALESCE(actor_user_id, 0), action, COALESCE(target_user_id, 0), COALESCE(email, ''),
+		COALESCE(client_id, ''), COALESCE(details, ''), COALESCE(ip, ''), COALESCE(user_agent, ''), COALESCE(request_id, ''), created_at
+		FROM audit_log%s ORDER BY id DESC LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, queryStr, append(args, limit, offset)...)
+	if err != nil {
+		log.Printf("Error listing audit entries: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry := models.AuditEntry{}
+		err := rows.Scan(&entry.ID, &entry.ActorUserID, &entry.Action, &entry.TargetUserID, &entry.Email, &entry.ClientID,
+			&entry.Details, &entry.IP, &entry.UserAgent, &entry.RequestID, &entry.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"auth-service/models"
+)
+
+// AuthorizationCodeRepository is a repository for OAuth authorization codes
+type AuthorizationCodeRepository interface {
+	Create(ctx context.Context, code *models.AuthorizationCode) error
+	GetByHash(ctx context.Context, codeHash string) (models.AuthorizationCode, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+}
+
+// authorizationCodeRepository is a concrete implementation of AuthorizationCodeRepository
+type authorizationCodeRepository struct {
+	db *sql.DB
+}
+
+// NewAuthorizationCodeRepository returns a new instance of authorizationCodeRepository
+func NewAuthorizationCodeRepository(db *sql.DB) AuthorizationCodeRepository {
+	return &authorizationCodeRepository{db: db}
+}
+
+// Create inserts a new authorization code into the database
+func (r authorizationCodeRepository) Create(ctx context.Context, code *models.AuthorizationCode) error {
+	query := `INSERT INTO authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, code_challenge_method, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		code.Scope,
+		code.Nonce,
+		code.CodeChallenge,
+		code.CodeChallengeMethod,
+		code.ExpiresAt,
+	).Scan(&code.ID, &code.CreatedAt)
+}
+
+// GetByHash retrieves an authorization code by its hash. Returns a zero value code if none matches.
+func (r authorizationCodeRepository) GetByHash(ctx context.Context, codeHash string) (models.AuthorizationCode, error) {
+	code := models.AuthorizationCode{}
+	var usedAt sql.NullTime
+	queryStr := `SELECT id, code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, code_challenge_method, expires_at, used_at, created_at
+		FROM authorization_codes WHERE code_hash = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, codeHash).Scan(
+		&code.ID,
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.Scope,
+		&code.Nonce,
+		&code.CodeChallenge,
+		&code.CodeChallengeMethod,
+		&code.ExpiresAt,
+		&usedAt,
+		&code.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving authorization code: %v", err)
+		return models.AuthorizationCode{}, err
+	}
+
+	code.UsedAt = nullTimePtr(usedAt)
+	return code, nil
+}
+
+// MarkUsed flags an authorization code as redeemed. It returns false when the code was already used.
+func (r authorizationCodeRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE authorization_codes SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"auth-service/models"
+
+	"github.com/lib/pq"
+)
+
+// ClientRepository is a repository for registered OAuth clients
+type ClientRepository interface {
+	Create(ctx context.Context, client *models.Client) error
+	GetByClientID(ctx context.Context, clientID string) (models.Client, error)
+	CreateSecret(ctx context.Context, secret *models.ClientSecret) error
+	ListActiveSecrets(ctx context.Context, clientID string) ([]models.ClientSecret, error)
+	RevokeSecret(ctx context.Context, clientID string, secretID int64) (bool, error)
+	RevokeSecretsExceptNewest(ctx context.Context, clientID string, keep int) error
+}
+
+// clientRepository is a concrete implementation of ClientRepository
+type clientRepository struct {
+	db *sql.DB
+}
+
+// NewClientRepository returns a new instance of clientRepository
+func NewClientRepository(db *sql.DB) ClientRepository {
+	return &clientRepository{db: db}
+}
+
+// Create inserts a new client into the database
+func (r clientRepository) Create(ctx context.Context, client *models.Client) error {
+	query := `INSERT INTO clients (client_id, name, redirect_uris, confidential, allowed_scopes, owner_user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		client.ClientID,
+		client.Name,
+		pq.Array(client.RedirectURIs),
+		client.Confidential,
+		pq.Array(client.AllowedScopes),
+		client.OwnerUserID,
+	).Scan(&client.ID, &client.CreatedAt)
+}
+
+// GetByClientID retrieves a client by its public identifier. Returns a zero value client if none matches.
+func (r clientRepository) GetByClientID(ctx context.Context, clientID string) (models.Client, error) {
+	client := models.Client{}
+	var ownerUserID sql.NullInt64
+	queryStr := `SELECT id, client_id, name, redirect_uris, confidential, allowed_scopes, owner_user_id, created_at FROM clients WHERE client_id = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, clientID).Scan(
+		&client.ID,
+		&client.ClientID,
+		&client.Name,
+		pq.Array(&client.RedirectURIs),
+		&client.Confidential,
+		pq.Array(&client.AllowedScopes),
+		&ownerUserID,
+		&client.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving client: %v", err)
+		return models.Client{}, err
+	}
+
+	client.OwnerUserID = ownerUserID.Int64
+	return client, nil
+}
+
+// CreateSecret inserts a new client secret into the database
+func (r clientRepository) CreateSecret(ctx context.Context, secret *models.ClientSecret) error {
+	query := `INSERT INTO client_secrets (client_id, secret_hash) VALUES ($1, $2) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, secret.ClientID, secret.SecretHash).Scan(&secret.ID, &secret.CreatedAt)
+}
+
+// ListActiveSecrets returns the secrets of a client that were not revoked, newest first
+func (r clientRepository) ListActiveSecrets(ctx context.Context, clientID string) ([]models.ClientSecret, error) {
+	queryStr := `SELECT id, client_id, secret_hash, created_at FROM client_secrets
+		WHERE client_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, queryStr, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var secrets []models.ClientSecret
+	for rows.Next() {
+		secret := models.ClientSecret{}
+		if err := rows.Scan(&secret.ID, &secret.ClientID, &secret.SecretHash, &secret.CreatedAt); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, rows.Err()
+}
+
+// RevokeSecret revokes one secret of a client. It returns false when no active secret matched.
+func (r clientRepository) RevokeSecret(ctx context.Context, clientID string, secretID int64) (bool, error) {
+	query := `UPDATE client_secrets SET revoked_at = CURRENT_TIMESTAMP WHERE client_id = $1 AND id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, clientID, secretID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// RevokeSecretsExceptNewest revokes every active secret of a client but the keep newest ones
+func (r clientRepository) RevokeSecretsExceptNewest(ctx context.Context, clientID string, keep int) error {
+	query := `UPDATE client_secrets SET revoked_at = CURRENT_TIMESTAMP
+		WHERE client_id = $1 AND revoked_at IS NULL AND id NOT IN (
+			SELECT id FROM client_secrets WHERE client_id = $1 AND revoked_at IS NULL
+			ORDER BY created_at DESC, id DESC LIMIT $2
+		)`
+
+	_, err := r.db.ExecContext(ctx, query, clientID, keep)
+	return err
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"auth-service/models"
+)
+
+// EmailChangeRepository is a repository for email change tokens
+type EmailChangeRepository interface {
+	Create(ctx context.Context, token *models.EmailChangeToken) error
+	GetByHash(ctx context.Context, tokenHash string) (models.EmailChangeToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+}
+
+// emailChangeRepository is a concrete implementation of EmailChangeRepository
+type emailChangeRepository struct {
+	db *sql.DB
+}
+
+// NewEmailChangeRepository returns a new instance of emailChangeRepository
+func NewEmailChangeRepository(db *sql.DB) EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+// Create inserts a new email change token into the database
+func (r emailChangeRepository) Create(ctx context.Context, token *models.EmailChangeToken) error {
+	query := `INSERT INTO email_change_tokens (user_id, new_email, token_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, token.UserID, token.NewEmail, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetByHash retrieves an email change token by its hash. Returns a zero value token if none matches.
+func (r emailChangeRepository) GetByHash(ctx context.Context, tokenHash string) (models.EmailChangeToken, error) {
+	token := models.EmailChangeToken{}
+	var usedAt sql.NullTime
+	queryStr := `SELECT id, user_id, new_email, token_hash, expires_at, used_at, created_at FROM email_change_tokens WHERE token_hash = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.NewEmail,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving email change token: %v", err)
+		return models.EmailChangeToken{}, err
+	}
+
+	token.UsedAt = nullTimePtr(usedAt)
+	return token, nil
+}
+
+// MarkUsed flags an email change token as used. It returns false when it was already used.
+func (r emailChangeRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE email_change_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// InvalidateForUser marks every unused email change token of a user as used
+func (r emailChangeRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE email_change_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
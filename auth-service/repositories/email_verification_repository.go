@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"auth-service/models"
+)
+
+// EmailVerificationRepository is a repository for email verification tokens
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, token *models.EmailVerificationToken) error
+	GetByHash(ctx context.Context, tokenHash string) (models.EmailVerificationToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+}
+
+// emailVerificationRepository is a concrete implementation of EmailVerificationRepository
+type emailVerificationRepository struct {
+	db *sql.DB
+}
+
+// NewEmailVerificationRepository returns a new instance of emailVerificationRepository
+func NewEmailVerificationRepository(db *sql.DB) EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+// Create inserts a new email verification token into the database
+func (r emailVerificationRepository) Create(ctx context.Context, token *models.EmailVerificationToken) error {
+	query := `INSERT INTO email_verification_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetByHash retrieves a email verification token by its hash. Returns a zero value token if none matches.
+func (r emailVerificationRepository) GetByHash(ctx context.Context, tokenHash string) (models.EmailVerificationToken, error) {
+	token := models.EmailVerificationToken{}
+	var usedAt sql.NullTime
+	queryStr := `SELECT id, user_id, token_hash, expires_at, used_at, created_at FROM email_verification_tokens WHERE token_hash = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving email verification token: %v", err)
+		return models.EmailVerificationToken{}, err
+	}
+
+	token.UsedAt = nullTimePtr(usedAt)
+	return token, nil
+}
+
+// MarkUsed flags a email verification token as used. It returns false when it was already used.
+func (r emailVerificationRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE email_verification_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// InvalidateForUser marks every unused email verification token of a user as used
+func (r emailVerificationRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE email_verification_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
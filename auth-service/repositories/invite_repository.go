@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"auth-service/models"
+)
+
+// InviteRepository is a repository for registration invite codes
+type InviteRepository interface {
+	Create(ctx context.Context, invite *models.Invite) error
+	GetByHash(ctx context.Context, codeHash string) (models.Invite, error)
+	List(ctx context.Context, limit, offset int) ([]models.Invite, int64, error)
+	Redeem(ctx context.Context, id, userID int64) (bool, error)
+	Revoke(ctx context.Context, id int64) (bool, error)
+}
+
+// inviteColumns are the columns every invite query selects, in the order scanInvite reads them
+const inviteColumns = `i.id, i.code_hash, COALESCE(i.email, ''), COALESCE(i.role_id, 0), COALESCE(r.name, ''), ` +
+	`COALESCE(i.created_by, 0), i.expires_at, i.used_at, COALESCE(i.used_by, 0), i.revoked_at, i.created_at`
+
+// inviteRepository is a concrete implementation of InviteRepository
+type inviteRepository struct {
+	db *sql.DB
+}
+
+// NewInviteRepository returns a new instance of inviteRepository
+func NewInviteRepository(db *sql.DB) InviteRepository {
+	return &inviteRepository{db: db}
+}
+
+// Create inserts a new invite into the database
+func (r inviteRepository) Create(ctx context.Context, invite *models.Invite) error {
+	query := `INSERT INTO invites (code_hash, email, role_id, created_by, expires_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, 0), NULLIF($4, 0), $5) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, invite.CodeHash, invite.Email, invite.RoleID, invite.CreatedBy, invite.ExpiresAt).
+		Scan(&invite.ID, &invite.CreatedAt)
+}
+
+// GetByHash retrieves an invite by the hash of its code. Returns a zero value invite if none matches.
+func (r inviteRepository) GetByHash(ctx context.Context, codeHash string) (models.Invite, error) {
+	queryStr := `SELECT ` + inviteColumns + ` FROM invites i LEFT JOIN roles r ON r.id = i.role_id WHERE i.code_hash = $1`
+
+	invite, err := scanInvite(r.db.QueryRowContext(ctx, queryStr, codeHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Invite{}, nil
+	}
+	if err != nil {
+		log.Printf("Error retrieving invite: %v", err)
+		return models.Invite{}, err
+	}
+	return invite, nil
+}
+
+// List returns a page of invites, newest first, together with the number of invites
+func (r inviteRepository) List(ctx context.Context, limit, offset int) ([]models.Invite, int64, error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM invites`).Scan(&total); err != nil {
+		log.Printf("Error counting invites: %v", err)
+		return nil, 0, err
+	}
+
+	queryStr := `SELECT ` + inviteColumns + ` FROM invites i LEFT JOIN roles r ON r.id = i.role_id
+		ORDER BY i.id DESC LIMIT $1 OFFSET $2`
+	rows, err := r.db.QueryContext(ctx, queryStr, limit, offset)
+	if err != nil {
+		log.Printf("Error listing invites: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	invites := []models.Invite{}
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invites = append(invites, invite)
+	}
+	return invites, total, rows.Err()
+}
+
+// Redeem marks an invite as used by the given user. It returns false when the invite was used,
+// revoked or expired in the meantime.
+func (r inviteRepository) Redeem(ctx context.Context, id, userID int64) (bool, error) {
+	query := `UPDATE invites SET used_at = CURRENT_TIMESTAMP, used_by = $2
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// Revoke revokes an invite. It returns false when there is no such invite or it was already used
+// or revoked.
+func (r inviteRepository) Revoke(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE invites SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// scanInvite reads a row of inviteColumns
+func scanInvite(row rowScanner) (models.Invite, error) {
+	invite := models.Invite{}
+	var usedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&invite.ID,
+		&invite.CodeHash,
+		&invite.Email,
+		&invite.RoleID,
+		&invite.Role,
+		&invite.CreatedBy,
+		&invite.ExpiresAt,
+		&usedAt,
+		&invite.UsedBy,
+		&revokedAt,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return models.Invite{}, err
+	}
+	invite.UsedAt = nullTimePtr(usedAt)
+	invite.RevokedAt = nullTimePtr(revokedAt)
+	return invite, nil
+}
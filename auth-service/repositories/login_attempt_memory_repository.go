@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"auth-service/models"
+)
+
+// memoryLoginAttemptRepository is an in-process implementation of LoginAttemptRepository. Its
+// counters are lost on restart and not shared between instances.
+type memoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+// NewMemoryLoginAttemptRepository returns a new instance of memoryLoginAttemptRepository
+func NewMemoryLoginAttemptRepository() LoginAttemptRepository {
+	return &memoryLoginAttemptRepository{attempts: map[string]models.LoginAttempt{}}
+}
+
+// Get returns the counter of a key, or a zero value models.LoginAttempt if there is none
+func (r *memoryLoginAttemptRepository) Get(_ context.Context, key string) (models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.attempts[key], nil
+}
+
+// RecordFailure counts a failed login of a key and returns the updated counter. The count starts
+// over when the previous failure happened before windowStart.
+func (r *memoryLoginAttemptRepository) RecordFailure(_ context.Context, key string, now, windowStart time.Time) (models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(windowStart) {
+		attempt = models.LoginAttempt{Key: key, LockedUntil: attempt.LockedUntil}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	r.attempts[key] = attempt
+	return attempt, nil
+}
+
+// Lock refuses logins of a key until the given time
+func (r *memoryLoginAttemptRepository) Lock(_ context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.attempts[key]; ok {
+		attempt.LockedUntil = until
+		r.attempts[key] = attempt
+	}
+	return nil
+}
+
+// Delete forgets the counter of a key
+func (r *memoryLoginAttemptRepository) Delete(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+// DeleteStale removes counters whose last failure happened before the given time and that are
+// no longer locked
+func (r *memoryLoginAttemptRepository) DeleteStale(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, attempt := range r.attempts {
+		if attempt.LastFailureAt.Before(before) && attempt.LockedUntil.Before(before) {
+			delete(r.attempts, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"auth-service/models"
+)
+
+// LoginAttemptRepository is a repository for failed login counters. NewLoginAttemptRepository
+// shares them between instances through Postgres; NewMemoryLoginAttemptRepository keeps them in
+// the process.
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, key string) (models.LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, now, windowStart time.Time) (models.LoginAttempt, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Delete(ctx context.Context, key string) error
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+// loginAttemptRepository is a Postgres implementation of LoginAttemptRepository
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepository returns a new instance of loginAttemptRepository
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// Get retrieves the counter of a key. Returns a zero value models.LoginAttempt if there is none.
+func (r loginAttemptRepository) Get(ctx context.Context, key string) (models.LoginAttempt, error) {
+	attempt := models.LoginAttempt{}
+	var lockedUntil sql.NullTime
+	queryStr := `SELECT key, failures, last_failure_at, locked_until FROM login_attempts WHERE key = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, key).Scan(
+		&attempt.Key,
+		&attempt.Failures,
+		&attempt.LastFailureAt,
+		&lockedUntil,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving login attempts: %v", err)
+		return models.LoginAttempt{}, err
+	}
+
+	attempt.LockedUntil = lockedUntil.Time
+	return attempt, nil
+}
+
+// RecordFailure counts a failed login of a key and returns the updated counter. The count starts
+// over when the previous failure happened before windowStart.
+func (r loginAttemptRepository) RecordFailure(ctx context.Context, key string, now, windowStart time.Time) (models.LoginAttempt, error) {
+	attempt := models.LoginAttempt{}
+	var lockedUntil sql.NullTime
+	query := `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until`
+
+	err := r.db.QueryRowContext(ctx, query, key, now, windowStart).Scan(
+		&attempt.Key,
+		&attempt.Failures,
+		&attempt.LastFailureAt,
+		&lockedUntil,
+	)
+	if err != nil {
+		return models.LoginAttempt{}, err
+	}
+
+	attempt.LockedUntil = lockedUntil.Time
+	return attempt, nil
+}
+
+// Lock refuses logins of a key until the given time
+func (r loginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = $1 WHERE key = $2`
+
+	_, err := r.db.ExecContext(ctx, query, until, key)
+	return err
+}
+
+// Delete forgets the counter of a key
+func (r loginAttemptRepository) Delete(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+
+	_, err := r.db.ExecContext(ctx, query, key)
+	return err
+}
+
+// DeleteStale removes counters whose last failure happened before the given time and that are
+// no longer locked
+func (r loginAttemptRepository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM login_attempts WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"auth-service/models"
+)
+
+// MagicLinkRepository is a repository for passwordless sign-in tokens
+type MagicLinkRepository interface {
+	Create(ctx context.Context, token *models.MagicLinkToken) error
+	GetByHash(ctx context.Context, tokenHash string) (models.MagicLinkToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+	CountCreatedSince(ctx context.Context, userID int64, since time.Time) (int, error)
+}
+
+// magicLinkRepository is a concrete implementation of MagicLinkRepository
+type magicLinkRepository struct {
+	db *sql.DB
+}
+
+// NewMagicLinkRepository returns a new instance of magicLinkRepository
+func NewMagicLinkRepository(db *sql.DB) MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+// Create inserts a new sign-in token into the database
+func (r magicLinkRepository) Create(ctx context.Context, token *models.MagicLinkToken) error {
+	query := `INSERT INTO magic_link_tokens (user_id, token_hash, nonce_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, token.UserID, token.TokenHash, token.NonceHash, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetByHash retrieves a sign-in token by its hash. Returns a zero value token if none matches.
+func (r magicLinkRepository) GetByHash(ctx context.Context, tokenHash string) (models.MagicLinkToken, error) {
+	token := models.MagicLinkToken{}
+	var usedAt sql.NullTime
+	queryStr := `SELECT id, user_id, token_hash, nonce_hash, expires_at, used_at, created_at FROM magic_link_tokens WHERE token_hash = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.NonceHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.MagicLinkToken{}, nil
+	}
+	if err != nil {
+		log.Printf("Error retrieving sign-in token: %v", err)
+		return models.MagicLinkToken{}, err
+	}
+
+	token.UsedAt = nullTimePtr(usedAt)
+	return token, nil
+}
+
+// MarkUsed flags a sign-in token as used. It returns false when it was already used.
+func (r magicLinkRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE magic_link_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// InvalidateForUser marks every unused sign-in token of a user as used
+func (r magicLinkRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE magic_link_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// CountCreatedSince returns how many sign-in tokens were created for a user since the given time,
+// used or not
+func (r magicLinkRepository) CountCreatedSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	queryStr := `SELECT COUNT(*) FROM magic_link_tokens WHERE user_id = $1 AND created_at > $2`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, queryStr, userID, since).Scan(&count)
+	return count, err
+}
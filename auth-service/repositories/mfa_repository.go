@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"auth-service/models"
+)
+
+// MFARepository is a repository for TOTP enrollments, recovery codes and login challenges
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID int64) (models.UserMFA, error)
+	Upsert(ctx context.Context, userID int64, secret string) error
+	Confirm(ctx context.Context, userID int64, step int64) error
+	UseStep(ctx context.Context, userID int64, step int64) (bool, error)
+	Delete(ctx context.Context, userID int64) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+
+	CreateChallenge(ctx context.Context, challenge *models.MFAChallenge) error
+	GetChallengeByHash(ctx context.Context, tokenHash string) (models.MFAChallenge, error)
+	IncrementChallengeAttempts(ctx context.Context, id int64, maxAttempts int) (bool, error)
+	MarkChallengeUsed(ctx context.Context, id int64) (bool, error)
+}
+
+// mfaRepository is a concrete implementation of MFARepository
+type mfaRepository struct {
+	db *sql.DB
+}
+
+// NewMFARepository returns a new instance of mfaRepository
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+// GetByUserID retrieves the TOTP enrollment of a user. Returns a zero value enrollment if there is none.
+func (r mfaRepository) GetByUserID(ctx context.Context, userID int64) (models.UserMFA, error) {
+	mfa := models.UserMFA{}
+	var confirmedAt sql.NullTime
+	queryStr := `SELECT user_id, totp_secret, confirmed_at, last_used_step, created_at FROM user_mfa WHERE user_id = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, userID).Scan(
+		&mfa.UserID,
+		&mfa.TOTPSecret,
+		&confirmedAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving mfa enrollment: %v", err)
+		return models.UserMFA{}, err
+	}
+
+	mfa.ConfirmedAt = nullTimePtr(confirmedAt)
+	return mfa, nil
+}
+
+// Upsert stores a new unconfirmed secret for a user, replacing a pending enrollment. A confirmed
+// enrollment is left untouched.
+func (r mfaRepository) Upsert(ctx context.Context, userID int64, secret string) error {
+	query := `INSERT INTO user_mfa (user_id, totp_secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET totp_secret = EXCLUDED.totp_secret, last_used_step = 0, created_at = CURRENT_TIMESTAMP
+		WHERE user_mfa.confirmed_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID, secret)
+	return err
+}
+
+// Confirm enables the enrollment of a user and records the step of the code that confirmed it
+func (r mfaRepository) Confirm(ctx context.Context, userID int64, step int64) error {
+	query := `UPDATE user_mfa SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $2 WHERE user_id = $1 AND confirmed_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID, step)
+	return err
+}
+
+// UseStep records that a code of the given time step was accepted. It returns false when the
+// step or a later one was already used, so that a code cannot be replayed.
+func (r mfaRepository) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// Delete removes the enrollment, recovery codes and pending challenges of a user
+func (r mfaRepository) Delete(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM mfa_challenges WHERE user_id = $1`,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM user_mfa WHERE user_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes deletes the recovery codes of a user and stores the given hashes instead
+func (r mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, codeHash); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UseRecoveryCode flags a recovery code of a user as used. It returns false when the code does
+// not exist or was already used.
+func (r mfaRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// CreateChallenge inserts a new login challenge into the database
+func (r mfaRepository) CreateChallenge(ctx context.Context, challenge *models.MFAChallenge) error {
+	query := `INSERT INTO mfa_challenges (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, challenge.UserID, challenge.TokenHash, challenge.ExpiresAt).Scan(&challenge.ID, &challenge.CreatedAt)
+}
+
+// GetChallengeByHash retrieves a login challenge by the hash of its token. Returns a zero value challenge if none matches.
+func (r mfaRepository) GetChallengeByHash(ctx context.Context, tokenHash string) (models.MFAChallenge, error) {
+	challenge := models.MFAChallenge{}
+	var usedAt sql.NullTime
+	queryStr := `SELECT id, user_id, token_hash, attempts, expires_at, used_at, created_at FROM mfa_challenges WHERE token_hash = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, tokenHash).Scan(
+		&challenge.ID,
+		&challenge.UserID,
+		&challenge.TokenHash,
+		&challenge.Attempts,
+		&challenge.ExpiresAt,
+		&usedAt,
+		&challenge.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving mfa challenge: %v", err)
+		return models.MFAChallenge{}, err
+	}
+
+	challenge.UsedAt = nullTimePtr(usedAt)
+	return challenge, nil
+}
+
+// IncrementChallengeAttempts counts an attempt to complete a challenge. It returns false when the
+// challenge was used or has no attempts left.
+func (r mfaRepository) IncrementChallengeAttempts(ctx context.Context, id int64, maxAttempts int) (bool, error) {
+	query := `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1 AND used_at IS NULL AND attempts < $2 AND expires_at > $3`
+
+	result, err := r.db.ExecContext(ctx, query, id, maxAttempts, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// MarkChallengeUsed flags a challenge as completed. It returns false when it was already used.
+func (r mfaRepository) MarkChallengeUsed(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE mfa_challenges SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
@@ -0,0 +1,84 @@
+// Code generated by mockery v2.46.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "auth-service/models"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuditRepository is an autogenerated mock type for the AuditRepository type
+type AuditRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, entry
+func (_m *AuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	ret := _m.Called(ctx, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuditEntry) error); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, filter, limit, offset
+func (_m *AuditRepository) List(ctx context.Context, filter models.AuditLogFilter, limit int, offset int) ([]models.AuditEntry, int64, error) {
+	ret := _m.Called(ctx, filter, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []models.AuditEntry
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.AuditLogFilter, int, int) ([]models.AuditEntry, int64, error)); ok {
+		return rf(ctx, filter, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.AuditLogFilter, int, int) []models.AuditEntry); ok {
+		r0 = rf(ctx, filter, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.AuditLogFilter, int, int) int64); ok {
+		r1 = rf(ctx, filter, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.AuditLogFilter, int, int) error); ok {
+		r2 = rf(ctx, filter, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewAuditRepository creates a new instance of AuditRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuditRepository {
+	mock := &AuditRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
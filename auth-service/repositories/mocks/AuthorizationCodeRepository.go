@@ -0,0 +1,103 @@
+// Code generated by mockery v2.46.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "auth-service/models"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuthorizationCodeRepository is an autogenerated mock type for the AuthorizationCodeRepository type
+type AuthorizationCodeRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, code
+func (_m *AuthorizationCodeRepository) Create(ctx context.Context, code *models.AuthorizationCode) error {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuthorizationCode) error); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByHash provides a mock function with given fields: ctx, codeHash
+func (_m *AuthorizationCodeRepository) GetByHash(ctx context.Context, codeHash string) (models.AuthorizationCode, error) {
+	ret := _m.Called(ctx, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 models.AuthorizationCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.AuthorizationCode, error)); ok {
+		return rf(ctx, codeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.AuthorizationCode); ok {
+		r0 = rf(ctx, codeHash)
+	} else {
+		r0 = ret.Get(0).(models.AuthorizationCode)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkUsed provides a mock function with given fields: ctx, id
+func (_m *AuthorizationCodeRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkUsed")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAuthorizationCodeRepository creates a new instance of AuthorizationCodeRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuthorizationCodeRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuthorizationCodeRepository {
+	mock := &AuthorizationCodeRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
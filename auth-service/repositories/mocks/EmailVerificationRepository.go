@@ -0,0 +1,121 @@
+// Code generated by mockery v2.46.3. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "auth-service/models"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EmailVerificationRepository is an autogenerated mock type for the EmailVerificationRepository type
+type EmailVerificationRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, token
+func (_m *EmailVerificationRepository) Create(ctx context.Context, token *models.EmailVerificationToken) error {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.EmailVerificationToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByHash provides a mock function with given fields: ctx, tokenHash
+func (_m *EmailVerificationRepository) GetByHash(ctx context.Context, tokenHash string) (models.EmailVerificationToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 models.EmailVerificationToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.EmailVerificationToken, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.EmailVerificationToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(models.EmailVerificationToken)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvalidateForUser provides a mock function with given fields: ctx, userID
+func (_m *EmailVerificationRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvalidateForUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkUsed provides a mock function with given fields: ctx, id
+func (_m *EmailVerificationRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkUsed")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEmailVerificationRepository creates a new instance of EmailVerificationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEmailVerificationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EmailVerificationRepository {
+	mock := &EmailVerificationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"auth-service/constants"
+	"auth-service/models"
+)
+
+// OutboxRepository is a repository for the events other services consume. Events are written by
+// the repositories whose changes they announce, see insertOutboxEvent.
+type OutboxRepository interface {
+	List(ctx context.Context, afterID int64, limit int) ([]models.OutboxEvent, error)
+}
+
+// outboxRepository is a concrete implementation of OutboxRepository
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository returns a new instance of outboxRepository
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// List returns up to limit events with an id greater than afterID, oldest first. Consumers can
+// keep the id of the last event they handled as their cursor, since insertOutboxEvent makes
+// events commit in id order.
+func (r outboxRepository) List(ctx context.Context, afterID int64, limit int) ([]models.OutboxEvent, error) {
+	query := `SELECT id, event_type, payload, created_at FROM outbox_events WHERE id > $1 ORDER BY id LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		log.Printf("Error listing outbox events: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.OutboxEvent{}
+	for rows.Next() {
+		event := models.OutboxEvent{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.Type, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// insertOutboxEvent writes an event within tx, so that it is only published if the change it
+// announces is committed. Writers are serialized by a transaction-level advisory lock taken before
+// the id is drawn: otherwise concurrent transactions could commit a higher id first, consumers
+// would move their cursor past it, and the lower one would never be read.
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, eventType string, payload any) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, constants.OutboxAdvisoryLockKey); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO outbox_events (event_type, payload) VALUES ($1, $2)`, eventType, encoded)
+	return err
+}
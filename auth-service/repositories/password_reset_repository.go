@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"auth-service/models"
+)
+
+// PasswordResetRepository is a repository for password reset tokens
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	GetByHash(ctx context.Context, tokenHash string) (models.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+}
+
+// passwordResetRepository is a concrete implementation of PasswordResetRepository
+type passwordResetRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetRepository returns a new instance of passwordResetRepository
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create inserts a new password reset token into the database
+func (r passwordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetByHash retrieves a password reset token by its hash. Returns a zero value token if none matches.
+func (r passwordResetRepository) GetByHash(ctx context.Context, tokenHash string) (models.PasswordResetToken, error) {
+	token := models.PasswordResetToken{}
+	var usedAt sql.NullTime
+	queryStr := `SELECT id, user_id, token_hash, expires_at, used_at, created_at FROM password_reset_tokens WHERE token_hash = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving password reset token: %v", err)
+		return models.PasswordResetToken{}, err
+	}
+
+	token.UsedAt = nullTimePtr(usedAt)
+	return token, nil
+}
+
+// MarkUsed flags a password reset token as used. It returns false when it was already used.
+func (r passwordResetRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// InvalidateForUser marks every unused password reset token of a user as used
+func (r passwordResetRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
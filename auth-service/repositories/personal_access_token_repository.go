@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"auth-service/models"
+)
+
+// PersonalAccessTokenRepository is a repository for the personal access tokens of users
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	GetByHash(ctx context.Context, tokenHash string) (models.PersonalAccessToken, error)
+	ListActiveForUser(ctx context.Context, userID int64, now time.Time) ([]models.PersonalAccessToken, error)
+	CountActiveForUser(ctx context.Context, userID int64, now time.Time) (int, error)
+	Revoke(ctx context.Context, id, userID int64) (bool, error)
+	TouchLastUsed(ctx context.Context, id int64) error
+}
+
+// personalAccessTokenColumns are the columns every token query selects, in the order
+// scanPersonalAccessToken reads them
+const personalAccessTokenColumns = `id, user_id, name, token_hash, token_prefix, scope, expires_at, last_used_at, revoked_at, created_at`
+
+// personalAccessTokenRepository is a concrete implementation of PersonalAccessTokenRepository
+type personalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPersonalAccessTokenRepository returns a new instance of personalAccessTokenRepository
+func NewPersonalAccessTokenRepository(db *sql.DB) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db: db}
+}
+
+// Create inserts a new personal access token into the database
+func (r personalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	query := `INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scope, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, token.UserID, token.Name, token.TokenHash, token.Prefix, token.Scope, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetByHash retrieves a token by the hash of its value. Returns a zero value token if none matches.
+func (r personalAccessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (models.PersonalAccessToken, error) {
+	queryStr := `SELECT ` + personalAccessTokenColumns + ` FROM personal_access_tokens WHERE token_hash = $1`
+
+	token, err := scanPersonalAccessToken(r.db.QueryRowContext(ctx, queryStr, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.PersonalAccessToken{}, nil
+	}
+	if err != nil {
+		log.Printf("Error retrieving personal access token: %v", err)
+		return models.PersonalAccessToken{}, err
+	}
+	return token, nil
+}
+
+// ListActiveForUser returns the tokens of a user that are neither revoked nor expired at now,
+// newest first
+func (r personalAccessTokenRepository) ListActiveForUser(ctx context.Context, userID int64, now time.Time) ([]models.PersonalAccessToken, error) {
+	queryStr := `SELECT ` + personalAccessTokenColumns + ` FROM personal_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2) ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, queryStr, userID, now)
+	if err != nil {
+		log.Printf("Error listing personal access tokens of user %d: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// CountActiveForUser returns how many tokens of a user are neither revoked nor expired at now
+func (r personalAccessTokenRepository) CountActiveForUser(ctx context.Context, userID int64, now time.Time) (int, error) {
+	queryStr := `SELECT COUNT(*) FROM personal_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, queryStr, userID, now).Scan(&count)
+	return count, err
+}
+
+// Revoke marks a token of the user revoked. Returns false if the user has no such unrevoked token.
+func (r personalAccessTokenRepository) Revoke(ctx context.Context, id, userID int64) (bool, error) {
+	query := `UPDATE personal_access_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// TouchLastUsed records that the token was just used
+func (r personalAccessTokenRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `UPDATE personal_access_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// scanPersonalAccessToken reads a row of personalAccessTokenColumns
+func scanPersonalAccessToken(row rowScanner) (models.PersonalAccessToken, error) {
+	token := models.PersonalAccessToken{}
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.Prefix,
+		&token.Scope,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return models.PersonalAccessToken{}, err
+	}
+
+	token.ExpiresAt = nullTimePtr(expiresAt)
+	token.LastUsedAt = nullTimePtr(lastUsedAt)
+	token.RevokedAt = nullTimePtr(revokedAt)
+	return token, nil
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"auth-service/models"
+)
+
+// RefreshTokenRepository is a repository for refresh tokens
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+}
+
+// refreshTokenRepository is a concrete implementation of RefreshTokenRepository
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository returns a new instance of refreshTokenRepository
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create inserts a new refresh token into the database
+func (r refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, token_hash, family_id, client_id, scope, expires_at) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, token.UserID, token.TokenHash, token.FamilyID, token.ClientID, token.Scope, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetByHash retrieves a refresh token by its hash. Returns a zero value token if none matches.
+func (r refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	token := models.RefreshToken{}
+	var usedAt, revokedAt sql.NullTime
+	var clientID sql.NullString
+	queryStr := `SELECT id, user_id, token_hash, family_id, client_id, scope, expires_at, used_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = $1`
+
+	err := r.db.QueryRowContext(ctx, queryStr, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.FamilyID,
+		&clientID,
+		&token.Scope,
+		&token.ExpiresAt,
+		&usedAt,
+		&revokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Error retrieving refresh token: %v", err)
+		return models.RefreshToken{}, err
+	}
+
+	token.ClientID = clientID.String
+	token.UsedAt = nullTimePtr(usedAt)
+	token.RevokedAt = nullTimePtr(revokedAt)
+	return token, nil
+}
+
+// MarkUsed flags a refresh token as consumed by a rotation. It returns false when the token
+// was already used or revoked, which lets concurrent rotations of the same token be detected.
+func (r refreshTokenRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// RevokeFamily revokes every refresh token rotated from the same login
+func (r refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE family_id = $1 AND revoked_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	return err
+}
+
+// RevokeAllForUser revokes every refresh token that belongs to a user
+func (r refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// nullTimePtr converts a nullable timestamp column to a pointer
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
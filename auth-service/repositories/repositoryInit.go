@@ -8,11 +8,43 @@ import (
 // Repository is an interface for all repositories
 type Repository interface {
 	UserRepository() UserRepository
+	RefreshTokenRepository() RefreshTokenRepository
+	RevokedTokenRepository() RevokedTokenRepository
+	ClientRepository() ClientRepository
+	AuthorizationCodeRepository() AuthorizationCodeRepository
+	RoleRepository() RoleRepository
+	PasswordResetRepository() PasswordResetRepository
+	EmailVerificationRepository() EmailVerificationRepository
+	LoginAttemptRepository() LoginAttemptRepository
+	MFARepository() MFARepository
+	EmailChangeRepository() EmailChangeRepository
+	AuditRepository() AuditRepository
+	SessionRepository() SessionRepository
+	PersonalAccessTokenRepository() PersonalAccessTokenRepository
+	MagicLinkRepository() MagicLinkRepository
+	InviteRepository() InviteRepository
+	OutboxRepository() OutboxRepository
 }
 
 // repo  is a concrete  implementation of Repository
 type repo struct {
-	userRepository UserRepository
+	userRepository         UserRepository
+	refreshTokenRepository RefreshTokenRepository
+	revokedTokenRepository RevokedTokenRepository
+	clientRepository       ClientRepository
+	authCodeRepository     AuthorizationCodeRepository
+	roleRepository         RoleRepository
+	passwordResetRepo      PasswordResetRepository
+	emailVerificationRepo  EmailVerificationRepository
+	loginAttemptRepo       LoginAttemptRepository
+	mfaRepo                MFARepository
+	emailChangeRepo        EmailChangeRepository
+	auditRepo              AuditRepository
+	sessionRepo            SessionRepository
+	patRepo                PersonalAccessTokenRepository
+	magicLinkRepo          MagicLinkRepository
+	inviteRepo             InviteRepository
+	outboxRepo             OutboxRepository
 }
 
 // UserRepository implements Repository.
@@ -20,12 +52,108 @@ func (r *repo) UserRepository() UserRepository {
 	return r.userRepository
 }
 
+// RefreshTokenRepository implements Repository.
+func (r *repo) RefreshTokenRepository() RefreshTokenRepository {
+	return r.refreshTokenRepository
+}
+
+// RevokedTokenRepository implements Repository.
+func (r *repo) RevokedTokenRepository() RevokedTokenRepository {
+	return r.revokedTokenRepository
+}
+
+// ClientRepository implements Repository.
+func (r *repo) ClientRepository() ClientRepository {
+	return r.clientRepository
+}
+
+// AuthorizationCodeRepository implements Repository.
+func (r *repo) AuthorizationCodeRepository() AuthorizationCodeRepository {
+	return r.authCodeRepository
+}
+
+// RoleRepository implements Repository.
+func (r *repo) RoleRepository() RoleRepository {
+	return r.roleRepository
+}
+
+// PasswordResetRepository implements Repository.
+func (r *repo) PasswordResetRepository() PasswordResetRepository {
+	return r.passwordResetRepo
+}
+
+// EmailVerificationRepository implements Repository.
+func (r *repo) EmailVerificationRepository() EmailVerificationRepository {
+	return r.emailVerificationRepo
+}
+
+// LoginAttemptRepository implements Repository.
+func (r *repo) LoginAttemptRepository() LoginAttemptRepository {
+	return r.loginAttemptRepo
+}
+
+// MFARepository implements Repository.
+func (r *repo) MFARepository() MFARepository {
+	return r.mfaRepo
+}
+
+// EmailChangeRepository implements Repository.
+func (r *repo) EmailChangeRepository() EmailChangeRepository {
+	return r.emailChangeRepo
+}
+
+// AuditRepository implements Repository.
+func (r *repo) AuditRepository() AuditRepository {
+	return r.auditRepo
+}
+
+// SessionRepository implements Repository.
+func (r *repo) SessionRepository() SessionRepository {
+	return r.sessionRepo
+}
+
+// PersonalAccessTokenRepository implements Repository.
+func (r *repo) PersonalAccessTokenRepository() PersonalAccessTokenRepository {
+	return r.patRepo
+}
+
+// MagicLinkRepository implements Repository.
+func (r *repo) MagicLinkRepository() MagicLinkRepository {
+	return r.magicLinkRepo
+}
+
+// InviteRepository implements Repository.
+func (r *repo) InviteRepository() InviteRepository {
+	return r.inviteRepo
+}
+
+// OutboxRepository implements Repository.
+func (r *repo) OutboxRepository() OutboxRepository {
+	return r.outboxRepo
+}
+
 // NewRepository returns a new instance of Repository.
 func NewRepository(db *sql.DB) (Repository, error) {
 	if db == nil {
 		return nil, fmt.Errorf("db connection cannot be nil")
 	}
 	return &repo{
-		userRepository: NewUserRepository(db),
+		userRepository:         NewUserRepository(db),
+		refreshTokenRepository: NewRefreshTokenRepository(db),
+		revokedTokenRepository: NewRevokedTokenRepository(db),
+		clientRepository:       NewClientRepository(db),
+		authCodeRepository:     NewAuthorizationCodeRepository(db),
+		roleRepository:         NewRoleRepository(db),
+		passwordResetRepo:      NewPasswordResetRepository(db),
+		emailVerificationRepo:  NewEmailVerificationRepository(db),
+		loginAttemptRepo:       NewLoginAttemptRepository(db),
+		mfaRepo:                NewMFARepository(db),
+		emailChangeRepo:        NewEmailChangeRepository(db),
+		auditRepo:              NewAuditRepository(db),
+		sessionRepo:            NewSessionRepository(db),
+		patRepo:                NewPersonalAccessTokenRepository(db),
+		magicLinkRepo:          NewMagicLinkRepository(db),
+		inviteRepo:             NewInviteRepository(db),
+		outboxRepo:             NewOutboxRepository(db),
 	}, nil
 }
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"auth-service/models"
+)
+
+// RevokedTokenRepository is a repository for revoked access tokens
+type RevokedTokenRepository interface {
+	Create(ctx context.Context, token models.RevokedToken) error
+	ListActive(ctx context.Context, now time.Time) ([]models.RevokedToken, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+
+	SetUserCutoff(ctx context.Context, cutoff models.UserTokenCutoff) error
+	ListUserCutoffs(ctx context.Context, since time.Time) ([]models.UserTokenCutoff, error)
+	DeleteUserCutoffs(ctx context.Context, before time.Time) (int64, error)
+}
+
+// revokedTokenRepository is a concrete implementation of RevokedTokenRepository
+type revokedTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRevokedTokenRepository returns a new instance of revokedTokenRepository
+func NewRevokedTokenRepository(db *sql.DB) RevokedTokenRepository {
+	return &revokedTokenRepository{db: db}
+}
+
+// Create stores a revoked token; revoking the same jti twice is a no-op
+func (r revokedTokenRepository) Create(ctx context.Context, token models.RevokedToken) error {
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, token.JTI, token.UserID, token.ExpiresAt)
+	return err
+}
+
+// ListActive returns revoked tokens that have not expired yet
+func (r revokedTokenRepository) ListActive(ctx context.Context, now time.Time) ([]models.RevokedToken, error) {
+	query := `SELECT jti, user_id, expires_at, revoked_at FROM revoked_tokens WHERE expires_at > $1`
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.RevokedToken
+	for rows.Next() {
+		var token models.RevokedToken
+		if err := rows.Scan(&token.JTI, &token.UserID, &token.ExpiresAt, &token.RevokedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteExpired removes revoked tokens that expired anyway and returns how many were removed
+func (r revokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SetUserCutoff creates or moves forward the cutoff for a user
+func (r revokedTokenRepository) SetUserCutoff(ctx context.Context, cutoff models.UserTokenCutoff) error {
+	query := `INSERT INTO user_token_cutoffs (user_id, revoked_before) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before = GREATEST(user_token_cutoffs.revoked_before, EXCLUDED.revoked_before)`
+
+	_, err := r.db.ExecContext(ctx, query, cutoff.UserID, cutoff.RevokedBefore)
+	return err
+}
+
+// ListUserCutoffs returns cutoffs set after since; older cutoffs cannot match an unexpired token
+func (r revokedTokenRepository) ListUserCutoffs(ctx context.Context, since time.Time) ([]models.UserTokenCutoff, error) {
+	query := `SELECT user_id, revoked_before FROM user_token_cutoffs WHERE revoked_before > $1`
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cutoffs []models.UserTokenCutoff
+	for rows.Next() {
+		var cutoff models.UserTokenCutoff
+		if err := rows.Scan(&cutoff.UserID, &cutoff.RevokedBefore); err != nil {
+			return nil, err
+		}
+		cutoffs = append(cutoffs, cutoff)
+	}
+	return cutoffs, rows.Err()
+}
+
+// DeleteUserCutoffs removes cutoffs older than before and returns how many were removed
+func (r revokedTokenRepository) DeleteUserCutoffs(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_token_cutoffs WHERE revoked_before <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
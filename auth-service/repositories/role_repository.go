@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"auth-service/models"
+
+	"github.com/lib/pq"
+)
+
+// RoleRepository is a repository for roles, their permissions and the roles granted to users
+type RoleRepository interface {
+	List(ctx context.Context) ([]models.Role, error)
+	GetByName(ctx context.Context, name string) (models.Role, error)
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+	GetUserPermissions(ctx context.Context, userID int64) ([]string, error)
+	Assign(ctx context.Context, userID, roleID, grantedBy int64) (bool, error)
+	Remove(ctx context.Context, userID, roleID int64) (bool, error)
+}
+
+// roleRepository is a concrete implementation of RoleRepository
+type roleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository returns a new instance of roleRepository
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// List returns every role with its permissions, ordered by name
+func (r roleRepository) List(ctx context.Context) ([]models.Role, error) {
+	queryStr := `SELECT r.id, r.name, r.description, COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id ORDER BY r.name`
+
+	rows, err := r.db.QueryContext(ctx, queryStr)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		role := models.Role{}
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// GetByName returns the role with the given name, or a zero Role if there is none
+func (r roleRepository) GetByName(ctx context.Context, name string) (models.Role, error) {
+	queryStr := `SELECT id, name, description FROM roles WHERE name = $1`
+
+	role := models.Role{}
+	err := r.db.QueryRowContext(ctx, queryStr, name).Scan(&role.ID, &role.Name, &role.Description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Role{}, nil
+		}
+		return models.Role{}, err
+	}
+	return role, nil
+}
+
+// GetUserRoles returns the names of the roles granted to a user
+func (r roleRepository) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	queryStr := `SELECT r.name FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = $1 ORDER BY r.name`
+	return r.queryNames(ctx, queryStr, userID)
+}
+
+// GetUserPermissions returns the distinct permissions of every role granted to a user
+func (r roleRepository) GetUserPermissions(ctx context.Context, userID int64) ([]string, error) {
+	queryStr := `SELECT DISTINCT p.name FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1 ORDER BY p.name`
+	return r.queryNames(ctx, queryStr, userID)
+}
+
+// Assign grants a role to a user. It returns false when the user already had it.
+func (r roleRepository) Assign(ctx context.Context, userID, roleID, grantedBy int64) (bool, error) {
+	query := `INSERT INTO user_roles (user_id, role_id, granted_by) VALUES ($1, $2, NULLIF($3, 0)) ON CONFLICT DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, userID, roleID, grantedBy)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// Remove takes a role away from a user. It returns false when the user did not have it.
+func (r roleRepository) Remove(ctx context.Context, userID, roleID int64) (bool, error) {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, roleID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// queryNames runs a query selecting a single text column for a user
+func (r roleRepository) queryNames(ctx context.Context, queryStr string, userID int64) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, queryStr, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"auth-service/models"
+)
+
+// SessionRepository is a repository for the login sessions of users
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id int64) (models.Session, error)
+	Touch(ctx context.Context, familyID, ip, userAgent string) error
+	ListActiveForUser(ctx context.Context, userID int64, seenSince time.Time) ([]models.Session, error)
+	RevokeByFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+	ListRevokedFamilies(ctx context.Context, since time.Time) ([]string, error)
+	DeleteEnded(ctx context.Context, revokedBefore, seenBefore time.Time) (int64, error)
+}
+
+// sessionColumns are the columns every session query selects, in the order scanSession reads them
+const sessionColumns = `id, user_id, family_id, COALESCE(client_id, ''), COALESCE(user_agent, ''), COALESCE(ip, ''), ` +
+	`created_at, last_seen_at, revoked_at`
+
+// sessionRepository is a concrete implementation of SessionRepository
+type sessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository returns a new instance of sessionRepository
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Create inserts a new session into the database
+func (r sessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := `INSERT INTO sessions (user_id, family_id, client_id, user_agent, ip)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, '')) RETURNING id, created_at, last_seen_at`
+
+	return r.db.QueryRowContext(ctx, query, session.UserID, session.FamilyID, session.ClientID, session.UserAgent, session.IP).
+		Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+}
+
+// GetByID retrieves a session by id. Returns a zero value session if none matches.
+func (r sessionRepository) GetByID(ctx context.Context, id int64) (models.Session, error) {
+	queryStr := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, queryStr, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Session{}, nil
+	}
+	if err != nil {
+		log.Printf("Error retrieving session %d: %v", id, err)
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+// Touch records that the session was used again, from the given IP and user agent
+func (r sessionRepository) Touch(ctx context.Context, familyID, ip, userAgent string) error {
+	query := `UPDATE sessions SET last_seen_at = CURRENT_TIMESTAMP, ip = COALESCE(NULLIF($2, ''), ip),
+		user_agent = COALESCE(NULLIF($3, ''), user_agent) WHERE family_id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, familyID, ip, userAgent)
+	return err
+}
+
+// ListActiveForUser returns the sessions of a user that are not revoked and were used since
+// seenSince, most recently used first
+func (r sessionRepository) ListActiveForUser(ctx context.Context, userID int64, seenSince time.Time) ([]models.Session, error) {
+	queryStr := `SELECT ` + sessionColumns + ` FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND last_seen_at > $2 ORDER BY last_seen_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, queryStr, userID, seenSince)
+	if err != nil {
+		log.Printf("Error listing sessions of user %d: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeByFamily marks the session of a refresh token family revoked
+func (r sessionRepository) RevokeByFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE family_id = $1 AND revoked_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	return err
+}
+
+// RevokeAllForUser marks every session of a user revoked
+func (r sessionRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// ListRevokedFamilies returns the family ids of sessions revoked since the given time
+func (r sessionRepository) ListRevokedFamilies(ctx context.Context, since time.Time) ([]string, error) {
+	queryStr := `SELECT family_id FROM sessions WHERE revoked_at > $1`
+
+	rows, err := r.db.QueryContext(ctx, queryStr, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	families := []string{}
+	for rows.Next() {
+		var familyID string
+		if err := rows.Scan(&familyID); err != nil {
+			return nil, err
+		}
+		families = append(families, familyID)
+	}
+	return families, rows.Err()
+}
+
+// DeleteEnded deletes sessions revoked before revokedBefore or last used before seenBefore.
+// Returns the number of deleted rows.
+func (r sessionRepository) DeleteEnded(ctx context.Context, revokedBefore, seenBefore time.Time) (int64, error) {
+	query := `DELETE FROM sessions WHERE revoked_at < $1 OR last_seen_at < $2`
+
+	result, err := r.db.ExecContext(ctx, query, revokedBefore, seenBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanSession reads a row of sessionColumns
+func scanSession(row rowScanner) (models.Session, error) {
+	session := models.Session{}
+	var revokedAt sql.NullTime
+
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.FamilyID,
+		&session.ClientID,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	session.RevokedAt = nullTimePtr(revokedAt)
+	return session, nil
+}
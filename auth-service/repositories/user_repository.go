@@ -4,17 +4,57 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
+	"auth-service/constants"
 	"auth-service/models"
+
+	"github.com/lib/pq"
 )
 
 // UserRepository is a repository for user data
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByUserEmail(ctx context.Context, email string) (models.User, error)
+	GetByID(ctx context.Context, id int64) (models.User, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]models.User, error)
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+	UpdateProfile(ctx context.Context, user models.User) error
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	List(ctx context.Context, query string, limit, offset int) ([]models.User, int64, error)
+	SetDisabled(ctx context.Context, id int64, disabled bool) error
+	SetPasswordResetRequired(ctx context.Context, id int64) error
+	ScheduleDeletion(ctx context.Context, id int64, at time.Time) (bool, error)
+	CancelDeletion(ctx context.Context, id int64) (bool, error)
+	ListDueForDeletion(ctx context.Context, now time.Time, limit int) ([]models.User, error)
+	Delete(ctx context.Context, id int64) (bool, error)
+	DeleteIfDue(ctx context.Context, id int64, now time.Time) (bool, error)
 }
 
+// userColumns are the columns every user query selects, in the order scanUser reads them.
+// Optional profile fields are NULL until they are set.
+const userColumns = `id, email, password, first_name, last_name, email_verified_at, ` +
+	`COALESCE(display_name, ''), COALESCE(username, ''), COALESCE(bio, ''), COALESCE(avatar_url, ''), ` +
+	`disabled_at, password_reset_required, deletion_scheduled_at, created_at`
+
+// ErrUsernameTaken is returned by UpdateProfile when another user took the username first
+var ErrUsernameTaken = errors.New(constants.ErrUsernameTaken)
+
+// uniqueViolation is the Postgres error code of unique index violations, and usernameIndex
+// the index keeping usernames unique
+const (
+	uniqueViolation = "23505"
+	usernameIndex   = "idx_users_username"
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns, so search terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // userRepository is a concrete implementation of UserRepository
 type userRepository struct {
 	db *sql.DB
@@ -40,20 +80,289 @@ func (r userRepository) Create(ctx context.Context, user *models.User) error {
 // GetByUserEmail retrieves a user by email from the database. Returns a models.User and an error if any occurs.
 func (r userRepository) GetByUserEmail(ctx context.Context, email string) (models.User, error) {
 	log.Println("getting user by email ", email)
+	queryStr := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, queryStr, email))
+	if err != nil {
+		log.Printf("Error retrieving user by email %s: %v", email, err)
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// GetByID retrieves a user by id from the database. Returns a zero value models.User if none matches.
+func (r userRepository) GetByID(ctx context.Context, id int64) (models.User, error) {
+	queryStr := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, queryStr, id))
+	if err != nil {
+		log.Printf("Error retrieving user by id %d: %v", id, err)
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// GetByIDs retrieves the users with the given ids in no particular order. Unknown ids are skipped.
+func (r userRepository) GetByIDs(ctx context.Context, ids []int64) ([]models.User, error) {
+	queryStr := `SELECT ` + userColumns + ` FROM users WHERE id = ANY($1)`
+
+	rows, err := r.db.QueryContext(ctx, queryStr, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error retrieving users by ids: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// GetByUsername retrieves a user by username. Returns a zero value models.User if none matches.
+func (r userRepository) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	queryStr := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, queryStr, username))
+	if err != nil {
+		log.Printf("Error retrieving user by username %s: %v", username, err)
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// UpdatePassword replaces the password hash of a user, which fulfils a required password reset
+func (r userRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	query := `UPDATE users SET password = $1, password_reset_required = FALSE WHERE id = $2`
+
+	_, err := r.db.ExecContext(ctx, query, passwordHash, id)
+	return err
+}
+
+// MarkEmailVerified records that a user proved they own their email address. Users verified
+// before keep their original verification time.
+func (r userRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	query := `UPDATE users SET email_verified_at = CURRENT_TIMESTAMP WHERE id = $1 AND email_verified_at IS NULL`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// UpdateProfile stores the name and profile fields of a user. Empty optional fields are stored as
+// NULL, which keeps empty usernames out of the unique index.
+func (r userRepository) UpdateProfile(ctx context.Context, user models.User) error {
+	query := `UPDATE users SET first_name = $1, last_name = $2, display_name = NULLIF($3, ''), username = NULLIF($4, ''),
+		bio = NULLIF($5, ''), avatar_url = NULLIF($6, '') WHERE id = $7`
+
+	_, err := r.db.ExecContext(ctx, query, user.FirstName, user.LastName, user.DisplayName, user.Username, user.Bio, user.AvatarURL, user.ID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == usernameIndex {
+		return ErrUsernameTaken
+	}
+	return err
+}
+
+// UpdateEmail changes the email of a user. The new address was proven by a confirmation link, so
+// it is marked verified.
+func (r userRepository) UpdateEmail(ctx context.Context, id int64, email string) error {
+	query := `UPDATE users SET email = $1, email_verified_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	_, err := r.db.ExecContext(ctx, query, email, id)
+	return err
+}
+
+// List returns a page of users ordered by id, together with the number of users matching the
+// query. An empty query matches every user; otherwise emails, usernames and display names
+// containing it are matched case-insensitively.
+func (r userRepository) List(ctx context.Context, query string, limit, offset int) ([]models.User, int64, error) {
+	where := ``
+	args := []any{}
+	if query != "" {
+		where = ` WHERE email ILIKE $1 OR username ILIKE $1 OR display_name ILIKE $1`
+		args = append(args, "%"+likeEscaper.Replace(query)+"%")
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		log.Printf("Error counting users: %v", err)
+		return nil, 0, err
+	}
+
+	queryStr := fmt.Sprintf(`SELECT %s FROM users%s ORDER BY id LIMIT $%d OFFSET $%d`, userColumns, where, len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, queryStr, append(args, limit, offset)...)
+	if err != nil {
+		log.Printf("Error listing users: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+// SetDisabled disables or re-enables a user. Disabling an already disabled user keeps the time
+// they were first disabled.
+func (r userRepository) SetDisabled(ctx context.Context, id int64, disabled bool) error {
+	query := `UPDATE users SET disabled_at = NULL WHERE id = $1`
+	if disabled {
+		query = `UPDATE users SET disabled_at = COALESCE(disabled_at, CURRENT_TIMESTAMP) WHERE id = $1`
+	}
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// SetPasswordResetRequired makes the user set a new password before they can log in again
+func (r userRepository) SetPasswordResetRequired(ctx context.Context, id int64) error {
+	query := `UPDATE users SET password_reset_required = TRUE WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// ScheduleDeletion sets the time the account of a user is deleted at. It returns false when the
+// deletion was already scheduled, so that the first time set is kept.
+func (r userRepository) ScheduleDeletion(ctx context.Context, id int64, at time.Time) (bool, error) {
+	query := `UPDATE users SET deletion_scheduled_at = $1 WHERE id = $2 AND deletion_scheduled_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// CancelDeletion keeps the account of a user. It returns false when no deletion was scheduled.
+func (r userRepository) CancelDeletion(ctx context.Context, id int64) (bool, error) {
+	query := `UPDATE users SET deletion_scheduled_at = NULL WHERE id = $1 AND deletion_scheduled_at IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// ListDueForDeletion returns up to limit users whose scheduled deletion time has passed, the
+// longest overdue first
+func (r userRepository) ListDueForDeletion(ctx context.Context, now time.Time, limit int) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE deletion_scheduled_at <= $1 ORDER BY deletion_scheduled_at LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		log.Printf("Error listing users due for deletion: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// Delete removes a user; their tokens, roles and other rows go with them. Other services learn
+// about it from a user.deleted outbox event written in the same transaction. Reports whether the
+// user existed.
+func (r userRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	return r.delete(ctx, id, `DELETE FROM users WHERE id = $1`, id)
+}
+
+// DeleteIfDue is Delete for the scheduled deletion of a user: the user is only deleted if their
+// deletion is still scheduled at or before now, so that cancelling it races safely with the purge.
+// Reports whether the user was deleted.
+func (r userRepository) DeleteIfDue(ctx context.Context, id int64, now time.Time) (bool, error) {
+	query := `DELETE FROM users WHERE id = $1 AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $2`
+	return r.delete(ctx, id, query, id, now)
+}
+
+// delete runs a query deleting the user id and writes the user.deleted outbox event if it did
+func (r userRepository) delete(ctx context.Context, id int64, query string, args ...any) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if err := insertOutboxEvent(ctx, tx, constants.OutboxUserDeleted, models.UserDeletedPayload{UserID: id}); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser reads a row of userColumns. A missing row yields a zero value models.User.
+func scanUser(row rowScanner) (models.User, error) {
 	user := models.User{}
-	queryStr := `SELECT id, email, password, first_name, last_name FROM users WHERE email = $1`
+	var emailVerifiedAt, disabledAt, deletionScheduledAt, createdAt sql.NullTime
 
-	err := r.db.QueryRowContext(ctx, queryStr, email).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Email,
 		&user.Password,
 		&user.FirstName,
 		&user.LastName,
+		&emailVerifiedAt,
+		&user.DisplayName,
+		&user.Username,
+		&user.Bio,
+		&user.AvatarURL,
+		&disabledAt,
+		&user.PasswordResetRequired,
+		&deletionScheduledAt,
+		&createdAt,
 	)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		log.Printf("Error retrieving user by email %s: %v", email, err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, nil
+	}
+	if err != nil {
 		return models.User{}, err
 	}
 
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
+	user.DisabledAt = nullTimePtr(disabledAt)
+	user.DeletionScheduledAt = nullTimePtr(deletionScheduledAt)
+	user.CreatedAt = createdAt.Time
 	return user, nil
 }
@@ -169,12 +169,21 @@ func Test_userRepository_GetByUserEmail(t *testing.T) {
 						"password",
 						"first_name",
 						"last_name",
+						"email_verified_at",
+						"display_name",
+						"username",
+						"bio",
+						"avatar_url",
+						"disabled_at",
+						"password_reset_required",
+						"deletion_scheduled_at",
+						"created_at",
 					},
 				).AddRow(
-					1, "", "", "", "",
+					1, "", "", "", "", nil, "", "", "", "", nil, false, nil, nil,
 				)
 
-				sqlStr := `^SELECT id, email, password, first_name, last_name FROM users WHERE email = \$1$`
+				sqlStr := `^SELECT id, email, password, first_name, last_name, email_verified_at, COALESCE\(display_name, ''\), COALESCE\(username, ''\), COALESCE\(bio, ''\), COALESCE\(avatar_url, ''\), disabled_at, password_reset_required, deletion_scheduled_at, created_at FROM users WHERE email = \$1$`
 				sqlMockObj.ExpectQuery(sqlStr).
 					WithArgs("test@example.com").
 					WillReturnRows(userRows)
@@ -191,7 +200,7 @@ func Test_userRepository_GetByUserEmail(t *testing.T) {
 			mockFn: func(sqlMockObj sqlmock.Sqlmock) {
 				// Use the exact query string instead of regex
 				// sqlMockObj.ExpectQuery("SELECT id, email, first_name, last_name FROM users WHERE email = \\$1").
-				sqlStr := `^SELECT id, email, password, first_name, last_name FROM users WHERE email = \$1$`
+				sqlStr := `^SELECT id, email, password, first_name, last_name, email_verified_at, COALESCE\(display_name, ''\), COALESCE\(username, ''\), COALESCE\(bio, ''\), COALESCE\(avatar_url, ''\), disabled_at, password_reset_required, deletion_scheduled_at, created_at FROM users WHERE email = \$1$`
 
 				sqlMockObj.ExpectQuery(sqlStr).
 					WithArgs("notfound@example.com").
@@ -4,20 +4,174 @@ import (
 	"fmt"
 	"net/http"
 
+	"auth-service/constants"
 	"auth-service/controllers"
+	"auth-service/middleware"
 )
 
-// InitUserRouter  initializes the user router
-func InitUserRouter(ctrl controllers.Controller) *http.ServeMux {
+// InitUserRouter  initializes the user router.
+// Routes wrapped with authenticate require a valid user access token; introspection requires
+// a confidential client authenticated by authenticateIntrospector, and reading outbox events one
+// authenticated by authenticateEventConsumer. Routes wrapped with account also refuse the user
+// tokens of OAuth clients, which may only call userinfo and log out of their session. Admin routes
+// additionally require a permission, checked by the middleware requirePermission returns for it.
+// Routes that manage credentials or take the account or its data away refuse the tokens of
+// admins impersonating the user.
+func InitUserRouter(
+	ctrl controllers.Controller,
+	authenticate, authenticateIntrospector, authenticateEventConsumer middleware.Middleware,
+	requirePermission func(permission string) middleware.Middleware,
+) *http.ServeMux {
 	userCtrl := ctrl.AuthController()
+	wellKnownCtrl := ctrl.WellKnownController()
+	oauthCtrl := ctrl.OAuthController()
+	roleCtrl := ctrl.RoleController()
+	passwordCtrl := ctrl.PasswordController()
+	verifyCtrl := ctrl.EmailVerificationController()
+	userAdminCtrl := ctrl.UserAdminController()
+	mfaCtrl := ctrl.MFAController()
+	profileCtrl := ctrl.ProfileController()
+	sessionCtrl := ctrl.SessionController()
+	patCtrl := ctrl.PersonalAccessTokenController()
+	magicCtrl := ctrl.MagicLinkController()
+	inviteCtrl := ctrl.InviteController()
+	exportCtrl := ctrl.DataExportController()
+	eventCtrl := ctrl.EventController()
+	account := func(h http.HandlerFunc) http.Handler {
+		return authenticate(middleware.RejectClientTokens(h))
+	}
+	admin := func(permission string, h http.HandlerFunc) http.Handler {
+		return authenticate(middleware.RejectClientTokens(requirePermission(permission)(h)))
+	}
+	ownerOnly := func(h http.HandlerFunc) http.Handler {
+		return authenticate(middleware.RejectClientTokens(middleware.RejectImpersonation(h)))
+	}
 
 	loginPath := fmt.Sprintf("%s /login", http.MethodPost)
+	loginMFAPath := fmt.Sprintf("%s /login/mfa", http.MethodPost)
+	magicLinkPath := fmt.Sprintf("%s %s", http.MethodPost, constants.PathMagicLink)
+	magicLinkCallbackPath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathMagicLinkCallback)
 	registerPath := fmt.Sprintf("%s /register", http.MethodPost)
+	refreshPath := fmt.Sprintf("%s /refresh", http.MethodPost)
+	verifyPath := fmt.Sprintf("%s /verify", http.MethodGet)
+	introspectPath := fmt.Sprintf("%s %s", http.MethodPost, constants.PathIntrospect)
+	eventsPath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathEvents)
+	userInfoGetPath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathUserInfo)
+	userInfoPostPath := fmt.Sprintf("%s %s", http.MethodPost, constants.PathUserInfo)
+	forgotPasswordPath := fmt.Sprintf("%s /password/forgot", http.MethodPost)
+	resetPasswordPath := fmt.Sprintf("%s /password/reset", http.MethodPost)
+	verifyEmailPath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathVerifyEmail)
+	resendVerificationPath := fmt.Sprintf("%s %s/resend", http.MethodPost, constants.PathVerifyEmail)
+	enrollTOTPPath := fmt.Sprintf("%s /mfa/totp", http.MethodPost)
+	totpQRCodePath := fmt.Sprintf("%s /mfa/totp/qr", http.MethodGet)
+	confirmTOTPPath := fmt.Sprintf("%s /mfa/totp/confirm", http.MethodPost)
+	disableTOTPPath := fmt.Sprintf("%s /mfa/totp/disable", http.MethodPost)
+	recoveryCodesPath := fmt.Sprintf("%s /mfa/recovery-codes", http.MethodPost)
+	getProfilePath := fmt.Sprintf("%s /me", http.MethodGet)
+	updateProfilePath := fmt.Sprintf("%s /me", http.MethodPatch)
+	changePasswordPath := fmt.Sprintf("%s /me/password", http.MethodPost)
+	changeEmailPath := fmt.Sprintf("%s /me/email", http.MethodPost)
+	exportDataPath := fmt.Sprintf("%s /me/export", http.MethodGet)
+	deleteAccountPath := fmt.Sprintf("%s /me", http.MethodDelete)
+	cancelDeletionPath := fmt.Sprintf("%s /me/deletion/cancel", http.MethodPost)
+	confirmEmailChangePath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathConfirmEmailChange)
+	publicProfilePath := fmt.Sprintf("%s /users/{username}", http.MethodGet)
+	listSessionsPath := fmt.Sprintf("%s /sessions", http.MethodGet)
+	revokeSessionPath := fmt.Sprintf("%s /sessions/{id}", http.MethodDelete)
+	listTokensPath := fmt.Sprintf("%s /tokens", http.MethodGet)
+	createTokenPath := fmt.Sprintf("%s /tokens", http.MethodPost)
+	revokeTokenPath := fmt.Sprintf("%s /tokens/{id}", http.MethodDelete)
+	logoutPath := fmt.Sprintf("%s /logout", http.MethodPost)
+	logoutAllPath := fmt.Sprintf("%s /logout/all", http.MethodPost)
+	jwksPath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathJWKS)
+	openIDConfigurationPath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathOpenIDConfiguration)
+	registerClientPath := fmt.Sprintf("%s %s", http.MethodPost, constants.PathClients)
+	rotateClientSecretPath := fmt.Sprintf("%s %s/{client_id}/secrets", http.MethodPost, constants.PathClients)
+	revokeClientSecretPath := fmt.Sprintf("%s %s/{client_id}/secrets/{id}", http.MethodDelete, constants.PathClients)
+	authorizePath := fmt.Sprintf("%s %s", http.MethodGet, constants.PathAuthorize)
+	authorizeSubmitPath := fmt.Sprintf("%s %s", http.MethodPost, constants.PathAuthorize)
+	tokenPath := fmt.Sprintf("%s %s", http.MethodPost, constants.PathToken)
+	listRolesPath := fmt.Sprintf("%s /admin/roles", http.MethodGet)
+	userRolesPath := fmt.Sprintf("%s /admin/users/{id}/roles", http.MethodGet)
+	assignRolePath := fmt.Sprintf("%s /admin/users/{id}/roles", http.MethodPost)
+	removeRolePath := fmt.Sprintf("%s /admin/users/{id}/roles/{role}", http.MethodDelete)
+	listUsersPath := fmt.Sprintf("%s /admin/users", http.MethodGet)
+	getUserPath := fmt.Sprintf("%s /admin/users/{id}", http.MethodGet)
+	deleteUserPath := fmt.Sprintf("%s /admin/users/{id}", http.MethodDelete)
+	disableUserPath := fmt.Sprintf("%s /admin/users/{id}/disable", http.MethodPost)
+	enableUserPath := fmt.Sprintf("%s /admin/users/{id}/enable", http.MethodPost)
+	forcePasswordResetPath := fmt.Sprintf("%s /admin/users/{id}/password-reset", http.MethodPost)
+	unlockUserPath := fmt.Sprintf("%s /admin/users/{id}/unlock", http.MethodPost)
+	impersonateUserPath := fmt.Sprintf("%s /admin/users/{id}/impersonate", http.MethodPost)
+	auditLogPath := fmt.Sprintf("%s /admin/audit-log", http.MethodGet)
+	listInvitesPath := fmt.Sprintf("%s /admin/invites", http.MethodGet)
+	createInvitePath := fmt.Sprintf("%s /admin/invites", http.MethodPost)
+	revokeInvitePath := fmt.Sprintf("%s /admin/invites/{id}", http.MethodDelete)
 
 	router := http.ServeMux{}
 
 	router.HandleFunc(registerPath, userCtrl.Register)
 	router.HandleFunc(loginPath, userCtrl.Login)
+	router.HandleFunc(loginMFAPath, userCtrl.LoginMFA)
+	router.HandleFunc(magicLinkPath, magicCtrl.RequestLink)
+	router.HandleFunc(magicLinkCallbackPath, magicCtrl.Callback)
+	router.HandleFunc(refreshPath, userCtrl.RefreshToken)
+	router.HandleFunc(verifyPath, userCtrl.Verify)
+	router.Handle(introspectPath, authenticateIntrospector(http.HandlerFunc(userCtrl.Introspect)))
+	router.Handle(eventsPath, authenticateEventConsumer(http.HandlerFunc(eventCtrl.ListEvents)))
+	router.HandleFunc(forgotPasswordPath, passwordCtrl.ForgotPassword)
+	router.HandleFunc(resetPasswordPath, passwordCtrl.ResetPassword)
+	router.HandleFunc(verifyEmailPath, verifyCtrl.VerifyEmail)
+	router.HandleFunc(resendVerificationPath, verifyCtrl.ResendVerification)
+	router.Handle(enrollTOTPPath, ownerOnly(mfaCtrl.Enroll))
+	router.Handle(totpQRCodePath, account(mfaCtrl.QRCode))
+	router.Handle(confirmTOTPPath, ownerOnly(mfaCtrl.Confirm))
+	router.Handle(disableTOTPPath, ownerOnly(mfaCtrl.Disable))
+	router.Handle(recoveryCodesPath, ownerOnly(mfaCtrl.RegenerateRecoveryCodes))
+	router.Handle(getProfilePath, account(profileCtrl.GetProfile))
+	router.Handle(updateProfilePath, account(profileCtrl.UpdateProfile))
+	router.Handle(changePasswordPath, ownerOnly(profileCtrl.ChangePassword))
+	router.Handle(changeEmailPath, ownerOnly(profileCtrl.ChangeEmail))
+	router.Handle(exportDataPath, ownerOnly(exportCtrl.ExportData))
+	router.Handle(deleteAccountPath, ownerOnly(profileCtrl.DeleteAccount))
+	router.Handle(cancelDeletionPath, account(profileCtrl.CancelDeletion))
+	// opened from the confirmation email, so the token in the link is the only credential
+	router.HandleFunc(confirmEmailChangePath, profileCtrl.ConfirmEmailChange)
+	router.HandleFunc(publicProfilePath, profileCtrl.PublicProfile)
+	router.Handle(listSessionsPath, account(sessionCtrl.ListSessions))
+	router.Handle(revokeSessionPath, account(sessionCtrl.RevokeSession))
+	router.Handle(listTokensPath, account(patCtrl.ListTokens))
+	router.Handle(createTokenPath, ownerOnly(patCtrl.CreateToken))
+	router.Handle(revokeTokenPath, account(patCtrl.RevokeToken))
+	router.Handle(logoutPath, authenticate(http.HandlerFunc(userCtrl.Logout)))
+	router.Handle(logoutAllPath, account(userCtrl.LogoutAll))
+	// OIDC clients may call userinfo with GET or POST
+	router.Handle(userInfoGetPath, authenticate(http.HandlerFunc(userCtrl.UserInfo)))
+	router.Handle(userInfoPostPath, authenticate(http.HandlerFunc(userCtrl.UserInfo)))
+	router.HandleFunc(jwksPath, wellKnownCtrl.JWKS)
+	router.HandleFunc(openIDConfigurationPath, wellKnownCtrl.OpenIDConfiguration)
+	router.Handle(registerClientPath, ownerOnly(oauthCtrl.RegisterClient))
+	router.Handle(rotateClientSecretPath, ownerOnly(oauthCtrl.RotateClientSecret))
+	router.Handle(revokeClientSecretPath, ownerOnly(oauthCtrl.RevokeClientSecret))
+	router.HandleFunc(authorizePath, oauthCtrl.Authorize)
+	router.HandleFunc(authorizeSubmitPath, oauthCtrl.AuthorizeSubmit)
+	router.HandleFunc(tokenPath, oauthCtrl.Token)
+	router.Handle(listRolesPath, admin(constants.PermissionRolesManage, roleCtrl.ListRoles))
+	router.Handle(userRolesPath, admin(constants.PermissionRolesManage, roleCtrl.UserRoles))
+	router.Handle(assignRolePath, admin(constants.PermissionRolesManage, roleCtrl.AssignRole))
+	router.Handle(removeRolePath, admin(constants.PermissionRolesManage, roleCtrl.RemoveRole))
+	router.Handle(listUsersPath, admin(constants.PermissionUsersManage, userAdminCtrl.ListUsers))
+	router.Handle(getUserPath, admin(constants.PermissionUsersManage, userAdminCtrl.GetUser))
+	router.Handle(deleteUserPath, admin(constants.PermissionUsersManage, userAdminCtrl.DeleteUser))
+	router.Handle(disableUserPath, admin(constants.PermissionUsersManage, userAdminCtrl.DisableUser))
+	router.Handle(enableUserPath, admin(constants.PermissionUsersManage, userAdminCtrl.EnableUser))
+	router.Handle(forcePasswordResetPath, admin(constants.PermissionUsersManage, userAdminCtrl.ForcePasswordReset))
+	router.Handle(unlockUserPath, admin(constants.PermissionUsersManage, userAdminCtrl.UnlockUser))
+	router.Handle(impersonateUserPath, admin(constants.PermissionUsersManage, userAdminCtrl.ImpersonateUser))
+	router.Handle(auditLogPath, admin(constants.PermissionUsersManage, userAdminCtrl.AuditLog))
+	router.Handle(listInvitesPath, admin(constants.PermissionUsersManage, inviteCtrl.ListInvites))
+	router.Handle(createInvitePath, admin(constants.PermissionUsersManage, inviteCtrl.CreateInvite))
+	router.Handle(revokeInvitePath, admin(constants.PermissionUsersManage, inviteCtrl.RevokeInvite))
 
 	return &router
 
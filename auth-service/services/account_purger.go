@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+)
+
+// AccountPurger deletes the accounts whose scheduled deletion time has passed. Deleting a user
+// writes a user.deleted outbox event, from which blog-service learns to remove their posts.
+type AccountPurger interface {
+	PurgeDue(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+// accountPurger is an implementation of AccountPurger
+type accountPurger struct {
+	userRepo repositories.UserRepository
+	audit    AuditService
+}
+
+// NewAccountPurger returns a new instance of the account purger
+func NewAccountPurger(userRepo repositories.UserRepository, audit AuditService) AccountPurger {
+	return &accountPurger{
+		userRepo: userRepo,
+		audit:    audit,
+	}
+}
+
+// PurgeDue deletes up to AccountPurgeBatchSize accounts that are due and returns how many were
+// deleted. The security event keeps the email, since the account cannot be looked up afterwards.
+func (a accountPurger) PurgeDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	users, err := a.userRepo.ListDueForDeletion(ctx, now, constants.AccountPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, user := range users {
+		deleted, err := a.userRepo.DeleteIfDue(ctx, user.ID, now)
+		if err != nil {
+			return purged, err
+		}
+		// the user cancelled the deletion or an admin deleted them in the meantime
+		if !deleted {
+			continue
+		}
+		purged++
+		a.audit.Log(ctx, models.AuthEvent{Type: constants.EventAccountDeleted, UserID: user.ID, Email: user.Email})
+		log.Printf("account of user %d deleted as scheduled", user.ID)
+	}
+	return purged, nil
+}
+
+// Run periodically deletes the accounts that are due until ctx is cancelled
+func (a accountPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.AccountPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := a.PurgeDue(ctx); err != nil {
+				log.Println("error while deleting accounts", err.Error())
+			}
+		}
+	}
+}
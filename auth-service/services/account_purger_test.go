@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_accountPurger_PurgeDue(t *testing.T) {
+	due := []models.User{{ID: 7, Email: "gone@example.com"}, {ID: 8, Email: "cancelled@example.com"}}
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("ListDueForDeletion", mock.Anything, mock.Anything, constants.AccountPurgeBatchSize).Return(due, nil)
+	userRepo.On("DeleteIfDue", mock.Anything, int64(7), mock.Anything).Return(true, nil)
+	// the user cancelled the deletion after it was listed
+	userRepo.On("DeleteIfDue", mock.Anything, int64(8), mock.Anything).Return(false, nil)
+	events := &recordingAuditService{}
+
+	purged, err := NewAccountPurger(userRepo, events).PurgeDue(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, models.AuthEvent{Type: constants.EventAccountDeleted, UserID: 7, Email: "gone@example.com"}, events.events[0])
+}
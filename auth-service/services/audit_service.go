@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// AuditService keeps the audit log: what admins do to user accounts, and the security events of
+// every account, such as logins and token revocations
+type AuditService interface {
+	Record(ctx context.Context, actorID int64, action string, targetUserID int64, details string)
+	Log(ctx context.Context, event models.AuthEvent)
+	List(ctx context.Context, req models.AuditLogRequest) (int, models.AuditLogResponse, error)
+}
+
+// auditService is an implementation of AuditService
+type auditService struct {
+	auditRepo repositories.AuditRepository
+}
+
+// NewAuditService returns a new instance of the audit service
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+// Record writes an entry for an admin action that already took effect
+func (s auditService) Record(ctx context.Context, actorID int64, action string, targetUserID int64, details string) {
+	s.write(ctx, models.AuditEntry{
+		ActorUserID:  actorID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		Details:      details,
+	})
+}
+
+// Log writes an entry for a security event. The details of the event are kept as key=value
+// pairs in key order.
+func (s auditService) Log(ctx context.Context, event models.AuthEvent) {
+	details := make([]string, 0, len(event.Details))
+	for key, value := range event.Details {
+		details = append(details, key+"="+value)
+	}
+	slices.Sort(details)
+
+	s.write(ctx, models.AuditEntry{
+		Action:       event.Type,
+		TargetUserID: event.UserID,
+		Email:        event.Email,
+		ClientID:     event.ClientID,
+		Details:      strings.Join(details, " "),
+	})
+}
+
+// write stores entry along with the client IP, user agent and request ID of the request it
+// happened in. A failed write is logged with the entry rather than returned, since the action
+// cannot be taken back anymore.
+func (s auditService) write(ctx context.Context, entry models.AuditEntry) {
+	entry.IP = utils.ClientIPFromContext(ctx)
+	entry.UserAgent = utils.UserAgentFromContext(ctx)
+	entry.RequestID = utils.RequestIDFromContext(ctx)
+	if err := s.auditRepo.Create(ctx, &entry); err != nil {
+		log.Printf("error while writing audit entry %+v: %v", entry, err)
+	}
+}
+
+// List returns a page of the audit entries matching the filter of req, newest first
+func (s auditService) List(ctx context.Context, req models.AuditLogRequest) (int, models.AuditLogResponse, error) {
+	entries, total, err := s.auditRepo.List(ctx, req.Filter, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		log.Println("error while listing audit entries", err.Error())
+		return http.StatusInternalServerError, models.AuditLogResponse{}, err
+	}
+	return http.StatusOK, models.AuditLogResponse{
+		Items:      entries,
+		Pagination: models.NewPagination(req.Page, req.PageSize, total),
+	}, nil
+}
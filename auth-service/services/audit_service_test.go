@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditService keeps the recorded admin actions and logged security events in memory
+type recordingAuditService struct {
+	entries []models.AuditEntry
+	events  []models.AuthEvent
+}
+
+// Record records the admin action
+func (a *recordingAuditService) Record(_ context.Context, actorID int64, action string, targetUserID int64, details string) {
+	a.entries = append(a.entries, models.AuditEntry{ActorUserID: actorID, Action: action, TargetUserID: targetUserID, Details: details})
+}
+
+// Log records the event
+func (a *recordingAuditService) Log(_ context.Context, event models.AuthEvent) {
+	a.events = append(a.events, event)
+}
+
+// List returns the recorded admin actions on a single page
+func (a *recordingAuditService) List(context.Context, models.AuditLogRequest) (int, models.AuditLogResponse, error) {
+	return http.StatusOK, models.AuditLogResponse{Items: a.entries}, nil
+}
+
+func Test_auditService_Record(t *testing.T) {
+	ctx := utils.ContextWithClientIP(context.Background(), "203.0.113.9")
+	ctx = utils.ContextWithUserAgent(ctx, "curl/8.0")
+	ctx = utils.ContextWithRequestID(ctx, "req-42")
+
+	auditRepo := mocks.NewAuditRepository(t)
+	var stored *models.AuditEntry
+	auditRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.AuditEntry)
+	}).Return(nil)
+
+	NewAuditService(auditRepo).Record(ctx, 1, constants.AuditUserDisabled, 7, "spam")
+
+	require.NotNil(t, stored)
+	assert.Equal(t, models.AuditEntry{
+		ActorUserID:  1,
+		Action:       constants.AuditUserDisabled,
+		TargetUserID: 7,
+		Details:      "spam",
+		IP:           "203.0.113.9",
+		UserAgent:    "curl/8.0",
+		RequestID:    "req-42",
+	}, *stored)
+}
+
+func Test_auditService_Log(t *testing.T) {
+	ctx := utils.ContextWithClientIP(context.Background(), "203.0.113.9")
+	ctx = utils.ContextWithUserAgent(ctx, "curl/8.0")
+	ctx = utils.ContextWithRequestID(ctx, "req-42")
+
+	t.Run("event is written to the audit log with the request it happened in", func(t *testing.T) {
+		auditRepo := mocks.NewAuditRepository(t)
+		var stored *models.AuditEntry
+		auditRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*models.AuditEntry)
+		}).Return(nil)
+
+		NewAuditService(auditRepo).Log(ctx, models.AuthEvent{
+			Type:    constants.EventTokenRefreshed,
+			UserID:  7,
+			Email:   "asif@example.com",
+			Details: map[string]string{constants.EventDetailSession: "family-1", constants.EventDetailMethod: constants.LoginMethodPassword},
+		})
+
+		require.NotNil(t, stored)
+		assert.Equal(t, models.AuditEntry{
+			Action:       constants.EventTokenRefreshed,
+			TargetUserID: 7,
+			Email:        "asif@example.com",
+			Details:      "method=password session_id=family-1",
+			IP:           "203.0.113.9",
+			UserAgent:    "curl/8.0",
+			RequestID:    "req-42",
+		}, *stored)
+	})
+
+	t.Run("failed write does not fail the caller", func(t *testing.T) {
+		auditRepo := mocks.NewAuditRepository(t)
+		auditRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+		assert.NotPanics(t, func() {
+			NewAuditService(auditRepo).Log(ctx, models.AuthEvent{Type: constants.EventLoginFailed})
+		})
+	})
+}
+
+func Test_auditService_List(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	filter := models.AuditLogFilter{TargetUserID: 7, Action: constants.EventLoginFailed, From: &from}
+	entries := []models.AuditEntry{{ID: 12, Action: constants.EventLoginFailed, TargetUserID: 7}}
+
+	auditRepo := mocks.NewAuditRepository(t)
+	auditRepo.On("List", mock.Anything, filter, 20, 20).Return(entries, int64(21), nil)
+
+	status, resp, err := NewAuditService(auditRepo).List(context.Background(), models.AuditLogRequest{Filter: filter, Page: 2, PageSize: 20})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, entries, resp.Items)
+	assert.Equal(t, models.NewPagination(2, 20, 21), resp.Pagination)
+}
+
+func Test_userService_LoginEvents(t *testing.T) {
+	hasher := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024)
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	user := models.User{ID: 7, Email: "asif@example.com", Password: hash, EmailVerifiedAt: &now}
+
+	tests := []struct {
+		name       string
+		stored     models.User
+		password   string
+		wantType   string
+		wantUserID int64
+		wantDetail map[string]string
+	}{
+		{
+			name:       "successful login",
+			stored:     user,
+			password:   "correct horse",
+			wantType:   constants.EventLoginSucceeded,
+			wantUserID: 7,
+			wantDetail: map[string]string{constants.EventDetailMethod: constants.LoginMethodPassword},
+		},
+		{
+			name:       "wrong password",
+			stored:     user,
+			password:   "wrong horse",
+			wantType:   constants.EventLoginFailed,
+			wantUserID: 7,
+			wantDetail: map[string]string{constants.EventDetailReason: constants.LoginFailureInvalidCredentials},
+		},
+		{
+			name:       "unknown email",
+			stored:     models.User{},
+			password:   "correct horse",
+			wantType:   constants.EventLoginFailed,
+			wantDetail: map[string]string{constants.EventDetailReason: constants.LoginFailureInvalidCredentials},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewUserRepository(t)
+			repo.On("GetByUserEmail", mock.Anything, user.Email).Return(tt.stored, nil)
+			refreshRepo := mocks.NewRefreshTokenRepository(t)
+			refreshRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+			events := &recordingAuditService{}
+			conf := newTestConfiguration()
+			tokenSvc := NewTokenService(repo, refreshRepo, newTestRoleRepository(t, nil, nil), newTestSessionRepository(t), nil, nil,
+				newTestKeyManager(t, constants.SigningAlgEdDSA), events, conf)
+			u := NewUserService(repo, tokenSvc, nil, newTestLoginThrottle(), hasher, nil, newTestMFAService(t, models.UserMFA{}), nil, events, conf)
+
+			_, _, _ = u.Login(context.Background(), models.LoginRequest{Email: user.Email, Password: tt.password})
+
+			require.Len(t, events.events, 1)
+			assert.Equal(t, tt.wantType, events.events[0].Type)
+			assert.Equal(t, tt.wantUserID, events.events[0].UserID)
+			assert.Equal(t, user.Email, events.events[0].Email)
+			assert.Equal(t, tt.wantDetail, events.events[0].Details)
+		})
+	}
+}
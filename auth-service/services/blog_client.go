@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"auth-service/config"
+	"auth-service/constants"
+)
+
+// BlogClient fetches data users keep in blog-service, on their behalf
+type BlogClient interface {
+	// Configured reports whether BLOG_SERVICE_URL is set
+	Configured() bool
+	// ListPosts returns the posts of the user the access token belongs to, as blog-service encodes them
+	ListPosts(ctx context.Context, accessToken string) (json.RawMessage, error)
+}
+
+// blogClient is an implementation of BlogClient that calls the blog-service API
+type blogClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewBlogClient returns a client for the blog-service at BLOG_SERVICE_URL
+func NewBlogClient(conf config.Configuration) BlogClient {
+	return &blogClient{
+		baseURL: conf.AppConfig().BlogServiceURL(),
+		client:  &http.Client{Timeout: constants.BlogServiceTimeout},
+	}
+}
+
+// Configured reports whether BLOG_SERVICE_URL is set
+func (b *blogClient) Configured() bool {
+	return b.baseURL != ""
+}
+
+// ListPosts forwards the access token of the user to blog-service, which only returns their own posts
+func (b *blogClient) ListPosts(ctx context.Context, accessToken string) (json.RawMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.baseURL+constants.BlogServicePostsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("blog-service: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Data, nil
+}
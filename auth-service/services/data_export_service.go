@@ -0,0 +1,158 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+)
+
+// DataExportService collects the data kept about a user, in auth-service and in blog-service, into a
+// ZIP archive of JSON files the user can download
+type DataExportService interface {
+	Export(ctx context.Context, userID int64, accessToken string) (int, []byte, error)
+}
+
+// dataExportService is an implementation of DataExportService
+type dataExportService struct {
+	userRepo   repositories.UserRepository
+	roleRepo   repositories.RoleRepository
+	sessionSvc SessionService
+	patSvc     PersonalAccessTokenService
+	audit      AuditService
+	blog       BlogClient
+}
+
+// NewDataExportService returns a new instance of the data export service
+func NewDataExportService(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	sessionSvc SessionService,
+	patSvc PersonalAccessTokenService,
+	audit AuditService,
+	blog BlogClient,
+) DataExportService {
+	return &dataExportService{
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		sessionSvc: sessionSvc,
+		patSvc:     patSvc,
+		audit:      audit,
+		blog:       blog,
+	}
+}
+
+// Export returns a ZIP archive with the account, active sessions, personal access tokens and
+// audit log entries of the user, and their posts, which blog-service returns for accessToken. When
+// BLOG_SERVICE_URL is not set the posts are left out; when blog-service cannot be reached the
+// export fails rather than look complete.
+func (d dataExportService) Export(ctx context.Context, userID int64, accessToken string) (int, []byte, error) {
+	user, err := d.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, nil, err
+	}
+	if user.ID == 0 {
+		return http.StatusNotFound, nil, errors.New(constants.ErrUserNotFound)
+	}
+
+	roles, err := d.roleRepo.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		log.Println("error while fetching user roles", err.Error())
+		return http.StatusInternalServerError, nil, err
+	}
+	status, sessions, err := d.sessionSvc.ListSessions(ctx, user.ID, "")
+	if err != nil {
+		return status, nil, err
+	}
+	status, tokens, err := d.patSvc.ListTokens(ctx, user.ID)
+	if err != nil {
+		return status, nil, err
+	}
+	status, entries, err := d.auditEntries(ctx, user.ID)
+	if err != nil {
+		return status, nil, err
+	}
+
+	files := map[string]any{
+		"account.json":                models.AccountExport{Profile: profileOf(user), Roles: roles, CreatedAt: user.CreatedAt},
+		"sessions.json":               sessions,
+		"personal_access_tokens.json": tokens,
+		"audit_log.json":              entries,
+	}
+	if d.blog.Configured() {
+		posts, err := d.blog.ListPosts(ctx, accessToken)
+		if err != nil {
+			log.Printf("error while fetching posts of user %d: %v", user.ID, err)
+			return http.StatusBadGateway, nil, errors.New(constants.ErrBlogServiceUnavailable)
+		}
+		files["posts.json"] = posts
+	} else {
+		log.Printf("BLOG_SERVICE_URL not set, the export of user %d has no posts", user.ID)
+	}
+
+	archive, err := zipJSONFiles(files)
+	if err != nil {
+		log.Println("error while writing data export", err.Error())
+		return http.StatusInternalServerError, nil, err
+	}
+
+	d.audit.Log(ctx, models.AuthEvent{Type: constants.EventDataExported, UserID: user.ID, Email: user.Email})
+	log.Printf("data of user %d exported", user.ID)
+	return http.StatusOK, archive, nil
+}
+
+// auditEntries returns every entry of the audit log about the user, newest first
+func (d dataExportService) auditEntries(ctx context.Context, userID int64) (int, []models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+	req := models.AuditLogRequest{Filter: models.AuditLogFilter{TargetUserID: userID}, Page: 1, PageSize: constants.MaxPageSize}
+	for {
+		status, page, err := d.audit.List(ctx, req)
+		if err != nil {
+			return status, nil, err
+		}
+		entries = append(entries, page.Items...)
+		if !page.Pagination.HasMore {
+			return http.StatusOK, entries, nil
+		}
+		req.Page++
+	}
+}
+
+// zipJSONFiles returns a ZIP archive with a file for each entry of files, holding its value as
+// indented JSON. Files are added in name order.
+func zipJSONFiles(files map[string]any) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		encoded, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		w, err := archive.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBlogClient returns fixed posts, or err, and remembers the access token it was given
+type fakeBlogClient struct {
+	configured  bool
+	posts       string
+	err         error
+	accessToken string
+}
+
+// Configured reports whether the fake stands in for a configured blog-service
+func (f *fakeBlogClient) Configured() bool {
+	return f.configured
+}
+
+// ListPosts returns the fixed posts
+func (f *fakeBlogClient) ListPosts(_ context.Context, accessToken string) (json.RawMessage, error) {
+	f.accessToken = accessToken
+	return json.RawMessage(f.posts), f.err
+}
+
+// unzipExport returns the files of a data export by name
+func unzipExport(t *testing.T, archive []byte) map[string][]byte {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[file.Name] = content
+	}
+	return files
+}
+
+func Test_dataExportService_Export(t *testing.T) {
+	user := models.User{ID: 7, Email: "asif@example.com", Username: "asif"}
+
+	newService := func(t *testing.T, blog BlogClient) (DataExportService, *recordingAuditService) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		sessionRepo := mocks.NewSessionRepository(t)
+		sessionRepo.On("ListActiveForUser", mock.Anything, user.ID, mock.Anything).Return([]models.Session{{ID: 3, UserAgent: "curl/8.0"}}, nil)
+		patRepo := mocks.NewPersonalAccessTokenRepository(t)
+		patRepo.On("ListActiveForUser", mock.Anything, user.ID, mock.Anything).Return([]models.PersonalAccessToken{{ID: 4, Name: "ci", TokenHash: "secret-hash"}}, nil)
+		events := &recordingAuditService{entries: []models.AuditEntry{{Action: constants.EventLoginSucceeded, TargetUserID: user.ID}}}
+		conf := newTestConfiguration()
+
+		svc := NewDataExportService(userRepo, newTestRoleRepository(t, []string{constants.RoleModerator}, nil),
+			NewSessionService(sessionRepo, nil, events, conf), NewPersonalAccessTokenService(patRepo, events), events, blog)
+		return svc, events
+	}
+
+	t.Run("archive holds the account data and the posts", func(t *testing.T) {
+		blog := &fakeBlogClient{configured: true, posts: `[{"id":1,"title":"Hello"}]`}
+		svc, events := newService(t, blog)
+
+		status, archive, err := svc.Export(context.Background(), user.ID, "access-token")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "access-token", blog.accessToken)
+		files := unzipExport(t, archive)
+		assert.Len(t, files, 5)
+
+		var account models.AccountExport
+		require.NoError(t, json.Unmarshal(files["account.json"], &account))
+		assert.Equal(t, user.Email, account.Email)
+		assert.Equal(t, []string{constants.RoleModerator}, account.Roles)
+		assert.JSONEq(t, `[{"id":1,"title":"Hello"}]`, string(files["posts.json"]))
+		assert.Contains(t, string(files["sessions.json"]), "curl/8.0")
+		assert.Contains(t, string(files["audit_log.json"]), constants.EventLoginSucceeded)
+		// token hashes never leave auth-service
+		assert.NotContains(t, string(files["personal_access_tokens.json"]), "secret-hash")
+		assert.Equal(t, constants.EventDataExported, events.events[len(events.events)-1].Type)
+	})
+
+	t.Run("posts are left out without blog-service", func(t *testing.T) {
+		svc, _ := newService(t, &fakeBlogClient{})
+
+		status, archive, err := svc.Export(context.Background(), user.ID, "access-token")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.NotContains(t, unzipExport(t, archive), "posts.json")
+	})
+
+	t.Run("export fails when blog-service does", func(t *testing.T) {
+		svc, _ := newService(t, &fakeBlogClient{configured: true, err: errors.New("connection refused")})
+
+		status, _, err := svc.Export(context.Background(), user.ID, "access-token")
+
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.EqualError(t, err, constants.ErrBlogServiceUnavailable)
+	})
+}
+
+func Test_blogClient_ListPosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != constants.BlogServicePostsPath || r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1}]}`))
+	}))
+	defer server.Close()
+
+	env := viper.New()
+	env.Set(constants.BlogServiceURL, server.URL+"/")
+	client := NewBlogClient(config.NewConfiguration(config.NewAppConfig(env)))
+	require.True(t, client.Configured())
+
+	posts, err := client.ListPosts(context.Background(), "access-token")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id":1}]`, string(posts))
+
+	_, err = client.ListPosts(context.Background(), "other-token")
+	assert.Error(t, err)
+
+	assert.False(t, NewBlogClient(newTestConfiguration()).Configured())
+}
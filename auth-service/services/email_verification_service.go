@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// EmailVerificationService proves that users own the email address they registered with by
+// mailing them a verification link
+type EmailVerificationService interface {
+	SendVerification(ctx context.Context, user models.User) error
+	VerifyEmail(ctx context.Context, token string) (int, error)
+	ResendVerification(ctx context.Context, email string) (int, error)
+}
+
+// emailVerificationService is an implementation of EmailVerificationService
+type emailVerificationService struct {
+	userRepo   repositories.UserRepository
+	verifyRepo repositories.EmailVerificationRepository
+	mailer     Mailer
+	conf       config.Configuration
+}
+
+// NewEmailVerificationService returns a new instance of the email verification service
+func NewEmailVerificationService(
+	userRepo repositories.UserRepository,
+	verifyRepo repositories.EmailVerificationRepository,
+	mailer Mailer,
+	conf config.Configuration,
+) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:   userRepo,
+		verifyRepo: verifyRepo,
+		mailer:     mailer,
+		conf:       conf,
+	}
+}
+
+// SendVerification replaces any outstanding verification token of the user with a new one and
+// mails a link to GET /verify-email with it
+func (e emailVerificationService) SendVerification(ctx context.Context, user models.User) error {
+	appConf := e.conf.AppConfig()
+
+	if err := e.verifyRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	stored := models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(appConf.EmailVerificationTTL()),
+	}
+	if err := e.verifyRepo.Create(ctx, &stored); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(appConf.Issuer() + constants.PathVerifyEmail)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return e.mailer.Send(ctx, models.Email{
+		From:    appConf.MailFrom(),
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Welcome! Please confirm that this is your email address.\r\n\r\n"+
+			"Open this link within %s to verify it:\r\n%s\r\n\r\n"+
+			"If you did not create an account, ignore this email.",
+			appConf.EmailVerificationTTL(), link.String()),
+	})
+}
+
+// VerifyEmail marks the email of the token's user as verified. The token is consumed together
+// with any other outstanding token of the user.
+func (e emailVerificationService) VerifyEmail(ctx context.Context, token string) (int, error) {
+	errInvalid := errors.New(constants.ErrInvalidVerificationToken)
+	if token == "" {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	stored, err := e.verifyRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if stored.ID == 0 || stored.UsedAt != nil || time.Now().UTC().After(stored.ExpiresAt) {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	// tokens are single use; losing this race means a concurrent request used it
+	marked, err := e.verifyRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		log.Println("error while consuming email verification token", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !marked {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	if err := e.userRepo.MarkEmailVerified(ctx, stored.UserID); err != nil {
+		log.Println("error while marking email verified", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if err := e.verifyRepo.InvalidateForUser(ctx, stored.UserID); err != nil {
+		log.Println("error while invalidating email verification tokens", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	log.Printf("email verified for user %d", stored.UserID)
+	return http.StatusOK, nil
+}
+
+// ResendVerification mails a new verification link to the user with the given email. Like
+// ForgotPassword it answers the same for unknown and already verified emails, so the endpoint
+// cannot be used to probe for accounts.
+func (e emailVerificationService) ResendVerification(ctx context.Context, email string) (int, error) {
+	user, err := e.userRepo.GetByUserEmail(ctx, email)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if user.ID == 0 || user.EmailVerified() {
+		return http.StatusAccepted, nil
+	}
+
+	if err := e.SendVerification(ctx, user); err != nil {
+		log.Printf("error while sending verification email to user %d: %v", user.ID, err)
+	}
+	return http.StatusAccepted, nil
+}
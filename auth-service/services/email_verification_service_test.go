@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_emailVerificationService_ResendVerification(t *testing.T) {
+	verifiedAt := time.Now().UTC()
+	user := models.User{ID: 7, Email: "asif@example.com"}
+
+	t.Run("unknown and verified emails get the same answer and no mail", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByUserEmail", mock.Anything, "nobody@example.com").Return(models.User{}, nil)
+		userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(models.User{ID: user.ID, Email: user.Email, EmailVerifiedAt: &verifiedAt}, nil)
+		mailer := &recordingMailer{}
+		svc := NewEmailVerificationService(userRepo, mocks.NewEmailVerificationRepository(t), mailer, newTestConfiguration())
+
+		for _, email := range []string{"nobody@example.com", user.Email} {
+			status, err := svc.ResendVerification(context.Background(), email)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusAccepted, status)
+		}
+		assert.Empty(t, mailer.sent)
+	})
+
+	t.Run("unverified email gets a single-use link", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+		verifyRepo := mocks.NewEmailVerificationRepository(t)
+		verifyRepo.On("InvalidateForUser", mock.Anything, user.ID).Return(nil)
+		var stored *models.EmailVerificationToken
+		verifyRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*models.EmailVerificationToken)
+		}).Return(nil)
+		mailer := &recordingMailer{}
+		svc := NewEmailVerificationService(userRepo, verifyRepo, mailer, newTestConfiguration())
+
+		status, err := svc.ResendVerification(context.Background(), user.Email)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		require.Len(t, mailer.sent, 1)
+		assert.Equal(t, user.Email, mailer.sent[0].To)
+
+		// the link points at auth-service and only the hash of its token is stored
+		link := mailer.sent[0].Body[strings.Index(mailer.sent[0].Body, constants.DefaultTokenIssuer+constants.PathVerifyEmail):]
+		parsed, err := url.Parse(strings.Fields(link)[0])
+		require.NoError(t, err)
+		token := parsed.Query().Get("token")
+		require.NotEmpty(t, token)
+		assert.Equal(t, utils.HashToken(token), stored.TokenHash)
+		assert.WithinDuration(t, time.Now().UTC().Add(constants.DefaultEmailVerificationTTL), stored.ExpiresAt, time.Minute)
+	})
+}
+
+func Test_emailVerificationService_VerifyEmail(t *testing.T) {
+	const token = "verification-token"
+	usedAt := time.Now().UTC().Add(-time.Minute)
+	valid := models.EmailVerificationToken{ID: 3, UserID: 7, TokenHash: utils.HashToken(token), ExpiresAt: time.Now().UTC().Add(time.Minute)}
+
+	tests := []struct {
+		name       string
+		stored     models.EmailVerificationToken
+		marked     bool
+		wantStatus int
+		wantErr    string
+	}{
+		{name: "valid token verifies the email", stored: valid, marked: true, wantStatus: http.StatusOK},
+		{name: "unknown token", stored: models.EmailVerificationToken{}, wantStatus: http.StatusBadRequest, wantErr: constants.ErrInvalidVerificationToken},
+		{
+			name:       "used token",
+			stored:     models.EmailVerificationToken{ID: 3, UserID: 7, ExpiresAt: valid.ExpiresAt, UsedAt: &usedAt},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    constants.ErrInvalidVerificationToken,
+		},
+		{
+			name:       "expired token",
+			stored:     models.EmailVerificationToken{ID: 3, UserID: 7, ExpiresAt: time.Now().UTC().Add(-time.Second)},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    constants.ErrInvalidVerificationToken,
+		},
+		{name: "token consumed concurrently", stored: valid, marked: false, wantStatus: http.StatusBadRequest, wantErr: constants.ErrInvalidVerificationToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewUserRepository(t)
+			verifyRepo := mocks.NewEmailVerificationRepository(t)
+
+			verifyRepo.On("GetByHash", mock.Anything, utils.HashToken(token)).Return(tt.stored, nil)
+			if tt.stored.ID != 0 && tt.stored.UsedAt == nil && tt.stored.ExpiresAt.After(time.Now()) {
+				verifyRepo.On("MarkUsed", mock.Anything, tt.stored.ID).Return(tt.marked, nil)
+			}
+			if tt.wantStatus == http.StatusOK {
+				userRepo.On("MarkEmailVerified", mock.Anything, valid.UserID).Return(nil)
+				verifyRepo.On("InvalidateForUser", mock.Anything, valid.UserID).Return(nil)
+			}
+
+			svc := NewEmailVerificationService(userRepo, verifyRepo, &recordingMailer{}, newTestConfiguration())
+
+			status, err := svc.VerifyEmail(context.Background(), token)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
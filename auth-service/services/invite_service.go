@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// InviteService enforces the registration mode and manages the invite codes admins hand out while
+// registration is restricted
+type InviteService interface {
+	CreateInvite(ctx context.Context, actorID int64, req models.CreateInviteRequest) (int, models.CreateInviteResponse, error)
+	ListInvites(ctx context.Context, req models.InviteListRequest) (int, models.InviteListResponse, error)
+	RevokeInvite(ctx context.Context, actorID, inviteID int64) (int, error)
+	CheckRegistration(ctx context.Context, email, code string) (int, models.Invite, error)
+	Redeem(ctx context.Context, invite models.Invite, userID int64) (int, error)
+}
+
+// inviteService is an implementation of InviteService
+type inviteService struct {
+	inviteRepo repositories.InviteRepository
+	roleRepo   repositories.RoleRepository
+	audit      AuditService
+	conf       config.Configuration
+}
+
+// NewInviteService returns a new instance of the invite service
+func NewInviteService(
+	inviteRepo repositories.InviteRepository,
+	roleRepo repositories.RoleRepository,
+	audit AuditService,
+	conf config.Configuration,
+) InviteService {
+	return &inviteService{
+		inviteRepo: inviteRepo,
+		roleRepo:   roleRepo,
+		audit:      audit,
+		conf:       conf,
+	}
+}
+
+// CreateInvite creates an invite code valid for InviteTTL. The plain code is returned once; only its
+// hash is stored. An email limits the code to that address, and a role is granted on registration.
+func (s inviteService) CreateInvite(ctx context.Context, actorID int64, req models.CreateInviteRequest) (int, models.CreateInviteResponse, error) {
+	invite := models.Invite{
+		CreatedBy: actorID,
+		ExpiresAt: time.Now().UTC().Add(s.conf.AppConfig().InviteTTL()),
+	}
+
+	if email := strings.TrimSpace(req.Email); email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return http.StatusBadRequest, models.CreateInviteResponse{}, errors.New(constants.ErrInvalidEmail)
+		}
+		invite.Email = email
+	}
+	if name := strings.TrimSpace(req.Role); name != "" {
+		role, err := s.roleRepo.GetByName(ctx, name)
+		if err != nil {
+			log.Println("error while fetching role", err.Error())
+			return http.StatusInternalServerError, models.CreateInviteResponse{}, err
+		}
+		if role.ID == 0 {
+			return http.StatusBadRequest, models.CreateInviteResponse{}, errors.New(constants.ErrRoleNotFound)
+		}
+		invite.RoleID, invite.Role = role.ID, role.Name
+	}
+
+	code, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return http.StatusInternalServerError, models.CreateInviteResponse{}, err
+	}
+	invite.CodeHash = utils.HashToken(code)
+	if err := s.inviteRepo.Create(ctx, &invite); err != nil {
+		log.Println("error while storing invite", err.Error())
+		return http.StatusInternalServerError, models.CreateInviteResponse{}, err
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditInviteCreated, 0, inviteDetails(invite))
+	log.Printf("user %d created invite %d", actorID, invite.ID)
+	return http.StatusCreated, models.CreateInviteResponse{Invite: invite, Code: code}, nil
+}
+
+// ListInvites returns a page of invites, newest first, whether used, revoked, expired or pending
+func (s inviteService) ListInvites(ctx context.Context, req models.InviteListRequest) (int, models.InviteListResponse, error) {
+	invites, total, err := s.inviteRepo.List(ctx, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		log.Println("error while listing invites", err.Error())
+		return http.StatusInternalServerError, models.InviteListResponse{}, err
+	}
+	return http.StatusOK, models.InviteListResponse{
+		Items:      invites,
+		Pagination: models.NewPagination(req.Page, req.PageSize, total),
+	}, nil
+}
+
+// RevokeInvite revokes an invite that was not used yet
+func (s inviteService) RevokeInvite(ctx context.Context, actorID, inviteID int64) (int, error) {
+	revoked, err := s.inviteRepo.Revoke(ctx, inviteID)
+	if err != nil {
+		log.Println("error while revoking invite", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !revoked {
+		return http.StatusNotFound, errors.New(constants.ErrInviteNotFound)
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditInviteRevoked, 0, "invite "+strconv.FormatInt(inviteID, 10))
+	log.Printf("user %d revoked invite %d", actorID, inviteID)
+	return http.StatusOK, nil
+}
+
+// CheckRegistration decides whether email may register under the registration mode. A non-empty
+// code must belong to a usable invite, which is returned for Redeem; it is accepted in every mode
+// but disabled, and lets addresses outside REGISTRATION_ALLOWED_DOMAINS register under domain.
+func (s inviteService) CheckRegistration(ctx context.Context, email, code string) (int, models.Invite, error) {
+	appConf := s.conf.AppConfig()
+	mode := appConf.RegistrationMode()
+	if mode == constants.RegistrationDisabled {
+		return http.StatusForbidden, models.Invite{}, errors.New(constants.ErrRegistrationClosed)
+	}
+
+	if code = strings.TrimSpace(code); code != "" {
+		invite, err := s.inviteRepo.GetByHash(ctx, utils.HashToken(code))
+		if err != nil {
+			log.Println("error while fetching invite", err.Error())
+			return http.StatusInternalServerError, models.Invite{}, err
+		}
+		if !invite.Usable(time.Now().UTC()) || (invite.Email != "" && !strings.EqualFold(invite.Email, email)) {
+			return http.StatusForbidden, models.Invite{}, errors.New(constants.ErrInvalidInvite)
+		}
+		return http.StatusOK, invite, nil
+	}
+
+	switch mode {
+	case constants.RegistrationInvite:
+		return http.StatusForbidden, models.Invite{}, errors.New(constants.ErrInviteRequired)
+	case constants.RegistrationDomain:
+		domains := appConf.RegistrationAllowedDomains()
+		at := strings.LastIndex(email, "@")
+		if at < 0 || !slices.Contains(domains, strings.ToLower(email[at+1:])) {
+			return http.StatusForbidden, models.Invite{}, fmt.Errorf(constants.ErrEmailDomainNotAllowed, strings.Join(domains, ", "))
+		}
+	}
+	return http.StatusOK, models.Invite{}, nil
+}
+
+// Redeem uses up the invite for the user who just registered with it and grants its role. It fails
+// when a concurrent registration used the invite first. A failed role grant is only logged, since
+// an admin can still grant the role.
+func (s inviteService) Redeem(ctx context.Context, invite models.Invite, userID int64) (int, error) {
+	redeemed, err := s.inviteRepo.Redeem(ctx, invite.ID, userID)
+	if err != nil {
+		log.Println("error while redeeming invite", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !redeemed {
+		return http.StatusForbidden, errors.New(constants.ErrInvalidInvite)
+	}
+
+	if invite.RoleID != 0 {
+		if _, err := s.roleRepo.Assign(ctx, userID, invite.RoleID, invite.CreatedBy); err != nil {
+			log.Printf("error while granting role %s of invite %d to user %d: %v", invite.Role, invite.ID, userID, err)
+		}
+	}
+	log.Printf("user %d registered with invite %d", userID, invite.ID)
+	return http.StatusOK, nil
+}
+
+// inviteDetails describes an invite for the audit log
+func inviteDetails(invite models.Invite) string {
+	details := "invite " + strconv.FormatInt(invite.ID, 10)
+	if invite.Email != "" {
+		details += " for " + invite.Email
+	}
+	if invite.Role != "" {
+		details += " with role " + invite.Role
+	}
+	return details
+}
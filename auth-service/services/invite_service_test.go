@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRegistrationConfiguration returns a configuration with the given registration mode and
+// allowed domains. Email verification is off, so registrations mail nothing.
+func newTestRegistrationConfiguration(mode, domains string) config.Configuration {
+	env := viper.New()
+	env.Set(constants.SecretKey, "test-secret")
+	env.Set(constants.EmailVerificationPolicy, constants.EmailVerificationOff)
+	env.Set(constants.RegistrationMode, mode)
+	env.Set(constants.RegistrationAllowedDomains, domains)
+	return config.NewConfiguration(config.NewAppConfig(env))
+}
+
+func Test_inviteService_CheckRegistration(t *testing.T) {
+	const code = "invite-code"
+	now := time.Now().UTC()
+	pending := models.Invite{ID: 3, ExpiresAt: now.Add(time.Hour)}
+	forBob := models.Invite{ID: 3, Email: "bob@example.com", ExpiresAt: now.Add(time.Hour)}
+	used := models.Invite{ID: 3, ExpiresAt: now.Add(time.Hour), UsedAt: &now}
+	expired := models.Invite{ID: 3, ExpiresAt: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name       string
+		mode       string
+		email      string
+		code       string
+		stored     models.Invite
+		wantStatus int
+		wantErr    string
+		wantInvite int64
+	}{
+		{
+			name:       "open registration",
+			mode:       constants.RegistrationOpen,
+			email:      "alice@gmail.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "closed registration refuses invites too",
+			mode:       constants.RegistrationDisabled,
+			email:      "alice@example.com",
+			code:       code,
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrRegistrationClosed,
+		},
+		{
+			name:       "invite only without code",
+			mode:       constants.RegistrationInvite,
+			email:      "alice@example.com",
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrInviteRequired,
+		},
+		{
+			name:       "invite only with code",
+			mode:       constants.RegistrationInvite,
+			email:      "alice@example.com",
+			code:       code,
+			stored:     pending,
+			wantStatus: http.StatusOK,
+			wantInvite: 3,
+		},
+		{
+			name:       "used invite",
+			mode:       constants.RegistrationInvite,
+			email:      "alice@example.com",
+			code:       code,
+			stored:     used,
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrInvalidInvite,
+		},
+		{
+			name:       "expired invite",
+			mode:       constants.RegistrationInvite,
+			email:      "alice@example.com",
+			code:       code,
+			stored:     expired,
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrInvalidInvite,
+		},
+		{
+			name:       "invite for another email",
+			mode:       constants.RegistrationInvite,
+			email:      "alice@example.com",
+			code:       code,
+			stored:     forBob,
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrInvalidInvite,
+		},
+		{
+			name:       "invite for the email in other case",
+			mode:       constants.RegistrationInvite,
+			email:      "Bob@Example.com",
+			code:       code,
+			stored:     forBob,
+			wantStatus: http.StatusOK,
+			wantInvite: 3,
+		},
+		{
+			name:       "allowed domain",
+			mode:       constants.RegistrationDomain,
+			email:      "alice@Example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other domain",
+			mode:       constants.RegistrationDomain,
+			email:      "alice@gmail.com",
+			wantStatus: http.StatusForbidden,
+			wantErr:    fmt.Sprintf(constants.ErrEmailDomainNotAllowed, "example.com, example.org"),
+		},
+		{
+			name:       "other domain with invite",
+			mode:       constants.RegistrationDomain,
+			email:      "alice@gmail.com",
+			code:       code,
+			stored:     pending,
+			wantStatus: http.StatusOK,
+			wantInvite: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inviteRepo := mocks.NewInviteRepository(t)
+			inviteRepo.On("GetByHash", mock.Anything, utils.HashToken(code)).Return(tt.stored, nil).Maybe()
+			svc := NewInviteService(inviteRepo, nil, nil, newTestRegistrationConfiguration(tt.mode, " example.com, EXAMPLE.org ,"))
+
+			status, invite, err := svc.CheckRegistration(context.Background(), tt.email, tt.code)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantInvite, invite.ID)
+		})
+	}
+}
+
+func Test_inviteService_CreateInvite(t *testing.T) {
+	moderator := models.Role{ID: 2, Name: constants.RoleModerator}
+
+	t.Run("invite with email and role", func(t *testing.T) {
+		roleRepo := mocks.NewRoleRepository(t)
+		roleRepo.On("GetByName", mock.Anything, constants.RoleModerator).Return(moderator, nil)
+		inviteRepo := mocks.NewInviteRepository(t)
+		var stored *models.Invite
+		inviteRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*models.Invite)
+			stored.ID = 3
+		}).Return(nil)
+		auditRepo := mocks.NewAuditRepository(t)
+		expectAudit(auditRepo, 1, constants.AuditInviteCreated, 0)
+		svc := NewInviteService(inviteRepo, roleRepo, NewAuditService(auditRepo), newTestConfiguration())
+
+		status, resp, err := svc.CreateInvite(context.Background(), 1, models.CreateInviteRequest{Email: " bob@example.com ", Role: constants.RoleModerator})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		require.NotNil(t, stored)
+		assert.Equal(t, utils.HashToken(resp.Code), stored.CodeHash)
+		assert.Equal(t, "bob@example.com", stored.Email)
+		assert.Equal(t, moderator.ID, stored.RoleID)
+		assert.Equal(t, int64(1), stored.CreatedBy)
+		assert.WithinDuration(t, time.Now().UTC().Add(constants.DefaultInviteTTL), stored.ExpiresAt, time.Minute)
+		assert.Equal(t, int64(3), resp.ID)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		roleRepo := mocks.NewRoleRepository(t)
+		roleRepo.On("GetByName", mock.Anything, "owner").Return(models.Role{}, nil)
+		svc := NewInviteService(mocks.NewInviteRepository(t), roleRepo, nil, newTestConfiguration())
+
+		status, _, err := svc.CreateInvite(context.Background(), 1, models.CreateInviteRequest{Role: "owner"})
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, constants.ErrRoleNotFound)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		svc := NewInviteService(mocks.NewInviteRepository(t), nil, nil, newTestConfiguration())
+
+		status, _, err := svc.CreateInvite(context.Background(), 1, models.CreateInviteRequest{Email: "bob"})
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, constants.ErrInvalidEmail)
+	})
+}
+
+func Test_userService_RegisterWithInvite(t *testing.T) {
+	const code = "invite-code"
+	invite := models.Invite{ID: 3, RoleID: 2, Role: constants.RoleModerator, CreatedBy: 1, ExpiresAt: time.Now().UTC().Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		redeemed   bool
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "invite is used up and grants its role",
+			redeemed:   true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invite used concurrently takes the registration back",
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrInvalidInvite,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := newTestRegistrationConfiguration(constants.RegistrationInvite, "")
+			userRepo := mocks.NewUserRepository(t)
+			userRepo.On("GetByUserEmail", mock.Anything, "alice@example.com").Return(models.User{}, nil)
+			userRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*models.User).ID = 7
+			}).Return(nil)
+			inviteRepo := mocks.NewInviteRepository(t)
+			inviteRepo.On("GetByHash", mock.Anything, utils.HashToken(code)).Return(invite, nil)
+			inviteRepo.On("Redeem", mock.Anything, int64(3), int64(7)).Return(tt.redeemed, nil)
+			roleRepo := mocks.NewRoleRepository(t)
+			if tt.redeemed {
+				roleRepo.On("Assign", mock.Anything, int64(7), int64(2), int64(1)).Return(true, nil)
+			} else {
+				userRepo.On("Delete", mock.Anything, int64(7)).Return(true, nil)
+			}
+			events := &recordingAuditService{}
+			invites := NewInviteService(inviteRepo, roleRepo, nil, conf)
+			u := NewUserService(userRepo, nil, nil, nil, newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024),
+				NewPasswordPolicy(conf), nil, invites, events, conf)
+
+			user := &models.User{Email: "alice@example.com", Password: "correct horse battery", InviteCode: code}
+			status, err := u.Register(context.Background(), user)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Empty(t, events.events)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, events.events, 1)
+			assert.Equal(t, "3", events.events[0].Details[constants.EventDetailInviteID])
+		})
+	}
+}
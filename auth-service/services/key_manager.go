@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/utils"
+)
+
+// keyFileExt is the extension of signing key files; the file name without it is the kid
+const keyFileExt = ".pem"
+
+// KeyManager owns the access token signing keys. Keys are PEM files in a directory, so they
+// survive restarts and can be shared between instances through a volume.
+//
+// Rotation works with overlap windows: a new key is generated KeyPublishLead before the current
+// one reaches the rotation interval and is only published at first; it starts signing once
+// verifiers had time to refresh their JWKS copy. The old key stays published until every token
+// it signed has expired, then its file is deleted.
+type KeyManager interface {
+	utils.KeyResolver
+	SigningKey() (utils.SigningKey, error)
+	JWKS() models.JWKS
+	Load() error
+	Run(ctx context.Context)
+}
+
+// keyManager is an implementation of KeyManager
+type keyManager struct {
+	conf config.Configuration
+
+	mu     sync.RWMutex
+	keys   []utils.SigningKey // oldest first
+	active int
+}
+
+// NewKeyManager returns a new instance of the key manager
+func NewKeyManager(conf config.Configuration) KeyManager {
+	return &keyManager{conf: conf, active: -1}
+}
+
+// PublicKey returns the public key and algorithm of a published key
+func (m *keyManager) PublicKey(kid string) (crypto.PublicKey, string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.keys {
+		if key.ID == kid {
+			return key.PublicKey(), key.Algorithm, true
+		}
+	}
+	return nil, "", false
+}
+
+// SigningKey returns the key new tokens are signed with
+func (m *keyManager) SigningKey() (utils.SigningKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.active < 0 {
+		return utils.SigningKey{}, errors.New(constants.ErrNoSigningKey)
+	}
+	return m.keys[m.active], nil
+}
+
+// JWKS returns every published key, including the upcoming and the retiring ones
+func (m *keyManager) JWKS() models.JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jwks := models.JWKS{Keys: make([]models.JWK, 0, len(m.keys))}
+	for _, key := range m.keys {
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	return jwks
+}
+
+// Load reads the key directory, generates a key when rotation is due and deletes retired keys
+func (m *keyManager) Load() error {
+	appConf := m.conf.AppConfig()
+	dir := appConf.SigningKeysDir()
+	now := time.Now().UTC()
+
+	keys, err := readSigningKeys(dir)
+	if err != nil {
+		return err
+	}
+
+	rotateAt := appConf.KeyRotationInterval() - constants.KeyPublishLead
+	if len(keys) == 0 || now.Sub(keys[len(keys)-1].CreatedAt) >= rotateAt {
+		key, err := generateSigningKey(dir, appConf.SigningAlgorithm(), now)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	active := activeKeyIndex(keys, now)
+
+	// a key may go once its successor has signed for longer than an access token lives
+	retireAfter := constants.KeyPublishLead + appConf.AccessTokenTTL()
+	retired := 0
+	for retired < active && now.Sub(keys[retired+1].CreatedAt) > retireAfter {
+		if err := os.Remove(filepath.Join(dir, keys[retired].ID+keyFileExt)); err != nil && !os.IsNotExist(err) {
+			log.Printf("error deleting retired signing key %s: %v", keys[retired].ID, err)
+			break
+		}
+		retired++
+	}
+
+	m.mu.Lock()
+	m.keys = keys[retired:]
+	m.active = active - retired
+	m.mu.Unlock()
+	return nil
+}
+
+// Run periodically reloads the keys and rotates them until ctx is done
+func (m *keyManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.KeySyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Load(); err != nil {
+				log.Printf("error reloading signing keys: %v", err)
+			}
+		}
+	}
+}
+
+// activeKeyIndex picks the newest key published for at least KeyPublishLead. When there is none,
+// e.g. on first start, the newest key is used right away since no verifier can have a stale JWKS.
+func activeKeyIndex(keys []utils.SigningKey, now time.Time) int {
+	for i := len(keys) - 1; i >= 0; i-- {
+		if now.Sub(keys[i].CreatedAt) >= constants.KeyPublishLead {
+			return i
+		}
+	}
+	return len(keys) - 1
+}
+
+// readSigningKeys parses every PEM file in dir, oldest first. A key's age is its file modification time.
+func readSigningKeys(dir string) ([]utils.SigningKey, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]utils.SigningKey, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(entry.Name(), keyFileExt)
+		key, err := utils.ParseSigningKeyPEM(kid, data, info.ModTime().UTC())
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// generateSigningKey creates a key and stores it in dir
+func generateSigningKey(dir, algorithm string, now time.Time) (utils.SigningKey, error) {
+	suffix, err := utils.GenerateRandomID()
+	if err != nil {
+		return utils.SigningKey{}, err
+	}
+	kid := fmt.Sprintf("%s-%s", now.Format("20060102T150405Z"), suffix[:8])
+
+	key, err := utils.GenerateSigningKey(kid, algorithm, now)
+	if err != nil {
+		return utils.SigningKey{}, err
+	}
+	data, err := key.EncodePEM()
+	if err != nil {
+		return utils.SigningKey{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, kid+keyFileExt), data, 0o600); err != nil {
+		return utils.SigningKey{}, err
+	}
+
+	log.Printf("generated %s signing key %s", algorithm, kid)
+	return key, nil
+}
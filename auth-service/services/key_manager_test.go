@@ -0,0 +1,135 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/utils"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeAgedKey stores a new key in dir whose file looks age old
+func writeAgedKey(t *testing.T, dir, kid string, age time.Duration) {
+	key, err := utils.GenerateSigningKey(kid, constants.SigningAlgEdDSA, time.Now().UTC())
+	require.NoError(t, err)
+	data, err := key.EncodePEM()
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, kid+keyFileExt)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	createdAt := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, createdAt, createdAt))
+}
+
+func Test_keyManager_Load(t *testing.T) {
+	const rotation = 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		existing   map[string]time.Duration // kid -> age
+		wantKeys   int
+		wantActive string // empty means a freshly generated key
+		wantGone   []string
+	}{
+		{
+			name:     "empty directory gets a key that signs right away",
+			existing: map[string]time.Duration{},
+			wantKeys: 1,
+		},
+		{
+			name:       "young key is kept and used",
+			existing:   map[string]time.Duration{"current": time.Hour},
+			wantKeys:   1,
+			wantActive: "current",
+		},
+		{
+			name:       "key close to rotation gets a successor that is only published",
+			existing:   map[string]time.Duration{"current": rotation - time.Minute},
+			wantKeys:   2,
+			wantActive: "current",
+		},
+		{
+			name: "successor signs once it was published long enough",
+			existing: map[string]time.Duration{
+				"old": rotation,
+				"new": constants.KeyPublishLead + time.Minute,
+			},
+			wantKeys:   2,
+			wantActive: "new",
+		},
+		{
+			name: "old key is deleted once its tokens expired",
+			existing: map[string]time.Duration{
+				"old": 2 * rotation,
+				"new": constants.KeyPublishLead + constants.DefaultAccessTokenTTL + time.Minute,
+			},
+			wantKeys:   1,
+			wantActive: "new",
+			wantGone:   []string{"old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for kid, age := range tt.existing {
+				writeAgedKey(t, dir, kid, age)
+			}
+
+			env := viper.New()
+			env.Set(constants.JWTKeysDir, dir)
+			env.Set(constants.JWTKeyRotationInterval, rotation.String())
+			keys := NewKeyManager(config.NewConfiguration(config.NewAppConfig(env)))
+
+			require.NoError(t, keys.Load())
+
+			assert.Len(t, keys.JWKS().Keys, tt.wantKeys)
+			active, err := keys.SigningKey()
+			require.NoError(t, err)
+			if tt.wantActive != "" {
+				assert.Equal(t, tt.wantActive, active.ID)
+			} else {
+				assert.NotContains(t, tt.existing, active.ID)
+			}
+			for _, kid := range tt.wantGone {
+				_, _, ok := keys.PublicKey(kid)
+				assert.False(t, ok)
+				assert.NoFileExists(t, filepath.Join(dir, kid+keyFileExt))
+			}
+		})
+	}
+}
+
+func Test_keyManager_VerifiesBySigningKeyID(t *testing.T) {
+	for _, algorithm := range []string{constants.SigningAlgRS256, constants.SigningAlgEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			keys := newTestKeyManager(t, algorithm)
+			signingKey, err := keys.SigningKey()
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, signingKey.Algorithm)
+
+			claims := utils.NewTokenClaims("asif@example.com", time.Now().UTC().Unix())
+			claims.UserID = 7
+			token, err := utils.GenerateTokenWithSigningKey(claims, signingKey, time.Now().UTC().Add(time.Hour).Unix())
+			require.NoError(t, err)
+
+			got, err := utils.ValidateTokenClaims(token, "", utils.WithKeyResolver(keys))
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), got.UserID)
+
+			// another keyset does not know the kid
+			_, err = utils.ValidateTokenClaims(token, "", utils.WithKeyResolver(newTestKeyManager(t, algorithm)))
+			assert.EqualError(t, err, constants.TokenUnknownKey)
+
+			// asymmetric tokens are refused when no key resolver is configured
+			_, err = utils.ValidateTokenClaims(token, "secret")
+			assert.EqualError(t, err, constants.TokenUnExpectedSigningMethod)
+		})
+	}
+}
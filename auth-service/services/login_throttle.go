@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+)
+
+// LoginThrottle protects password logins against brute force. Failed logins are counted per account
+// and per client IP; every failure delays the next attempt exponentially and reaching the threshold
+// locks the account or blocks the IP for a while.
+type LoginThrottle interface {
+	Check(ctx context.Context, email, ip string) error
+	RecordFailure(ctx context.Context, email, ip string) error
+	RecordSuccess(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+	Run(ctx context.Context)
+}
+
+// loginThrottle is an implementation of LoginThrottle
+type loginThrottle struct {
+	repo repositories.LoginAttemptRepository
+	conf config.Configuration
+}
+
+// NewLoginThrottle returns a new instance of the login throttle
+func NewLoginThrottle(repo repositories.LoginAttemptRepository, conf config.Configuration) LoginThrottle {
+	return &loginThrottle{
+		repo: repo,
+		conf: conf,
+	}
+}
+
+// Check returns a *models.LoginThrottledError when the account or the IP may not try to log in yet
+func (l loginThrottle) Check(ctx context.Context, email, ip string) error {
+	appConf := l.conf.AppConfig()
+	now := time.Now().UTC()
+
+	account, err := l.repo.Get(ctx, accountAttemptKey(email))
+	if err != nil {
+		return err
+	}
+	if now.Before(account.LockedUntil) {
+		if account.Failures >= appConf.LoginLockoutThreshold() {
+			return models.NewLoginThrottledError(http.StatusLocked, constants.ErrAccountLocked, account.LockedUntil.Sub(now))
+		}
+		return models.NewLoginThrottledError(http.StatusTooManyRequests, constants.ErrTooManyLoginAttempts, account.LockedUntil.Sub(now))
+	}
+
+	if ip == "" {
+		return nil
+	}
+	client, err := l.repo.Get(ctx, ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if now.Before(client.LockedUntil) {
+		return models.NewLoginThrottledError(http.StatusTooManyRequests, constants.ErrTooManyLoginAttempts, client.LockedUntil.Sub(now))
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login of the account and the IP and delays their next attempt.
+// Unknown emails are counted too, so lockouts do not reveal which accounts exist.
+func (l loginThrottle) RecordFailure(ctx context.Context, email, ip string) error {
+	appConf := l.conf.AppConfig()
+
+	if err := l.recordFailure(ctx, accountAttemptKey(email), appConf.LoginLockoutThreshold()); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return l.recordFailure(ctx, ipAttemptKey(ip), appConf.LoginIPThreshold())
+}
+
+// RecordSuccess forgets the failed logins of an account. Failures from the IP are kept, so
+// logging in to an own account does not reset the budget for guessing other passwords.
+func (l loginThrottle) RecordSuccess(ctx context.Context, email string) error {
+	return l.repo.Delete(ctx, accountAttemptKey(email))
+}
+
+// Unlock lifts the lockout of an account
+func (l loginThrottle) Unlock(ctx context.Context, email string) error {
+	return l.repo.Delete(ctx, accountAttemptKey(email))
+}
+
+// Run periodically deletes counters without recent failures until ctx is cancelled
+func (l loginThrottle) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.LoginAttemptPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			before := time.Now().UTC().Add(-l.conf.AppConfig().LoginLockoutDuration())
+			if _, err := l.repo.DeleteStale(ctx, before); err != nil {
+				log.Println("error while pruning login attempts", err.Error())
+			}
+		}
+	}
+}
+
+// recordFailure counts a failure of key and locks it for the backoff delay
+func (l loginThrottle) recordFailure(ctx context.Context, key string, threshold int) error {
+	appConf := l.conf.AppConfig()
+	now := time.Now().UTC()
+
+	attempt, err := l.repo.RecordFailure(ctx, key, now, now.Add(-appConf.LoginLockoutDuration()))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures >= threshold {
+		log.Printf("locking %s after %d failed logins", key, attempt.Failures)
+	}
+	return l.repo.Lock(ctx, key, now.Add(loginBackoff(attempt.Failures, threshold, appConf.LoginBackoffBase(), appConf.LoginLockoutDuration())))
+}
+
+// loginBackoff returns how long to wait after the given number of failures: base doubled for
+// every failure after the first, and the full lockout once the threshold is reached
+func loginBackoff(failures, threshold int, base, lockout time.Duration) time.Duration {
+	if failures >= threshold {
+		return lockout
+	}
+	delay := base
+	for i := 1; i < failures && delay < lockout; i++ {
+		delay *= 2
+	}
+	return min(delay, lockout)
+}
+
+// accountAttemptKey returns the counter key of an account; emails are compared case-insensitively
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// ipAttemptKey returns the counter key of a client IP
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestLoginThrottle returns a throttle with in-memory counters that locks accounts after three
+// failures and IPs after five
+func newTestLoginThrottle() LoginThrottle {
+	env := viper.New()
+	env.Set(constants.LoginLockoutThreshold, 3)
+	env.Set(constants.LoginIPThreshold, 5)
+	return NewLoginThrottle(repositories.NewMemoryLoginAttemptRepository(), config.NewConfiguration(config.NewAppConfig(env)))
+}
+
+// throttledError returns err as a *models.LoginThrottledError, failing the test if it is not one
+func throttledError(t *testing.T, err error) *models.LoginThrottledError {
+	t.Helper()
+	var throttled *models.LoginThrottledError
+	require.True(t, errors.As(err, &throttled), "expected a LoginThrottledError, got %v", err)
+	return throttled
+}
+
+func Test_loginBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: time.Second},
+		{failures: 2, want: 2 * time.Second},
+		{failures: 4, want: 8 * time.Second},
+		{failures: 9, want: 256 * time.Second},
+		{failures: 15, want: 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		got := loginBackoff(tt.failures, 20, time.Second, 15*time.Minute)
+		assert.Equal(t, tt.want, got, "failures = %d", tt.failures)
+	}
+	assert.Equal(t, 15*time.Minute, loginBackoff(3, 3, time.Second, 15*time.Minute), "reaching the threshold locks")
+}
+
+func Test_loginThrottle(t *testing.T) {
+	ctx := context.Background()
+	const email = "asif@example.com"
+	const ip = "203.0.113.7"
+
+	t.Run("failures back off and then lock the account", func(t *testing.T) {
+		throttle := newTestLoginThrottle()
+		require.NoError(t, throttle.Check(ctx, email, ip))
+
+		require.NoError(t, throttle.RecordFailure(ctx, email, ip))
+		throttled := throttledError(t, throttle.Check(ctx, email, ip))
+		assert.Equal(t, http.StatusTooManyRequests, throttled.Status)
+		assert.InDelta(t, time.Second, throttled.RetryAfter, float64(100*time.Millisecond))
+
+		require.NoError(t, throttle.RecordFailure(ctx, email, ip))
+		require.NoError(t, throttle.RecordFailure(ctx, email, ip))
+		throttled = throttledError(t, throttle.Check(ctx, "ASIF@example.com", "198.51.100.1"))
+		assert.Equal(t, http.StatusLocked, throttled.Status)
+		assert.Equal(t, constants.ErrAccountLocked, throttled.Error())
+		assert.InDelta(t, constants.DefaultLoginLockoutDuration, throttled.RetryAfter, float64(time.Second))
+	})
+
+	t.Run("unlock lifts the lockout", func(t *testing.T) {
+		throttle := newTestLoginThrottle()
+		for range 3 {
+			require.NoError(t, throttle.RecordFailure(ctx, email, ""))
+		}
+		require.Error(t, throttle.Check(ctx, email, ""))
+
+		require.NoError(t, throttle.Unlock(ctx, email))
+		assert.NoError(t, throttle.Check(ctx, email, ""))
+	})
+
+	t.Run("failures across accounts block the IP", func(t *testing.T) {
+		throttle := newTestLoginThrottle()
+		for _, victim := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+			require.NoError(t, throttle.RecordFailure(ctx, victim, ip))
+		}
+
+		throttled := throttledError(t, throttle.Check(ctx, "f@example.com", ip))
+		assert.Equal(t, http.StatusTooManyRequests, throttled.Status)
+		assert.NoError(t, throttle.Check(ctx, "f@example.com", "198.51.100.1"))
+	})
+
+	t.Run("a successful login resets the account but not the IP", func(t *testing.T) {
+		throttle := newTestLoginThrottle()
+		require.NoError(t, throttle.RecordFailure(ctx, email, ip))
+		require.NoError(t, throttle.RecordSuccess(ctx, email))
+
+		assert.NoError(t, throttle.Check(ctx, email, ""))
+		assert.Error(t, throttle.Check(ctx, email, ip))
+	})
+}
+
+func Test_userService_AuthenticateThrottling(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := models.User{ID: 7, Email: "asif@example.com", Password: string(hash)}
+	ctx := utils.ContextWithClientIP(context.Background(), "203.0.113.7")
+
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepo.On("GetByUserEmail", mock.Anything, "nobody@example.com").Return(models.User{}, nil)
+	conf := newTestConfiguration()
+	svc := NewUserService(userRepo, nil, nil, newTestLoginThrottle(), NewPasswordHasher(conf), NewPasswordPolicy(conf), nil, nil, &recordingAuditService{}, conf)
+
+	// wrong passwords and unknown emails are both a 401, never a server error
+	status, _, err := svc.Authenticate(ctx, "nobody@example.com", "guess")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, constants.ErrInvalidEmailOrPass)
+
+	status, _, err = svc.Authenticate(context.Background(), user.Email, "wrong password")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, constants.ErrInvalidEmailOrPass)
+
+	// the backoff refuses even the right password
+	status, _, err = svc.Authenticate(context.Background(), user.Email, "correct password")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	throttledError(t, err)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// MagicLinkService lets users log in without a password through single-use links mailed to them.
+// A link only works in the browser that asked for it, which holds the matching nonce cookie, so a
+// link that leaks from the mailbox cannot be used elsewhere.
+type MagicLinkService interface {
+	RequestLink(ctx context.Context, email string) (int, models.MagicLinkNonce, error)
+	CompleteLogin(ctx context.Context, token, nonce string) (int, models.LoginResponse, error)
+}
+
+// magicLinkService is an implementation of MagicLinkService
+type magicLinkService struct {
+	userRepo  repositories.UserRepository
+	magicRepo repositories.MagicLinkRepository
+	tokenSvc  TokenService
+	mfaSvc    MFAService
+	mailer    Mailer
+	audit     AuditService
+	conf      config.Configuration
+}
+
+// NewMagicLinkService returns a new instance of the magic link service
+func NewMagicLinkService(
+	userRepo repositories.UserRepository,
+	magicRepo repositories.MagicLinkRepository,
+	tokenSvc TokenService,
+	mfaSvc MFAService,
+	mailer Mailer,
+	audit AuditService,
+	conf config.Configuration,
+) MagicLinkService {
+	return &magicLinkService{
+		userRepo:  userRepo,
+		magicRepo: magicRepo,
+		tokenSvc:  tokenSvc,
+		mfaSvc:    mfaSvc,
+		mailer:    mailer,
+		audit:     audit,
+		conf:      conf,
+	}
+}
+
+// RequestLink mails a sign-in link to the user with the given email and returns the nonce the
+// requesting browser must present with it. Like ForgotPassword, the result is the same whether or
+// not the email is registered, and failures after the user was found are only logged. Accounts that
+// were sent MagicLinkRateLimit links within MagicLinkRateWindow get no more mail until the window
+// moves on; the response does not change, so the limit does not reveal the account either.
+func (m magicLinkService) RequestLink(ctx context.Context, email string) (int, models.MagicLinkNonce, error) {
+	appConf := m.conf.AppConfig()
+
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return http.StatusInternalServerError, models.MagicLinkNonce{}, err
+	}
+	now := time.Now().UTC()
+	cookie := models.MagicLinkNonce{Value: nonce, ExpiresAt: now.Add(appConf.MagicLinkTTL())}
+
+	user, err := m.userRepo.GetByUserEmail(ctx, email)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, models.MagicLinkNonce{}, err
+	}
+	if user.ID == 0 || user.Disabled() {
+		return http.StatusAccepted, cookie, nil
+	}
+
+	sent, err := m.magicRepo.CountCreatedSince(ctx, user.ID, now.Add(-appConf.MagicLinkRateWindow()))
+	if err != nil {
+		log.Printf("error while counting sign-in links of user %d: %v", user.ID, err)
+		return http.StatusAccepted, cookie, nil
+	}
+	if sent >= appConf.MagicLinkRateLimit() {
+		log.Printf("sign-in link limit reached for user %d", user.ID)
+		return http.StatusAccepted, cookie, nil
+	}
+
+	if err := m.sendLink(ctx, user, nonce, cookie.ExpiresAt); err != nil {
+		log.Printf("error while sending sign-in link to user %d: %v", user.ID, err)
+	}
+	return http.StatusAccepted, cookie, nil
+}
+
+// CompleteLogin exchanges the token of a sign-in link for tokens, or for an mfa_token when the user
+// has two-factor authentication. The nonce must be the one handed out with the link; a link opened
+// in another browser is refused without being used up. Following the link proves the user owns the
+// address, so an unverified email is verified on the way.
+func (m magicLinkService) CompleteLogin(ctx context.Context, token, nonce string) (int, models.LoginResponse, error) {
+	errInvalid := errors.New(constants.ErrInvalidMagicLink)
+	if token == "" {
+		return http.StatusUnauthorized, models.LoginResponse{}, errInvalid
+	}
+
+	stored, err := m.magicRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if stored.ID == 0 || stored.UsedAt != nil || time.Now().UTC().After(stored.ExpiresAt) {
+		return http.StatusUnauthorized, models.LoginResponse{}, errInvalid
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(nonce)), []byte(stored.NonceHash)) != 1 {
+		return http.StatusForbidden, models.LoginResponse{}, errors.New(constants.ErrMagicLinkOtherBrowser)
+	}
+
+	// links are single use; losing this race means a concurrent request used it
+	marked, err := m.magicRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		log.Println("error while consuming sign-in token", err.Error())
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if !marked {
+		return http.StatusUnauthorized, models.LoginResponse{}, errInvalid
+	}
+
+	user, err := m.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if user.ID == 0 {
+		return http.StatusUnauthorized, models.LoginResponse{}, errInvalid
+	}
+	if user.Disabled() {
+		m.logLogin(ctx, constants.EventLoginFailed, user, constants.EventDetailReason, constants.LoginFailureDisabled)
+		return http.StatusForbidden, models.LoginResponse{}, errors.New(constants.ErrAccountDisabled)
+	}
+	if user.PasswordResetRequired {
+		m.logLogin(ctx, constants.EventLoginFailed, user, constants.EventDetailReason, constants.LoginFailureResetRequired)
+		return http.StatusForbidden, models.LoginResponse{}, errors.New(constants.ErrPasswordResetRequired)
+	}
+
+	if !user.EmailVerified() {
+		if err := m.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+			log.Println("error while marking email verified", err.Error())
+			return http.StatusInternalServerError, models.LoginResponse{}, err
+		}
+		verifiedAt := time.Now().UTC()
+		user.EmailVerifiedAt = &verifiedAt
+	}
+
+	mfaEnabled, err := m.mfaSvc.Enabled(ctx, user.ID)
+	if err != nil {
+		log.Println("error while checking mfa", err.Error())
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if mfaEnabled {
+		mfaToken, expiresAt, err := m.mfaSvc.StartChallenge(ctx, user.ID)
+		if err != nil {
+			log.Println("error while starting mfa challenge", err.Error())
+			return http.StatusInternalServerError, models.LoginResponse{}, err
+		}
+		return http.StatusOK, models.LoginResponse{
+			Email:       user.Email,
+			ExpiresAt:   expiresAt.Unix(),
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	loginResp, err := m.tokenSvc.IssueTokens(ctx, user, models.TokenGrant{})
+	if err != nil {
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+
+	m.logLogin(ctx, constants.EventLoginSucceeded, user, constants.EventDetailMethod, constants.LoginMethodMagicLink)
+	log.Printf("user logged in with a sign-in link: %s", user.Email)
+	return http.StatusOK, loginResp, nil
+}
+
+// logLogin records a login with a sign-in link in the audit log
+func (m magicLinkService) logLogin(ctx context.Context, eventType string, user models.User, detail, value string) {
+	m.audit.Log(ctx, models.AuthEvent{
+		Type:    eventType,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Details: map[string]string{detail: value},
+	})
+}
+
+// sendLink replaces any outstanding sign-in link of the user with a new one bound to nonce and mails it
+func (m magicLinkService) sendLink(ctx context.Context, user models.User, nonce string, expiresAt time.Time) error {
+	appConf := m.conf.AppConfig()
+
+	if err := m.magicRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	stored := models.MagicLinkToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		NonceHash: utils.HashToken(nonce),
+		ExpiresAt: expiresAt,
+	}
+	if err := m.magicRepo.Create(ctx, &stored); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(appConf.Issuer() + constants.PathMagicLinkCallback)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return m.mailer.Send(ctx, models.Email{
+		From:    appConf.MailFrom(),
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Someone asked to sign in to your account without a password.\r\n\r\n"+
+			"Open this link within %s, in the browser you asked from, to sign in:\r\n%s\r\n\r\n"+
+			"If this was not you, ignore this email; nobody can sign in without the link.",
+			appConf.MagicLinkTTL(), link.String()),
+	})
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_magicLinkService_RequestLink(t *testing.T) {
+	user := models.User{ID: 7, Email: "reader@example.com"}
+
+	t.Run("unknown email gets a nonce and no mail", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByUserEmail", mock.Anything, "nobody@example.com").Return(models.User{}, nil)
+		mailer := &recordingMailer{}
+		svc := NewMagicLinkService(userRepo, mocks.NewMagicLinkRepository(t), nil, nil, mailer, &recordingAuditService{}, newTestConfiguration())
+
+		status, nonce, err := svc.RequestLink(context.Background(), "nobody@example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.NotEmpty(t, nonce.Value)
+		assert.Empty(t, mailer.sent)
+	})
+
+	t.Run("link is bound to the nonce of the requesting browser", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+		magicRepo := mocks.NewMagicLinkRepository(t)
+		magicRepo.On("CountCreatedSince", mock.Anything, user.ID, mock.Anything).Return(0, nil)
+		magicRepo.On("InvalidateForUser", mock.Anything, user.ID).Return(nil)
+		var stored *models.MagicLinkToken
+		magicRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*models.MagicLinkToken)
+		}).Return(nil)
+		mailer := &recordingMailer{}
+		svc := NewMagicLinkService(userRepo, magicRepo, nil, nil, mailer, &recordingAuditService{}, newTestConfiguration())
+
+		status, nonce, err := svc.RequestLink(context.Background(), user.Email)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		require.NotNil(t, stored)
+		assert.Equal(t, utils.HashToken(nonce.Value), stored.NonceHash)
+		assert.WithinDuration(t, time.Now().UTC().Add(constants.DefaultMagicLinkTTL), stored.ExpiresAt, time.Minute)
+
+		require.Len(t, mailer.sent, 1)
+		assert.Equal(t, user.Email, mailer.sent[0].To)
+		start := strings.Index(mailer.sent[0].Body, constants.DefaultTokenIssuer+constants.PathMagicLinkCallback)
+		require.GreaterOrEqual(t, start, 0)
+		link, err := url.Parse(strings.Fields(mailer.sent[0].Body[start:])[0])
+		require.NoError(t, err)
+		assert.Equal(t, stored.TokenHash, utils.HashToken(link.Query().Get("token")))
+	})
+
+	t.Run("no more mail once the limit is reached", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+		magicRepo := mocks.NewMagicLinkRepository(t)
+		magicRepo.On("CountCreatedSince", mock.Anything, user.ID, mock.MatchedBy(func(since time.Time) bool {
+			return since.Before(time.Now().UTC().Add(-constants.DefaultMagicLinkRateWindow + time.Minute))
+		})).Return(constants.DefaultMagicLinkRateLimit, nil)
+		mailer := &recordingMailer{}
+		svc := NewMagicLinkService(userRepo, magicRepo, nil, nil, mailer, &recordingAuditService{}, newTestConfiguration())
+
+		status, nonce, err := svc.RequestLink(context.Background(), user.Email)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.NotEmpty(t, nonce.Value)
+		assert.Empty(t, mailer.sent)
+	})
+}
+
+func Test_magicLinkService_CompleteLogin(t *testing.T) {
+	const token, nonce = "link-token", "browser-nonce"
+	now := time.Now().UTC()
+	live := models.MagicLinkToken{ID: 3, UserID: 7, TokenHash: utils.HashToken(token), NonceHash: utils.HashToken(nonce), ExpiresAt: now.Add(time.Minute)}
+	expired := live
+	expired.ExpiresAt = now.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		stored      models.MagicLinkToken
+		nonce       string
+		user        models.User
+		mfa         models.UserMFA
+		wantStatus  int
+		wantErr     string
+		wantConsume bool
+		wantVerify  bool
+	}{
+		{
+			name:       "unknown link",
+			stored:     models.MagicLinkToken{},
+			nonce:      nonce,
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrInvalidMagicLink,
+		},
+		{
+			name:       "expired link",
+			stored:     expired,
+			nonce:      nonce,
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrInvalidMagicLink,
+		},
+		{
+			name:       "link opened in another browser is not used up",
+			stored:     live,
+			nonce:      "other-nonce",
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrMagicLinkOtherBrowser,
+		},
+		{
+			name:       "link opened without the nonce cookie",
+			stored:     live,
+			wantStatus: http.StatusForbidden,
+			wantErr:    constants.ErrMagicLinkOtherBrowser,
+		},
+		{
+			name:        "disabled user",
+			stored:      live,
+			nonce:       nonce,
+			user:        models.User{ID: 7, Email: "reader@example.com", EmailVerifiedAt: &now, DisabledAt: &now},
+			wantStatus:  http.StatusForbidden,
+			wantErr:     constants.ErrAccountDisabled,
+			wantConsume: true,
+		},
+		{
+			name:        "unverified user is verified and logged in",
+			stored:      live,
+			nonce:       nonce,
+			user:        models.User{ID: 7, Email: "reader@example.com"},
+			wantStatus:  http.StatusOK,
+			wantConsume: true,
+			wantVerify:  true,
+		},
+		{
+			name:        "user with two-factor authentication gets an mfa_token",
+			stored:      live,
+			nonce:       nonce,
+			user:        models.User{ID: 7, Email: "reader@example.com", EmailVerifiedAt: &now},
+			mfa:         models.UserMFA{UserID: 7, ConfirmedAt: &now},
+			wantStatus:  http.StatusOK,
+			wantConsume: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			magicRepo := mocks.NewMagicLinkRepository(t)
+			magicRepo.On("GetByHash", mock.Anything, utils.HashToken(token)).Return(tt.stored, nil)
+			if tt.wantConsume {
+				magicRepo.On("MarkUsed", mock.Anything, int64(3)).Return(true, nil)
+			}
+			userRepo := mocks.NewUserRepository(t)
+			userRepo.On("GetByID", mock.Anything, int64(7)).Return(tt.user, nil).Maybe()
+			if tt.wantVerify {
+				userRepo.On("MarkEmailVerified", mock.Anything, int64(7)).Return(nil)
+			}
+			mfaRepo := mocks.NewMFARepository(t)
+			mfaRepo.On("GetByUserID", mock.Anything, int64(7)).Return(tt.mfa, nil).Maybe()
+			mfaRepo.On("CreateChallenge", mock.Anything, mock.Anything).Return(nil).Maybe()
+			refreshRepo := mocks.NewRefreshTokenRepository(t)
+			refreshRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			conf := newTestConfiguration()
+			tokenSvc := NewTokenService(userRepo, refreshRepo, newTestRoleRepository(t, nil, nil), newTestSessionRepository(t), nil, nil, newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{}, conf)
+			svc := NewMagicLinkService(userRepo, magicRepo, tokenSvc, NewMFAService(userRepo, mfaRepo, conf), &recordingMailer{}, &recordingAuditService{}, conf)
+
+			status, resp, err := svc.CompleteLogin(context.Background(), token, tt.nonce)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.mfa.Enabled() {
+				assert.True(t, resp.MFARequired)
+				assert.NotEmpty(t, resp.MFAToken)
+				assert.Empty(t, resp.AccessToken)
+				return
+			}
+			assert.NotEmpty(t, resp.AccessToken)
+			// verified on the way, so the token is not limited to blog:read
+			claims, err := tokenSvc.ValidateAccessToken(context.Background(), resp.AccessToken)
+			require.NoError(t, err)
+			assert.Empty(t, claims.Scope)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/utils"
+)
+
+// Mailer delivers emails to users. Implementations for real providers can be plugged in through
+// NewMailer; the bundled ones are meant for local development.
+type Mailer interface {
+	Send(ctx context.Context, email models.Email) error
+}
+
+// NewMailer returns the mailer selected by MAILER_DRIVER, falling back to stdout for unknown drivers
+func NewMailer(conf config.Configuration) Mailer {
+	appConf := conf.AppConfig()
+	switch appConf.MailerDriver() {
+	case constants.MailerDriverFile:
+		return &fileMailer{dir: appConf.MailOutboxDir()}
+	case constants.MailerDriverStdout:
+		return &stdoutMailer{}
+	default:
+		log.Printf("unknown mailer driver %q, printing emails to stdout", appConf.MailerDriver())
+		return &stdoutMailer{}
+	}
+}
+
+// stdoutMailer prints emails to standard output
+type stdoutMailer struct{}
+
+// Send prints the email
+func (m *stdoutMailer) Send(_ context.Context, email models.Email) error {
+	_, err := fmt.Fprint(os.Stdout, formatEmail(email))
+	return err
+}
+
+// fileMailer writes every email to its own file in dir, so they can be opened like an inbox
+type fileMailer struct {
+	dir string
+}
+
+// Send writes the email to a new .eml file
+func (m *fileMailer) Send(_ context.Context, email models.Email) error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+	suffix, err := utils.GenerateRandomID()
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405Z"), suffix[:8])
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(formatEmail(email)), 0o600)
+}
+
+// formatEmail renders an email as a minimal RFC 5322 message
+func formatEmail(email models.Email) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", email.From)
+	fmt.Fprintf(&b, "To: %s\r\n", email.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", email.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(email.Body)
+	b.WriteString("\r\n")
+	return b.String()
+}
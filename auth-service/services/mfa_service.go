@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// recoveryCodeEncoding spells recovery codes in lower case base32, which avoids look-alike digits
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// MFAService manages TOTP two-factor authentication. Enroll creates a secret that only takes
+// effect once Confirm was called with a code from the authenticator app, which also returns the
+// first set of one-time recovery codes. Logins of enrolled users need a second step: StartChallenge
+// returns an mfa_token that CompleteChallenge exchanges together with a code.
+type MFAService interface {
+	Enroll(ctx context.Context, userID int64) (int, models.MFAEnrollment, error)
+	QRCode(ctx context.Context, userID int64) (int, []byte, error)
+	Confirm(ctx context.Context, userID int64, code string) (int, models.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID int64, code string) (int, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (int, models.RecoveryCodesResponse, error)
+	Enabled(ctx context.Context, userID int64) (bool, error)
+	VerifyCode(ctx context.Context, userID int64, code string) (bool, error)
+	StartChallenge(ctx context.Context, userID int64) (string, time.Time, error)
+	CompleteChallenge(ctx context.Context, token, code string) (int, int64, error)
+}
+
+// mfaService is an implementation of MFAService
+type mfaService struct {
+	userRepo repositories.UserRepository
+	mfaRepo  repositories.MFARepository
+	conf     config.Configuration
+}
+
+// NewMFAService returns a new instance of the two-factor authentication service
+func NewMFAService(userRepo repositories.UserRepository, mfaRepo repositories.MFARepository, conf config.Configuration) MFAService {
+	return &mfaService{
+		userRepo: userRepo,
+		mfaRepo:  mfaRepo,
+		conf:     conf,
+	}
+}
+
+// Enroll creates a new TOTP secret for the user, replacing an unconfirmed one
+func (m mfaService) Enroll(ctx context.Context, userID int64) (int, models.MFAEnrollment, error) {
+	mfa, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return http.StatusInternalServerError, models.MFAEnrollment{}, err
+	}
+	if mfa.Enabled() {
+		return http.StatusConflict, models.MFAEnrollment{}, errors.New(constants.ErrMFAAlreadyEnabled)
+	}
+
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return http.StatusInternalServerError, models.MFAEnrollment{}, err
+	}
+	if user.ID == 0 {
+		return http.StatusNotFound, models.MFAEnrollment{}, errors.New(constants.ErrUserNotFound)
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return http.StatusInternalServerError, models.MFAEnrollment{}, err
+	}
+	if err := m.mfaRepo.Upsert(ctx, userID, secret); err != nil {
+		log.Println("error while storing mfa secret", err.Error())
+		return http.StatusInternalServerError, models.MFAEnrollment{}, err
+	}
+
+	return http.StatusOK, models.MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(m.conf.AppConfig().MFAIssuer(), user.Email, secret),
+	}, nil
+}
+
+// QRCode renders the otpauth:// URI of a pending enrollment as a PNG. Confirmed secrets are never
+// shown again.
+func (m mfaService) QRCode(ctx context.Context, userID int64) (int, []byte, error) {
+	mfa, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if mfa.UserID == 0 {
+		return http.StatusBadRequest, nil, errors.New(constants.ErrMFANotEnrolled)
+	}
+	if mfa.Enabled() {
+		return http.StatusConflict, nil, errors.New(constants.ErrMFAAlreadyEnabled)
+	}
+
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	png, err := qrcode.Encode(utils.TOTPURI(m.conf.AppConfig().MFAIssuer(), user.Email, mfa.TOTPSecret), qrcode.Medium, constants.MFAQRCodeSize)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, png, nil
+}
+
+// Confirm enables two-factor authentication once the user proved their app generates valid codes
+func (m mfaService) Confirm(ctx context.Context, userID int64, code string) (int, models.RecoveryCodesResponse, error) {
+	mfa, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return http.StatusInternalServerError, models.RecoveryCodesResponse{}, err
+	}
+	if mfa.UserID == 0 {
+		return http.StatusBadRequest, models.RecoveryCodesResponse{}, errors.New(constants.ErrMFANotEnrolled)
+	}
+	if mfa.Enabled() {
+		return http.StatusConflict, models.RecoveryCodesResponse{}, errors.New(constants.ErrMFAAlreadyEnabled)
+	}
+
+	step, ok := utils.ValidateTOTP(mfa.TOTPSecret, code, time.Now().UTC())
+	if !ok {
+		return http.StatusBadRequest, models.RecoveryCodesResponse{}, errors.New(constants.ErrInvalidMFACode)
+	}
+	if err := m.mfaRepo.Confirm(ctx, userID, step); err != nil {
+		log.Println("error while confirming mfa", err.Error())
+		return http.StatusInternalServerError, models.RecoveryCodesResponse{}, err
+	}
+
+	log.Printf("enabled two-factor authentication for user %d", userID)
+	return m.newRecoveryCodes(ctx, userID)
+}
+
+// Disable turns two-factor authentication off after checking a current code or a recovery code
+func (m mfaService) Disable(ctx context.Context, userID int64, code string) (int, error) {
+	if status, err := m.requireCode(ctx, userID, code); err != nil {
+		return status, err
+	}
+
+	if err := m.mfaRepo.Delete(ctx, userID); err != nil {
+		log.Println("error while disabling mfa", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	log.Printf("disabled two-factor authentication for user %d", userID)
+	return http.StatusOK, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user after checking a code
+func (m mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (int, models.RecoveryCodesResponse, error) {
+	if status, err := m.requireCode(ctx, userID, code); err != nil {
+		return status, models.RecoveryCodesResponse{}, err
+	}
+	return m.newRecoveryCodes(ctx, userID)
+}
+
+// Enabled reports whether the user has confirmed two-factor authentication
+func (m mfaService) Enabled(ctx context.Context, userID int64) (bool, error) {
+	mfa, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa.Enabled(), nil
+}
+
+// VerifyCode checks a TOTP code or a recovery code of the user. Each TOTP time step and each
+// recovery code is only accepted once.
+func (m mfaService) VerifyCode(ctx context.Context, userID int64, code string) (bool, error) {
+	mfa, err := m.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil || !mfa.Enabled() {
+		return false, err
+	}
+
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		step, ok := utils.ValidateTOTP(mfa.TOTPSecret, code, time.Now().UTC())
+		if !ok {
+			return false, nil
+		}
+		return m.mfaRepo.UseStep(ctx, userID, step)
+	}
+
+	used, err := m.mfaRepo.UseRecoveryCode(ctx, userID, utils.HashToken(normalizeRecoveryCode(code)))
+	if used {
+		log.Printf("user %d used a recovery code", userID)
+	}
+	return used, err
+}
+
+// StartChallenge creates the mfa_token a password login of an enrolled user returns
+func (m mfaService) StartChallenge(ctx context.Context, userID int64) (string, time.Time, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	challenge := models.MFAChallenge{
+		UserID:    userID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(m.conf.AppConfig().MFAChallengeTTL()),
+	}
+	if err := m.mfaRepo.CreateChallenge(ctx, &challenge); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, challenge.ExpiresAt, nil
+}
+
+// CompleteChallenge checks the code for an mfa_token and returns the user it was issued to. Every
+// token can be completed once and tried constants.MFAChallengeMaxAttempts times. For a wrong code
+// the user is returned along with the error so that the failure can be counted.
+func (m mfaService) CompleteChallenge(ctx context.Context, token, code string) (int, int64, error) {
+	errInvalidToken := errors.New(constants.ErrInvalidMFAToken)
+
+	challenge, err := m.mfaRepo.GetChallengeByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return http.StatusInternalServerError, 0, err
+	}
+	if challenge.ID == 0 || challenge.UsedAt != nil || time.Now().UTC().After(challenge.ExpiresAt) {
+		return http.StatusUnauthorized, 0, errInvalidToken
+	}
+
+	counted, err := m.mfaRepo.IncrementChallengeAttempts(ctx, challenge.ID, constants.MFAChallengeMaxAttempts)
+	if err != nil {
+		return http.StatusInternalServerError, 0, err
+	}
+	if !counted {
+		return http.StatusUnauthorized, 0, errInvalidToken
+	}
+
+	ok, err := m.VerifyCode(ctx, challenge.UserID, code)
+	if err != nil {
+		return http.StatusInternalServerError, 0, err
+	}
+	if !ok {
+		return http.StatusUnauthorized, challenge.UserID, errors.New(constants.ErrInvalidMFACode)
+	}
+
+	marked, err := m.mfaRepo.MarkChallengeUsed(ctx, challenge.ID)
+	if err != nil {
+		return http.StatusInternalServerError, 0, err
+	}
+	if !marked {
+		return http.StatusUnauthorized, 0, errInvalidToken
+	}
+	return http.StatusOK, challenge.UserID, nil
+}
+
+// requireCode checks that two-factor authentication is enabled and the code is valid
+func (m mfaService) requireCode(ctx context.Context, userID int64, code string) (int, error) {
+	enabled, err := m.Enabled(ctx, userID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !enabled {
+		return http.StatusBadRequest, errors.New(constants.ErrMFANotEnabled)
+	}
+
+	ok, err := m.VerifyCode(ctx, userID, code)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !ok {
+		return http.StatusBadRequest, errors.New(constants.ErrInvalidMFACode)
+	}
+	return http.StatusOK, nil
+}
+
+// newRecoveryCodes replaces the recovery codes of the user; only their hashes are stored
+func (m mfaService) newRecoveryCodes(ctx context.Context, userID int64) (int, models.RecoveryCodesResponse, error) {
+	codes := make([]string, 0, constants.RecoveryCodeCount)
+	hashes := make([]string, 0, constants.RecoveryCodeCount)
+	for range constants.RecoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return http.StatusInternalServerError, models.RecoveryCodesResponse{}, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := m.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		log.Println("error while storing recovery codes", err.Error())
+		return http.StatusInternalServerError, models.RecoveryCodesResponse{}, err
+	}
+	return http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// generateRecoveryCode returns a random code of the form xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", utils.ErrGeneratingToken
+	}
+	code := recoveryCodeEncoding.EncodeToString(b)[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode ignores case, dashes and spaces users may type differently
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+// isTOTPCode reports whether code looks like a TOTP code rather than a recovery code
+func isTOTPCode(code string) bool {
+	if len(code) != constants.TOTPDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMFAService returns an MFAService that finds mfa as the enrollment of every user
+func newTestMFAService(t *testing.T, mfa models.UserMFA) MFAService {
+	mfaRepo := mocks.NewMFARepository(t)
+	mfaRepo.On("GetByUserID", mock.Anything, mock.Anything).Return(mfa, nil).Maybe()
+	return NewMFAService(nil, mfaRepo, newTestConfiguration())
+}
+
+// currentTOTPCode returns the code an authenticator app shows for secret right now
+func currentTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now().UTC()))
+	require.NoError(t, err)
+	return code
+}
+
+func Test_mfaService_Confirm(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now().UTC()
+	pending := models.UserMFA{UserID: 7, TOTPSecret: secret}
+
+	t.Run("a valid code enables mfa and returns recovery codes", func(t *testing.T) {
+		mfaRepo := mocks.NewMFARepository(t)
+		mfaRepo.On("GetByUserID", mock.Anything, int64(7)).Return(pending, nil)
+		mfaRepo.On("Confirm", mock.Anything, int64(7), utils.TOTPStep(time.Now().UTC())).Return(nil)
+		var stored []string
+		mfaRepo.On("ReplaceRecoveryCodes", mock.Anything, int64(7), mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]string)
+		}).Return(nil)
+		svc := NewMFAService(nil, mfaRepo, newTestConfiguration())
+
+		status, resp, err := svc.Confirm(context.Background(), 7, currentTOTPCode(t, secret))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, resp.RecoveryCodes, constants.RecoveryCodeCount)
+		// only hashes are stored, and they match the codes however they are typed
+		require.Len(t, stored, constants.RecoveryCodeCount)
+		assert.Equal(t, utils.HashToken(normalizeRecoveryCode(resp.RecoveryCodes[0])), stored[0])
+		assert.NotContains(t, stored, resp.RecoveryCodes[0])
+	})
+
+	tests := []struct {
+		name       string
+		mfa        models.UserMFA
+		code       string
+		wantStatus int
+		wantErr    string
+	}{
+		{name: "wrong code", mfa: pending, code: "wrong", wantStatus: http.StatusBadRequest, wantErr: constants.ErrInvalidMFACode},
+		{name: "not enrolled", mfa: models.UserMFA{}, wantStatus: http.StatusBadRequest, wantErr: constants.ErrMFANotEnrolled},
+		{
+			name:       "already enabled",
+			mfa:        models.UserMFA{UserID: 7, TOTPSecret: secret, ConfirmedAt: &confirmedAt},
+			wantStatus: http.StatusConflict,
+			wantErr:    constants.ErrMFAAlreadyEnabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, err := newTestMFAService(t, tt.mfa).Confirm(context.Background(), 7, tt.code)
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_mfaService_VerifyCode(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now().UTC()
+	enabled := models.UserMFA{UserID: 7, TOTPSecret: secret, ConfirmedAt: &confirmedAt}
+
+	t.Run("a totp code is accepted once", func(t *testing.T) {
+		mfaRepo := mocks.NewMFARepository(t)
+		mfaRepo.On("GetByUserID", mock.Anything, int64(7)).Return(enabled, nil)
+		mfaRepo.On("UseStep", mock.Anything, int64(7), mock.Anything).Return(true, nil).Once()
+		mfaRepo.On("UseStep", mock.Anything, int64(7), mock.Anything).Return(false, nil).Once()
+		svc := NewMFAService(nil, mfaRepo, newTestConfiguration())
+		code := currentTOTPCode(t, secret)
+
+		ok, err := svc.VerifyCode(context.Background(), 7, code)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = svc.VerifyCode(context.Background(), 7, code)
+		require.NoError(t, err)
+		assert.False(t, ok, "replayed code")
+	})
+
+	t.Run("recovery codes ignore case and dashes", func(t *testing.T) {
+		mfaRepo := mocks.NewMFARepository(t)
+		mfaRepo.On("GetByUserID", mock.Anything, int64(7)).Return(enabled, nil)
+		mfaRepo.On("UseRecoveryCode", mock.Anything, int64(7), utils.HashToken("abcdefghij")).Return(true, nil)
+		svc := NewMFAService(nil, mfaRepo, newTestConfiguration())
+
+		ok, err := svc.VerifyCode(context.Background(), 7, " ABCDE-fghij ")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("users without mfa have no valid codes", func(t *testing.T) {
+		ok, err := newTestMFAService(t, models.UserMFA{}).VerifyCode(context.Background(), 7, "123456")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func Test_mfaService_CompleteChallenge(t *testing.T) {
+	const token = "mfa-token"
+	secret, err := utils.GenerateTOTPSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now().UTC()
+	usedAt := time.Now().UTC().Add(-time.Minute)
+	enabled := models.UserMFA{UserID: 7, TOTPSecret: secret, ConfirmedAt: &confirmedAt}
+	valid := models.MFAChallenge{ID: 3, UserID: 7, TokenHash: utils.HashToken(token), ExpiresAt: time.Now().UTC().Add(time.Minute)}
+
+	tests := []struct {
+		name       string
+		stored     models.MFAChallenge
+		counted    bool
+		code       string
+		wantStatus int
+		wantUserID int64
+		wantErr    string
+	}{
+		{name: "valid code completes the challenge", stored: valid, counted: true, code: "totp", wantStatus: http.StatusOK, wantUserID: 7},
+		{name: "unknown token", stored: models.MFAChallenge{}, wantStatus: http.StatusUnauthorized, wantErr: constants.ErrInvalidMFAToken},
+		{
+			name:       "used token",
+			stored:     models.MFAChallenge{ID: 3, UserID: 7, ExpiresAt: valid.ExpiresAt, UsedAt: &usedAt},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrInvalidMFAToken,
+		},
+		{
+			name:       "expired token",
+			stored:     models.MFAChallenge{ID: 3, UserID: 7, ExpiresAt: time.Now().UTC().Add(-time.Second)},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrInvalidMFAToken,
+		},
+		{name: "no attempts left", stored: valid, counted: false, wantStatus: http.StatusUnauthorized, wantErr: constants.ErrInvalidMFAToken},
+		{
+			name:       "wrong code names the user",
+			stored:     valid,
+			counted:    true,
+			code:       "wrong-codes",
+			wantStatus: http.StatusUnauthorized,
+			wantUserID: 7,
+			wantErr:    constants.ErrInvalidMFACode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfaRepo := mocks.NewMFARepository(t)
+			mfaRepo.On("GetChallengeByHash", mock.Anything, utils.HashToken(token)).Return(tt.stored, nil)
+			if tt.stored.ID != 0 && tt.stored.UsedAt == nil && tt.stored.ExpiresAt.After(time.Now()) {
+				mfaRepo.On("IncrementChallengeAttempts", mock.Anything, tt.stored.ID, constants.MFAChallengeMaxAttempts).Return(tt.counted, nil)
+			}
+			if tt.counted {
+				mfaRepo.On("GetByUserID", mock.Anything, int64(7)).Return(enabled, nil)
+			}
+			code := tt.code
+			switch code {
+			case "totp":
+				code = currentTOTPCode(t, secret)
+				mfaRepo.On("UseStep", mock.Anything, int64(7), mock.Anything).Return(true, nil)
+				mfaRepo.On("MarkChallengeUsed", mock.Anything, tt.stored.ID).Return(true, nil)
+			case "wrong-codes":
+				mfaRepo.On("UseRecoveryCode", mock.Anything, int64(7), mock.Anything).Return(false, nil)
+			}
+			svc := NewMFAService(nil, mfaRepo, newTestConfiguration())
+
+			status, userID, err := svc.CompleteChallenge(context.Background(), token, code)
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantUserID, userID)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_userService_LoginMFA(t *testing.T) {
+	hasher := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024)
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	user := models.User{ID: 7, Email: "asif@example.com", Password: hash}
+	secret, err := utils.GenerateTOTPSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now().UTC()
+	enabled := models.UserMFA{UserID: 7, TOTPSecret: secret, ConfirmedAt: &confirmedAt}
+	ctx := context.Background()
+
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mfaRepo := mocks.NewMFARepository(t)
+	mfaRepo.On("GetByUserID", mock.Anything, user.ID).Return(enabled, nil)
+	var challenge models.MFAChallenge
+	mfaRepo.On("CreateChallenge", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		challenge = *args.Get(1).(*models.MFAChallenge)
+		challenge.ID = 3
+	}).Return(nil)
+	mfaRepo.On("GetChallengeByHash", mock.Anything, mock.Anything).Return(func(_ context.Context, tokenHash string) (models.MFAChallenge, error) {
+		if tokenHash != challenge.TokenHash {
+			return models.MFAChallenge{}, nil
+		}
+		return challenge, nil
+	})
+	mfaRepo.On("IncrementChallengeAttempts", mock.Anything, int64(3), constants.MFAChallengeMaxAttempts).Return(true, nil)
+	refreshRepo := mocks.NewRefreshTokenRepository(t)
+	refreshRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	conf := newTestConfiguration()
+	throttle := NewLoginThrottle(repositories.NewMemoryLoginAttemptRepository(), conf)
+	tokenSvc := NewTokenService(userRepo, refreshRepo, newTestRoleRepository(t, nil, nil), newTestSessionRepository(t), nil, nil, newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{}, conf)
+	svc := NewUserService(userRepo, tokenSvc, nil, throttle, hasher, NewPasswordPolicy(conf), NewMFAService(userRepo, mfaRepo, conf), nil, &recordingAuditService{}, conf)
+
+	// the password alone only yields an mfa_token
+	status, resp, err := svc.Login(ctx, models.LoginRequest{Email: user.Email, Password: "correct horse"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, resp.MFARequired)
+	assert.Empty(t, resp.AccessToken)
+	require.NotEmpty(t, resp.MFAToken)
+	assert.Equal(t, utils.HashToken(resp.MFAToken), challenge.TokenHash)
+
+	// a wrong code counts as a failed login of the account
+	mfaRepo.On("UseRecoveryCode", mock.Anything, user.ID, mock.Anything).Return(false, nil).Once()
+	status, _, err = svc.LoginMFA(ctx, models.MFALoginRequest{MFAToken: resp.MFAToken, Code: "wrong-codes"})
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, constants.ErrInvalidMFACode)
+	throttledError(t, throttle.Check(ctx, user.Email, ""))
+
+	// the right code issues tokens and forgets the failure
+	mfaRepo.On("UseStep", mock.Anything, user.ID, mock.Anything).Return(true, nil).Once()
+	mfaRepo.On("MarkChallengeUsed", mock.Anything, int64(3)).Return(true, nil).Once()
+	status, resp, err = svc.LoginMFA(ctx, models.MFALoginRequest{MFAToken: resp.MFAToken, Code: currentTOTPCode(t, secret)})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, resp.AccessToken)
+	assert.NoError(t, throttle.Check(ctx, user.Email, ""))
+}
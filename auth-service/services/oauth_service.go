@@ -0,0 +1,493 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// pkcePattern matches PKCE code verifiers and S256 code challenges (RFC 7636 section 4.1)
+var pkcePattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// OAuthService implements the OAuth 2.0 authorization code flow with PKCE for registered clients
+// and the client_credentials grant for confidential clients
+type OAuthService interface {
+	RegisterClient(ctx context.Context, ownerUserID int64, req models.ClientRegistrationRequest) (int, models.Client, error)
+	RotateClientSecret(ctx context.Context, ownerUserID int64, clientID string) (int, models.ClientSecret, error)
+	RevokeClientSecret(ctx context.Context, ownerUserID int64, clientID string, secretID int64) (int, error)
+	AuthenticateClient(ctx context.Context, clientID, secret string) (int, models.Client, error)
+	ValidateAuthorizeRequest(ctx context.Context, req models.AuthorizeRequest) (models.Client, models.AuthorizeRequest, error)
+	Authorize(ctx context.Context, req models.AuthorizeRequest, email, password, code string) (string, error)
+	Token(ctx context.Context, req models.TokenRequest) (int, models.TokenResponse, error)
+}
+
+// oauthService is an implementation of OAuthService
+type oauthService struct {
+	clientRepo   repositories.ClientRepository
+	authCodeRepo repositories.AuthorizationCodeRepository
+	userRepo     repositories.UserRepository
+	userSvc      UserService
+	tokenSvc     TokenService
+	conf         config.Configuration
+}
+
+// NewOAuthService returns a new instance of the OAuth service
+func NewOAuthService(
+	clientRepo repositories.ClientRepository,
+	authCodeRepo repositories.AuthorizationCodeRepository,
+	userRepo repositories.UserRepository,
+	userSvc UserService,
+	tokenSvc TokenService,
+	conf config.Configuration,
+) OAuthService {
+	return &oauthService{
+		clientRepo:   clientRepo,
+		authCodeRepo: authCodeRepo,
+		userRepo:     userRepo,
+		userSvc:      userSvc,
+		tokenSvc:     tokenSvc,
+		conf:         conf,
+	}
+}
+
+// RegisterClient registers a client owned by the calling user. Confidential clients get their
+// first secret in the response; it is not stored in plain text and cannot be retrieved later.
+func (o oauthService) RegisterClient(ctx context.Context, ownerUserID int64, req models.ClientRegistrationRequest) (int, models.Client, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return http.StatusBadRequest, models.Client{}, errors.New(constants.ErrInvalidClientName)
+	}
+	// public clients can only obtain tokens through a redirect
+	if !req.Confidential && len(req.RedirectURIs) == 0 {
+		return http.StatusBadRequest, models.Client{}, errors.New(constants.ErrInvalidRedirectURI)
+	}
+	for _, uri := range req.RedirectURIs {
+		if !isValidRedirectURI(uri) {
+			return http.StatusBadRequest, models.Client{}, errors.New(constants.ErrInvalidRedirectURI)
+		}
+	}
+	for _, scope := range req.AllowedScopes {
+		if !req.Confidential || !slices.Contains(constants.ServiceScopes, scope) {
+			return http.StatusBadRequest, models.Client{}, errors.New(constants.ErrInvalidAllowedScope)
+		}
+	}
+
+	clientID, err := utils.GenerateRandomID()
+	if err != nil {
+		return http.StatusInternalServerError, models.Client{}, err
+	}
+
+	client := models.Client{
+		ClientID:      clientID,
+		Name:          name,
+		RedirectURIs:  req.RedirectURIs,
+		Confidential:  req.Confidential,
+		AllowedScopes: req.AllowedScopes,
+		OwnerUserID:   ownerUserID,
+	}
+	if err := o.clientRepo.Create(ctx, &client); err != nil {
+		log.Println("error while creating client", err.Error())
+		return http.StatusInternalServerError, models.Client{}, err
+	}
+
+	if client.Confidential {
+		secret, err := o.createSecret(ctx, client.ClientID)
+		if err != nil {
+			return http.StatusInternalServerError, models.Client{}, err
+		}
+		client.ClientSecret = secret.Secret
+	}
+	return http.StatusCreated, client, nil
+}
+
+// RotateClientSecret adds a secret to a confidential client of the caller. The previous secret stays
+// valid so deployments can switch over; older ones beyond MaxActiveClientSecrets are revoked.
+func (o oauthService) RotateClientSecret(ctx context.Context, ownerUserID int64, clientID string) (int, models.ClientSecret, error) {
+	status, _, err := o.ownedConfidentialClient(ctx, ownerUserID, clientID)
+	if err != nil {
+		return status, models.ClientSecret{}, err
+	}
+
+	secret, err := o.createSecret(ctx, clientID)
+	if err != nil {
+		return http.StatusInternalServerError, models.ClientSecret{}, err
+	}
+	if err := o.clientRepo.RevokeSecretsExceptNewest(ctx, clientID, constants.MaxActiveClientSecrets); err != nil {
+		log.Println("error while revoking old client secrets", err.Error())
+		return http.StatusInternalServerError, models.ClientSecret{}, err
+	}
+
+	log.Printf("rotated secret of client %s", clientID)
+	return http.StatusCreated, secret, nil
+}
+
+// RevokeClientSecret revokes one secret of a confidential client of the caller, e.g. once every
+// deployment uses the rotated secret or after a leak
+func (o oauthService) RevokeClientSecret(ctx context.Context, ownerUserID int64, clientID string, secretID int64) (int, error) {
+	status, _, err := o.ownedConfidentialClient(ctx, ownerUserID, clientID)
+	if err != nil {
+		return status, err
+	}
+
+	revoked, err := o.clientRepo.RevokeSecret(ctx, clientID, secretID)
+	if err != nil {
+		log.Println("error while revoking client secret", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !revoked {
+		return http.StatusNotFound, errors.New(constants.ErrClientSecretNotFound)
+	}
+
+	log.Printf("revoked secret %d of client %s", secretID, clientID)
+	return http.StatusOK, nil
+}
+
+// AuthenticateClient verifies the credentials of a confidential client against its active secrets.
+// Every failure is reported as invalid_client so callers cannot tell unknown clients from wrong secrets.
+func (o oauthService) AuthenticateClient(ctx context.Context, clientID, secret string) (int, models.Client, error) {
+	errInvalidClient := models.NewOAuthError(constants.OAuthErrInvalidClient, "client authentication failed")
+	if clientID == "" || secret == "" {
+		return http.StatusUnauthorized, models.Client{}, errInvalidClient
+	}
+
+	client, err := o.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return http.StatusInternalServerError, models.Client{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+	if client.ID == 0 || !client.Confidential {
+		return http.StatusUnauthorized, models.Client{}, errInvalidClient
+	}
+
+	secrets, err := o.clientRepo.ListActiveSecrets(ctx, clientID)
+	if err != nil {
+		log.Println("error while fetching client secrets", err.Error())
+		return http.StatusInternalServerError, models.Client{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+
+	hash := []byte(utils.HashToken(secret))
+	matched := false
+	for _, stored := range secrets {
+		if subtle.ConstantTimeCompare(hash, []byte(stored.SecretHash)) == 1 {
+			matched = true
+		}
+	}
+	if !matched {
+		return http.StatusUnauthorized, models.Client{}, errInvalidClient
+	}
+	return http.StatusOK, client, nil
+}
+
+// ValidateAuthorizeRequest checks an authorization request and returns it with the redirect URI resolved.
+// When the returned client is the zero value the client or redirect URI could not be trusted, so the
+// error must be shown to the user instead of being sent to the redirect URI (RFC 6749 section 4.1.2.1).
+func (o oauthService) ValidateAuthorizeRequest(ctx context.Context, req models.AuthorizeRequest) (models.Client, models.AuthorizeRequest, error) {
+	if req.ClientID == "" {
+		return models.Client{}, req, models.NewOAuthError(constants.OAuthErrInvalidRequest, "client_id is required")
+	}
+
+	client, err := o.clientRepo.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		return models.Client{}, req, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+	if client.ID == 0 {
+		return models.Client{}, req, models.NewOAuthError(constants.OAuthErrInvalidClient, "unknown client")
+	}
+
+	// the redirect URI may only be omitted when the client registered exactly one
+	if req.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return models.Client{}, req, models.NewOAuthError(constants.OAuthErrInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	if req.ResponseType != constants.ResponseTypeCode {
+		return client, req, models.NewOAuthError(constants.OAuthErrUnsupportedResponseType, "only the code response type is supported")
+	}
+	if req.CodeChallengeMethod != constants.CodeChallengeMethodS256 || !pkcePattern.MatchString(req.CodeChallenge) {
+		return client, req, models.NewOAuthError(constants.OAuthErrInvalidRequest, "a PKCE code_challenge with method S256 is required")
+	}
+	for _, scope := range utils.ParseScope(req.Scope) {
+		if !slices.Contains(constants.SupportedScopes, scope) {
+			return client, req, models.NewOAuthError(constants.OAuthErrInvalidScope, "unsupported scope "+scope)
+		}
+	}
+	return client, req, nil
+}
+
+// Authorize authenticates the user and returns the redirect URL carrying a new authorization code.
+// Users with two-factor authentication must also give a code. A wrong password or code is returned
+// as is so the login page can be shown again.
+func (o oauthService) Authorize(ctx context.Context, req models.AuthorizeRequest, email, password, code string) (string, error) {
+	client, req, err := o.ValidateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	_, user, err := o.userSvc.Authenticate(ctx, email, password)
+	if err != nil {
+		return "", err
+	}
+	if _, err := o.userSvc.VerifySecondFactor(ctx, user, code); err != nil {
+		return "", err
+	}
+	return o.issueCode(ctx, client, req, user)
+}
+
+// Token exchanges an authorization code or a refresh token for a token pair (RFC 6749 sections 4.1.3
+// and 6), or issues a client token (section 4.4). Confidential clients must authenticate with a secret.
+func (o oauthService) Token(ctx context.Context, req models.TokenRequest) (int, models.TokenResponse, error) {
+	if req.ClientID == "" {
+		return http.StatusUnauthorized, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidClient, "client_id is required")
+	}
+
+	var client models.Client
+	if req.ClientSecret != "" {
+		status, authenticated, err := o.AuthenticateClient(ctx, req.ClientID, req.ClientSecret)
+		if err != nil {
+			return status, models.TokenResponse{}, err
+		}
+		client = authenticated
+	} else {
+		found, err := o.clientRepo.GetByClientID(ctx, req.ClientID)
+		if err != nil {
+			return http.StatusInternalServerError, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+		}
+		if found.ID == 0 {
+			return http.StatusUnauthorized, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidClient, "unknown client")
+		}
+		if found.Confidential {
+			return http.StatusUnauthorized, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidClient, "client authentication is required")
+		}
+		client = found
+	}
+
+	switch req.GrantType {
+	case constants.GrantTypeAuthorizationCode:
+		return o.exchangeCode(ctx, client, req)
+	case constants.GrantTypeRefreshToken:
+		return o.refresh(ctx, client, req)
+	case constants.GrantTypeClientCredentials:
+		return o.clientCredentials(ctx, client, req)
+	default:
+		return http.StatusBadRequest, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrUnsupportedGrantType, "")
+	}
+}
+
+// issueCode stores a new authorization code and builds the redirect back to the client
+func (o oauthService) issueCode(ctx context.Context, client models.Client, req models.AuthorizeRequest, user models.User) (string, error) {
+	code, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	stored := models.AuthorizationCode{
+		CodeHash:            utils.HashToken(code),
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		ExpiresAt:           time.Now().UTC().Add(constants.AuthorizationCodeTTL),
+	}
+	if err := o.authCodeRepo.Create(ctx, &stored); err != nil {
+		log.Println("error while storing authorization code", err.Error())
+		return "", models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+
+	log.Printf("issued authorization code to client %s for user %d", client.ClientID, user.ID)
+	return AuthorizeRedirectURL(req, url.Values{"code": {code}}), nil
+}
+
+// exchangeCode redeems an authorization code after checking the client, redirect URI and PKCE verifier
+func (o oauthService) exchangeCode(ctx context.Context, client models.Client, req models.TokenRequest) (int, models.TokenResponse, error) {
+	errInvalidGrant := models.NewOAuthError(constants.OAuthErrInvalidGrant, "invalid authorization code")
+	if req.Code == "" || !pkcePattern.MatchString(req.CodeVerifier) {
+		return http.StatusBadRequest, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidRequest, "code and a valid code_verifier are required")
+	}
+
+	code, err := o.authCodeRepo.GetByHash(ctx, utils.HashToken(req.Code))
+	if err != nil {
+		return http.StatusInternalServerError, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+	if code.ID == 0 || code.UsedAt != nil || time.Now().UTC().After(code.ExpiresAt) {
+		return http.StatusBadRequest, models.TokenResponse{}, errInvalidGrant
+	}
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return http.StatusBadRequest, models.TokenResponse{}, errInvalidGrant
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return http.StatusBadRequest, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidGrant, "code_verifier does not match the code_challenge")
+	}
+
+	// codes are single use; losing this race means a concurrent request redeemed it
+	marked, err := o.authCodeRepo.MarkUsed(ctx, code.ID)
+	if err != nil {
+		log.Println("error while consuming authorization code", err.Error())
+		return http.StatusInternalServerError, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+	if !marked {
+		return http.StatusBadRequest, models.TokenResponse{}, errInvalidGrant
+	}
+
+	user, err := o.userRepo.GetByID(ctx, code.UserID)
+	if err != nil {
+		return http.StatusInternalServerError, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+	// the user was deleted or disabled after authorizing the client
+	if user.ID == 0 || user.Disabled() {
+		return http.StatusBadRequest, models.TokenResponse{}, errInvalidGrant
+	}
+
+	grant := models.TokenGrant{ClientID: client.ClientID, Scope: code.Scope, Nonce: code.Nonce}
+	resp, err := o.tokenSvc.IssueTokens(ctx, user, grant)
+	if err != nil {
+		return http.StatusInternalServerError, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+	return http.StatusOK, tokenResponse(resp), nil
+}
+
+// refresh rotates a refresh token issued to the client
+func (o oauthService) refresh(ctx context.Context, client models.Client, req models.TokenRequest) (int, models.TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return http.StatusBadRequest, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidRequest, "refresh_token is required")
+	}
+
+	status, resp, err := o.tokenSvc.Refresh(ctx, req.RefreshToken, client.ClientID)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			return status, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+		}
+		return http.StatusBadRequest, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidGrant, err.Error())
+	}
+	return http.StatusOK, tokenResponse(resp), nil
+}
+
+// clientCredentials issues an access token to an authenticated confidential client. Without a scope
+// parameter the token gets every scope the client is allowed.
+func (o oauthService) clientCredentials(ctx context.Context, client models.Client, req models.TokenRequest) (int, models.TokenResponse, error) {
+	if !client.Confidential {
+		return http.StatusBadRequest, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrUnauthorizedClient, "only confidential clients may use client_credentials")
+	}
+
+	scopes := utils.ParseScope(req.Scope)
+	if len(scopes) == 0 {
+		scopes = client.AllowedScopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(client.AllowedScopes, scope) {
+			return http.StatusBadRequest, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrInvalidScope, "scope "+scope+" is not allowed for this client")
+		}
+	}
+
+	resp, err := o.tokenSvc.IssueClientToken(ctx, client, strings.Join(scopes, " "))
+	if err != nil {
+		return http.StatusInternalServerError, models.TokenResponse{}, models.NewOAuthError(constants.OAuthErrServerError, "")
+	}
+	return http.StatusOK, tokenResponse(resp), nil
+}
+
+// createSecret generates a client secret and stores its hash; the plain secret is only returned once
+func (o oauthService) createSecret(ctx context.Context, clientID string) (models.ClientSecret, error) {
+	plain, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return models.ClientSecret{}, err
+	}
+
+	secret := models.ClientSecret{ClientID: clientID, SecretHash: utils.HashToken(plain)}
+	if err := o.clientRepo.CreateSecret(ctx, &secret); err != nil {
+		log.Println("error while storing client secret", err.Error())
+		return models.ClientSecret{}, err
+	}
+	secret.Secret = plain
+	return secret, nil
+}
+
+// ownedConfidentialClient fetches a confidential client and checks that the caller owns it.
+// Clients of other users are reported as not found.
+func (o oauthService) ownedConfidentialClient(ctx context.Context, ownerUserID int64, clientID string) (int, models.Client, error) {
+	client, err := o.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		log.Println("error while fetching client", err.Error())
+		return http.StatusInternalServerError, models.Client{}, err
+	}
+	if client.ID == 0 || client.OwnerUserID != ownerUserID {
+		return http.StatusNotFound, models.Client{}, errors.New(constants.ErrClientNotFound)
+	}
+	if !client.Confidential {
+		return http.StatusBadRequest, models.Client{}, errors.New(constants.ErrClientNotConfidential)
+	}
+	return http.StatusOK, client, nil
+}
+
+// AuthorizeRedirectURL appends params and the request state to the redirect URI of an authorization request
+func AuthorizeRedirectURL(req models.AuthorizeRequest, params url.Values) string {
+	redirect, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return req.RedirectURI
+	}
+
+	query := redirect.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirect.RawQuery = query.Encode()
+	return redirect.String()
+}
+
+// tokenResponse converts issued tokens to an RFC 6749 token response
+func tokenResponse(resp models.LoginResponse) models.TokenResponse {
+	return models.TokenResponse{
+		AccessToken:  resp.AccessToken,
+		TokenType:    constants.TokenTypeBearer,
+		ExpiresIn:    resp.ExpiresAt - time.Now().UTC().Unix(),
+		RefreshToken: resp.RefreshToken,
+		Scope:        resp.Scope,
+		IDToken:      resp.IDToken,
+	}
+}
+
+// verifyCodeChallenge checks an S256 PKCE code verifier against the stored challenge
+func verifyCodeChallenge(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// isValidRedirectURI accepts absolute https URLs without a fragment; plain http is only allowed
+// for loopback addresses used by native apps and local development (RFC 8252 section 7.3)
+func isValidRedirectURI(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Host == "" || parsed.Fragment != "" || parsed.User != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := parsed.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	default:
+		return false
+	}
+}
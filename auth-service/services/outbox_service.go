@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"auth-service/models"
+	"auth-service/repositories"
+)
+
+// OutboxService hands the events of the outbox to the services consuming them
+type OutboxService interface {
+	ListEvents(ctx context.Context, afterID int64, limit int) (int, models.OutboxEventListResponse, error)
+}
+
+// outboxService is an implementation of OutboxService
+type outboxService struct {
+	outboxRepo repositories.OutboxRepository
+}
+
+// NewOutboxService returns a new instance of the outbox service
+func NewOutboxService(outboxRepo repositories.OutboxRepository) OutboxService {
+	return &outboxService{outboxRepo: outboxRepo}
+}
+
+// ListEvents returns up to limit events after the event with id afterID, oldest first. Consumers
+// pass the id of the last event they handled, starting at zero.
+func (o outboxService) ListEvents(ctx context.Context, afterID int64, limit int) (int, models.OutboxEventListResponse, error) {
+	events, err := o.outboxRepo.List(ctx, afterID, limit)
+	if err != nil {
+		log.Println("error while listing outbox events", err.Error())
+		return http.StatusInternalServerError, models.OutboxEventListResponse{}, err
+	}
+	return http.StatusOK, models.OutboxEventListResponse{Events: events}, nil
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"auth-service/config"
+	"auth-service/constants"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Sizes of the salt and derived key of argon2id hashes
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// PasswordHasher hashes passwords into self-describing strings: argon2id hashes use the PHC string
+// format and bcrypt hashes their modular crypt format, which both carry the algorithm and its
+// parameters. NeedsRehash reports whether a stored hash should be replaced because it was made
+// with another algorithm or other parameters than new hashes are.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+}
+
+// NewPasswordHasher returns a hasher that creates hashes with the algorithm selected by
+// PASSWORD_HASH_ALGORITHM and verifies hashes of every supported algorithm
+func NewPasswordHasher(conf config.Configuration) PasswordHasher {
+	appConf := conf.AppConfig()
+	h := &passwordHasher{
+		bcrypt: &bcryptHasher{cost: appConf.BcryptCost()},
+		argon2id: &argon2idHasher{
+			memory:      appConf.Argon2Memory(),
+			iterations:  appConf.Argon2Iterations(),
+			parallelism: appConf.Argon2Parallelism(),
+		},
+	}
+	h.preferred = h.argon2id
+	if appConf.PasswordHashAlgorithm() == constants.PasswordHashBcrypt {
+		h.preferred = h.bcrypt
+	}
+	return h
+}
+
+// passwordHasher dispatches verification to the algorithm of the stored hash
+type passwordHasher struct {
+	preferred PasswordHasher
+	bcrypt    *bcryptHasher
+	argon2id  *argon2idHasher
+
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+// Hash hashes the password with the preferred algorithm
+func (h *passwordHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify checks the password against a hash of any supported algorithm. An empty hash, as of
+// unknown users, never matches but costs as much to check as a hash of the preferred algorithm,
+// so that response times do not tell which emails have accounts.
+func (h *passwordHasher) Verify(password, encoded string) (bool, error) {
+	switch {
+	case encoded == "":
+		h.dummyOnce.Do(func() {
+			h.dummyHash, _ = h.preferred.Hash("dummy password")
+		})
+		_, _ = h.preferred.Verify(password, h.dummyHash)
+		return false, nil
+	case isArgon2idHash(encoded):
+		return h.argon2id.Verify(password, encoded)
+	case isBcryptHash(encoded):
+		return h.bcrypt.Verify(password, encoded)
+	default:
+		return false, errors.New(constants.ErrUnknownHashFormat)
+	}
+}
+
+// NeedsRehash reports whether the hash differs from what the preferred algorithm creates
+func (h *passwordHasher) NeedsRehash(encoded string) bool {
+	return h.preferred.NeedsRehash(encoded)
+}
+
+// bcryptHasher hashes passwords with bcrypt
+type bcryptHasher struct {
+	cost int
+}
+
+// Hash hashes the password with bcrypt
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify checks the password against a bcrypt hash
+func (h *bcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NeedsRehash reports whether the hash is not a bcrypt hash of the configured cost
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	if !isBcryptHash(encoded) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+// argon2idHasher hashes passwords with argon2id
+type argon2idHasher struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// argon2idParams are the parameters encoded in an argon2id hash
+type argon2idParams struct {
+	version     int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// Hash hashes the password with argon2id and a random salt
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.iterations, h.memory, h.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.iterations, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks the password against an argon2id hash with the parameters stored in it
+func (h *argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, err := parseArgon2idHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1, nil
+}
+
+// NeedsRehash reports whether the hash is not an argon2id hash with the configured parameters
+func (h *argon2idHasher) NeedsRehash(encoded string) bool {
+	params, err := parseArgon2idHash(encoded)
+	if err != nil {
+		return true
+	}
+	return params.version != argon2.Version ||
+		params.memory != h.memory ||
+		params.iterations != h.iterations ||
+		params.parallelism != h.parallelism ||
+		len(params.key) != argon2KeyLength
+}
+
+// parseArgon2idHash decodes a PHC string of the form $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func parseArgon2idHash(encoded string) (argon2idParams, error) {
+	errFormat := errors.New(constants.ErrUnknownHashFormat)
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != constants.PasswordHashArgon2id {
+		return argon2idParams{}, errFormat
+	}
+
+	var params argon2idParams
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &params.version); err != nil {
+		return argon2idParams{}, errFormat
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return argon2idParams{}, errFormat
+	}
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return argon2idParams{}, errFormat
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.key) == 0 {
+		return argon2idParams{}, errFormat
+	}
+	if params.iterations == 0 || params.parallelism == 0 {
+		return argon2idParams{}, errFormat
+	}
+	return params, nil
+}
+
+// isArgon2idHash reports whether encoded looks like an argon2id PHC string
+func isArgon2idHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$"+constants.PasswordHashArgon2id+"$")
+}
+
+// isBcryptHash reports whether encoded looks like a bcrypt hash
+func isBcryptHash(encoded string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(encoded, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/repositories/mocks"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPasswordHasher returns a hasher preferring algorithm, with cheap parameters
+func newTestPasswordHasher(algorithm string, bcryptCost, argon2Memory int) PasswordHasher {
+	env := viper.New()
+	env.Set(constants.PasswordHashAlgorithm, algorithm)
+	env.Set(constants.BcryptCost, bcryptCost)
+	env.Set(constants.Argon2Memory, argon2Memory)
+	env.Set(constants.Argon2Iterations, 1)
+	env.Set(constants.Argon2Parallelism, 1)
+	return NewPasswordHasher(config.NewConfiguration(config.NewAppConfig(env)))
+}
+
+func Test_passwordHasher(t *testing.T) {
+	argon := newTestPasswordHasher(constants.PasswordHashArgon2id, 4, 1024)
+	bcryptHash, err := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024).Hash("correct horse")
+	require.NoError(t, err)
+
+	hash, err := argon.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), hash)
+
+	other, err := argon.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "hashes are salted")
+
+	for _, stored := range []string{hash, bcryptHash} {
+		matched, err := argon.Verify("correct horse", stored)
+		assert.NoError(t, err)
+		assert.True(t, matched, stored)
+
+		matched, err = argon.Verify("wrong horse", stored)
+		assert.NoError(t, err)
+		assert.False(t, matched, stored)
+	}
+
+	_, err = argon.Verify("correct horse", "plaintext")
+	assert.EqualError(t, err, constants.ErrUnknownHashFormat)
+
+	// unknown users are checked against a dummy hash, which no password matches
+	for _, password := range []string{"", "dummy password"} {
+		matched, err := argon.Verify(password, "")
+		assert.NoError(t, err)
+		assert.False(t, matched)
+	}
+}
+
+func Test_passwordHasher_NeedsRehash(t *testing.T) {
+	argon := newTestPasswordHasher(constants.PasswordHashArgon2id, 4, 1024)
+	bcryptHasher := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024)
+	argonHash, err := argon.Hash("correct horse")
+	require.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("correct horse")
+	require.NoError(t, err)
+
+	assert.False(t, argon.NeedsRehash(argonHash))
+	assert.True(t, argon.NeedsRehash(bcryptHash), "other algorithm")
+	assert.True(t, newTestPasswordHasher(constants.PasswordHashArgon2id, 4, 2048).NeedsRehash(argonHash), "other memory")
+
+	assert.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+	assert.True(t, bcryptHasher.NeedsRehash(argonHash), "other algorithm")
+	assert.True(t, newTestPasswordHasher(constants.PasswordHashBcrypt, 5, 1024).NeedsRehash(bcryptHash), "other cost")
+}
+
+func Test_userService_AuthenticateRehash(t *testing.T) {
+	hasher := newTestPasswordHasher(constants.PasswordHashArgon2id, 4, 1024)
+	legacy, err := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024).Hash("correct horse")
+	require.NoError(t, err)
+	user := models.User{ID: 7, Email: "asif@example.com", Password: legacy}
+
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepo.On("UpdatePassword", mock.Anything, user.ID, mock.MatchedBy(func(hash string) bool {
+		matched, err := hasher.Verify("correct horse", hash)
+		return err == nil && matched && !hasher.NeedsRehash(hash)
+	})).Return(nil).Once()
+
+	conf := newTestConfiguration()
+	throttle := NewLoginThrottle(repositories.NewMemoryLoginAttemptRepository(), conf)
+	svc := NewUserService(userRepo, nil, nil, throttle, hasher, NewPasswordPolicy(conf), newTestMFAService(t, models.UserMFA{}), nil, &recordingAuditService{}, conf)
+
+	status, got, err := svc.Authenticate(context.Background(), user.Email, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, user.ID, got.ID)
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"auth-service/config"
+	"auth-service/constants"
+)
+
+// PasswordPolicy decides which passwords users may choose. Passwords must have a configured length
+// and must not appear in the breached password list, if one is configured. Load reads the list
+// and must be called before the policy is used.
+type PasswordPolicy interface {
+	Load() error
+	Validate(password string) error
+}
+
+// passwordPolicy is an implementation of PasswordPolicy
+type passwordPolicy struct {
+	conf     config.Configuration
+	breached map[string]struct{}
+}
+
+// NewPasswordPolicy returns a new instance of the password policy
+func NewPasswordPolicy(conf config.Configuration) PasswordPolicy {
+	return &passwordPolicy{conf: conf, breached: map[string]struct{}{}}
+}
+
+// Load reads the breached password list named by PASSWORD_BREACHED_LIST. Empty lines and lines
+// starting with # are skipped. Passwords are compared case-insensitively, so a listed password
+// cannot be reused by changing the case of its letters.
+func (p *passwordPolicy) Load() error {
+	path := p.conf.AppConfig().PasswordBreachedList()
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	breached := map[string]struct{}{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	p.breached = breached
+	log.Printf("loaded %d breached passwords from %s", len(breached), path)
+	return nil
+}
+
+// Validate returns an error describing why the password may not be used, or nil
+func (p *passwordPolicy) Validate(password string) error {
+	appConf := p.conf.AppConfig()
+
+	length := utf8.RuneCountInString(password)
+	if length < appConf.PasswordMinLength() {
+		return fmt.Errorf(constants.ErrPasswordTooShort, appConf.PasswordMinLength())
+	}
+	if length > appConf.PasswordMaxLength() {
+		return fmt.Errorf(constants.ErrPasswordTooLong, appConf.PasswordMaxLength())
+	}
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return errors.New(constants.ErrPasswordBreached)
+	}
+	return nil
+}
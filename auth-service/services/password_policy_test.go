@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"auth-service/config"
+	"auth-service/constants"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_passwordPolicy_Validate(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(list, []byte("# top passwords\npassword123\n\nletmein!!\n"), 0o600))
+
+	env := viper.New()
+	env.Set(constants.PasswordMinLength, 10)
+	env.Set(constants.PasswordMaxLength, 20)
+	env.Set(constants.PasswordBreachedList, list)
+	policy := NewPasswordPolicy(config.NewConfiguration(config.NewAppConfig(env)))
+	require.NoError(t, policy.Load())
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string
+	}{
+		{name: "acceptable password", password: "correct horse"},
+		{name: "too short", password: "horse", wantErr: fmt.Sprintf(constants.ErrPasswordTooShort, 10)},
+		{name: "length counts characters, not bytes", password: strings.Repeat("ä", 10)},
+		{name: "too long", password: strings.Repeat("horse", 5), wantErr: fmt.Sprintf(constants.ErrPasswordTooLong, 20)},
+		{name: "breached password", password: "password123", wantErr: constants.ErrPasswordBreached},
+		{name: "breached password with other case", password: "PassWord123", wantErr: constants.ErrPasswordBreached},
+		{name: "comment lines are not passwords", password: "# top passwords"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_passwordPolicy_LoadMissingList(t *testing.T) {
+	env := viper.New()
+	env.Set(constants.PasswordBreachedList, filepath.Join(t.TempDir(), "missing.txt"))
+	policy := NewPasswordPolicy(config.NewConfiguration(config.NewAppConfig(env)))
+
+	assert.Error(t, policy.Load())
+}
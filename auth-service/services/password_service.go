@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// PasswordService lets users who forgot their password set a new one through a mailed link
+type PasswordService interface {
+	ForgotPassword(ctx context.Context, email string) (int, error)
+	ResetPassword(ctx context.Context, token, password string) (int, error)
+}
+
+// passwordService is an implementation of PasswordService
+type passwordService struct {
+	userRepo  repositories.UserRepository
+	resetRepo repositories.PasswordResetRepository
+	tokenSvc  TokenService
+	hasher    PasswordHasher
+	policy    PasswordPolicy
+	mailer    Mailer
+	conf      config.Configuration
+}
+
+// NewPasswordService returns a new instance of the password service
+func NewPasswordService(
+	userRepo repositories.UserRepository,
+	resetRepo repositories.PasswordResetRepository,
+	tokenSvc TokenService,
+	hasher PasswordHasher,
+	policy PasswordPolicy,
+	mailer Mailer,
+	conf config.Configuration,
+) PasswordService {
+	return &passwordService{
+		userRepo:  userRepo,
+		resetRepo: resetRepo,
+		tokenSvc:  tokenSvc,
+		hasher:    hasher,
+		policy:    policy,
+		mailer:    mailer,
+		conf:      conf,
+	}
+}
+
+// ForgotPassword mails a reset link to the user with the given email. The result is the same
+// whether or not the email is registered, so the endpoint cannot be used to probe for accounts;
+// failures after the user was found are logged instead of returned for the same reason.
+func (p passwordService) ForgotPassword(ctx context.Context, email string) (int, error) {
+	user, err := p.userRepo.GetByUserEmail(ctx, email)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if user.ID == 0 {
+		return http.StatusAccepted, nil
+	}
+
+	if err := p.sendResetLink(ctx, user); err != nil {
+		log.Printf("error while sending password reset link to user %d: %v", user.ID, err)
+	}
+	return http.StatusAccepted, nil
+}
+
+// ResetPassword sets a new password with a reset token. The token is consumed, other outstanding
+// tokens of the user are invalidated, and every refresh and access token of the user is revoked
+// so that whoever knew the old password is logged out.
+func (p passwordService) ResetPassword(ctx context.Context, token, password string) (int, error) {
+	errInvalid := errors.New(constants.ErrInvalidResetToken)
+	if err := p.policy.Validate(password); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if token == "" {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	stored, err := p.resetRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if stored.ID == 0 || stored.UsedAt != nil || time.Now().UTC().After(stored.ExpiresAt) {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	// tokens are single use; losing this race means a concurrent request used it
+	marked, err := p.resetRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		log.Println("error while consuming password reset token", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !marked {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	hashedPassword, err := p.hasher.Hash(password)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if err := p.userRepo.UpdatePassword(ctx, stored.UserID, hashedPassword); err != nil {
+		log.Println("error while updating password", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	if err := p.resetRepo.InvalidateForUser(ctx, stored.UserID); err != nil {
+		log.Println("error while invalidating password reset tokens", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if status, err := p.tokenSvc.LogoutEverywhere(ctx, stored.UserID); err != nil {
+		return status, err
+	}
+
+	log.Printf("password reset for user %d", stored.UserID)
+	return http.StatusOK, nil
+}
+
+// sendResetLink replaces any outstanding reset token of the user with a new one and mails it
+func (p passwordService) sendResetLink(ctx context.Context, user models.User) error {
+	appConf := p.conf.AppConfig()
+
+	if err := p.resetRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	stored := models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(appConf.PasswordResetTTL()),
+	}
+	if err := p.resetRepo.Create(ctx, &stored); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(appConf.PasswordResetURL())
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return p.mailer.Send(ctx, models.Email{
+		From:    appConf.MailFrom(),
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your account.\r\n\r\n"+
+			"Open this link within %s to choose a new password:\r\n%s\r\n\r\n"+
+			"If this was not you, ignore this email; your password stays unchanged.",
+			appConf.PasswordResetTTL(), link.String()),
+	})
+}
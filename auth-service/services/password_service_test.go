@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer keeps sent emails in memory
+type recordingMailer struct {
+	sent []models.Email
+}
+
+// Send records the email
+func (m *recordingMailer) Send(_ context.Context, email models.Email) error {
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+func Test_passwordService_ForgotPassword(t *testing.T) {
+	user := models.User{ID: 7, Email: "asif@example.com"}
+
+	t.Run("unknown email gets the same answer and no mail", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByUserEmail", mock.Anything, "nobody@example.com").Return(models.User{}, nil)
+		mailer := &recordingMailer{}
+		svc := NewPasswordService(userRepo, mocks.NewPasswordResetRepository(t), nil, nil, nil, mailer, newTestConfiguration())
+
+		status, err := svc.ForgotPassword(context.Background(), "nobody@example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.Empty(t, mailer.sent)
+	})
+
+	t.Run("registered email gets a single-use link", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+		resetRepo := mocks.NewPasswordResetRepository(t)
+		resetRepo.On("InvalidateForUser", mock.Anything, user.ID).Return(nil)
+		var stored *models.PasswordResetToken
+		resetRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*models.PasswordResetToken)
+		}).Return(nil)
+		mailer := &recordingMailer{}
+		svc := NewPasswordService(userRepo, resetRepo, nil, nil, nil, mailer, newTestConfiguration())
+
+		status, err := svc.ForgotPassword(context.Background(), user.Email)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		require.Len(t, mailer.sent, 1)
+		assert.Equal(t, user.Email, mailer.sent[0].To)
+
+		// only the hash of the mailed token is stored
+		link := mailer.sent[0].Body[strings.Index(mailer.sent[0].Body, constants.DefaultPasswordResetURL):]
+		parsed, err := url.Parse(strings.Fields(link)[0])
+		require.NoError(t, err)
+		token := parsed.Query().Get("token")
+		require.NotEmpty(t, token)
+		assert.Equal(t, utils.HashToken(token), stored.TokenHash)
+		assert.WithinDuration(t, time.Now().UTC().Add(constants.DefaultPasswordResetTTL), stored.ExpiresAt, time.Minute)
+	})
+}
+
+func Test_passwordService_ResetPassword(t *testing.T) {
+	const token = "reset-token"
+	const newPassword = "correct horse battery"
+	usedAt := time.Now().UTC().Add(-time.Minute)
+	valid := models.PasswordResetToken{ID: 3, UserID: 7, TokenHash: utils.HashToken(token), ExpiresAt: time.Now().UTC().Add(time.Minute)}
+	hasher := NewPasswordHasher(newTestConfiguration())
+
+	tests := []struct {
+		name       string
+		password   string
+		stored     models.PasswordResetToken
+		marked     bool
+		wantStatus int
+		wantErr    string
+	}{
+		{name: "valid token resets the password", password: newPassword, stored: valid, marked: true, wantStatus: http.StatusOK},
+		{name: "short password", password: "short", stored: valid, wantStatus: http.StatusBadRequest, wantErr: fmt.Sprintf(constants.ErrPasswordTooShort, constants.MinPasswordLength)},
+		{name: "unknown token", password: newPassword, stored: models.PasswordResetToken{}, wantStatus: http.StatusBadRequest, wantErr: constants.ErrInvalidResetToken},
+		{
+			name:       "used token",
+			password:   newPassword,
+			stored:     models.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: valid.ExpiresAt, UsedAt: &usedAt},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    constants.ErrInvalidResetToken,
+		},
+		{
+			name:       "expired token",
+			password:   newPassword,
+			stored:     models.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: time.Now().UTC().Add(-time.Second)},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    constants.ErrInvalidResetToken,
+		},
+		{name: "token consumed concurrently", password: newPassword, stored: valid, marked: false, wantStatus: http.StatusBadRequest, wantErr: constants.ErrInvalidResetToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewUserRepository(t)
+			resetRepo := mocks.NewPasswordResetRepository(t)
+			refreshRepo := mocks.NewRefreshTokenRepository(t)
+			revokedRepo := mocks.NewRevokedTokenRepository(t)
+			sessionRepo := mocks.NewSessionRepository(t)
+
+			resetRepo.On("GetByHash", mock.Anything, utils.HashToken(token)).Return(tt.stored, nil).Maybe()
+			if tt.stored.ID != 0 && tt.stored.UsedAt == nil && tt.stored.ExpiresAt.After(time.Now()) && tt.password == newPassword {
+				resetRepo.On("MarkUsed", mock.Anything, tt.stored.ID).Return(tt.marked, nil)
+			}
+			if tt.wantStatus == http.StatusOK {
+				userRepo.On("UpdatePassword", mock.Anything, valid.UserID, mock.MatchedBy(func(hash string) bool {
+					matched, err := hasher.Verify(newPassword, hash)
+					return err == nil && matched
+				})).Return(nil)
+				resetRepo.On("InvalidateForUser", mock.Anything, valid.UserID).Return(nil)
+				refreshRepo.On("RevokeAllForUser", mock.Anything, valid.UserID).Return(nil)
+				revokedRepo.On("SetUserCutoff", mock.Anything, mock.MatchedBy(func(c models.UserTokenCutoff) bool {
+					return c.UserID == valid.UserID
+				})).Return(nil)
+				sessionRepo.On("RevokeAllForUser", mock.Anything, valid.UserID).Return(nil)
+			}
+
+			conf := newTestConfiguration()
+			tokenSvc := NewTokenService(userRepo, refreshRepo, nil, sessionRepo, nil, NewRevocationStore(revokedRepo, sessionRepo, conf), nil, &recordingAuditService{}, conf)
+			svc := NewPasswordService(userRepo, resetRepo, tokenSvc, hasher, NewPasswordPolicy(conf), &recordingMailer{}, conf)
+
+			status, err := svc.ResetPassword(context.Background(), token, tt.password)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// PersonalAccessTokenService lets users create long-lived tokens for scripts and revoke them again.
+// The tokens are verified by TokenService along with access tokens.
+type PersonalAccessTokenService interface {
+	ListTokens(ctx context.Context, userID int64) (int, []models.PersonalAccessToken, error)
+	CreateToken(ctx context.Context, userID int64, req models.CreatePersonalAccessTokenRequest) (int, models.CreatePersonalAccessTokenResponse, error)
+	RevokeToken(ctx context.Context, userID, tokenID int64) (int, error)
+}
+
+// personalAccessTokenService is an implementation of PersonalAccessTokenService
+type personalAccessTokenService struct {
+	patRepo repositories.PersonalAccessTokenRepository
+	audit   AuditService
+}
+
+// NewPersonalAccessTokenService returns a new instance of the personal access token service
+func NewPersonalAccessTokenService(patRepo repositories.PersonalAccessTokenRepository, audit AuditService) PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		patRepo: patRepo,
+		audit:   audit,
+	}
+}
+
+// ListTokens returns the usable tokens of a user, newest first. Revoked and expired tokens are not listed.
+func (s personalAccessTokenService) ListTokens(ctx context.Context, userID int64) (int, []models.PersonalAccessToken, error) {
+	tokens, err := s.patRepo.ListActiveForUser(ctx, userID, time.Now().UTC())
+	if err != nil {
+		log.Println("error while listing personal access tokens", err.Error())
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, tokens, nil
+}
+
+// CreateToken creates a personal access token with the requested name, scope and expiry. The
+// plain token is returned once; only its hash is stored.
+func (s personalAccessTokenService) CreateToken(ctx context.Context, userID int64, req models.CreatePersonalAccessTokenRequest) (int, models.CreatePersonalAccessTokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return http.StatusBadRequest, models.CreatePersonalAccessTokenResponse{}, errors.New("name is required")
+	}
+	if len(name) > constants.MaxPersonalAccessTokenNameLength {
+		return http.StatusBadRequest, models.CreatePersonalAccessTokenResponse{}, fmt.Errorf(constants.ErrFieldTooLong, "name", constants.MaxPersonalAccessTokenNameLength)
+	}
+
+	scopes := utils.ParseScope(req.Scope)
+	if len(scopes) == 0 {
+		return http.StatusBadRequest, models.CreatePersonalAccessTokenResponse{}, invalidTokenScopeError()
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(constants.PersonalAccessTokenScopes, scope) {
+			return http.StatusBadRequest, models.CreatePersonalAccessTokenResponse{}, invalidTokenScopeError()
+		}
+	}
+
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > constants.MaxPersonalAccessTokenDays {
+		return http.StatusBadRequest, models.CreatePersonalAccessTokenResponse{}, fmt.Errorf(constants.ErrInvalidTokenExpiry, constants.MaxPersonalAccessTokenDays)
+	}
+
+	now := time.Now().UTC()
+	count, err := s.patRepo.CountActiveForUser(ctx, userID, now)
+	if err != nil {
+		log.Println("error while counting personal access tokens", err.Error())
+		return http.StatusInternalServerError, models.CreatePersonalAccessTokenResponse{}, err
+	}
+	if count >= constants.MaxPersonalAccessTokens {
+		return http.StatusConflict, models.CreatePersonalAccessTokenResponse{}, fmt.Errorf(constants.ErrTooManyPersonalAccessTokens, constants.MaxPersonalAccessTokens)
+	}
+
+	secret, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return http.StatusInternalServerError, models.CreatePersonalAccessTokenResponse{}, err
+	}
+	plain := constants.PersonalAccessTokenPrefix + secret
+
+	token := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: utils.HashToken(plain),
+		Prefix:    plain[:constants.PersonalAccessTokenDisplayLength],
+		Scope:     strings.Join(scopes, " "),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := now.AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := s.patRepo.Create(ctx, &token); err != nil {
+		log.Println("error while storing personal access token", err.Error())
+		return http.StatusInternalServerError, models.CreatePersonalAccessTokenResponse{}, err
+	}
+
+	s.logTokenEvent(ctx, constants.EventAccessTokenCreated, userID, token.ID)
+	log.Printf("user %d created personal access token %d", userID, token.ID)
+	return http.StatusCreated, models.CreatePersonalAccessTokenResponse{PersonalAccessToken: token, Token: plain}, nil
+}
+
+// RevokeToken revokes a token of the user. Tokens of other users are not found.
+func (s personalAccessTokenService) RevokeToken(ctx context.Context, userID, tokenID int64) (int, error) {
+	revoked, err := s.patRepo.Revoke(ctx, tokenID, userID)
+	if err != nil {
+		log.Println("error while revoking personal access token", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !revoked {
+		return http.StatusNotFound, errors.New(constants.ErrPersonalAccessTokenNotFound)
+	}
+
+	s.logTokenEvent(ctx, constants.EventAccessTokenRevoked, userID, tokenID)
+	log.Printf("user %d revoked personal access token %d", userID, tokenID)
+	return http.StatusOK, nil
+}
+
+// logTokenEvent records the creation or revocation of a token in the audit log
+func (s personalAccessTokenService) logTokenEvent(ctx context.Context, eventType string, userID, tokenID int64) {
+	s.audit.Log(ctx, models.AuthEvent{
+		Type:    eventType,
+		UserID:  userID,
+		Details: map[string]string{constants.EventDetailTokenID: strconv.FormatInt(tokenID, 10)},
+	})
+}
+
+// invalidTokenScopeError lists the scopes personal access tokens may carry
+func invalidTokenScopeError() error {
+	return fmt.Errorf(constants.ErrInvalidTokenScope, strings.Join(constants.PersonalAccessTokenScopes, ", "))
+}
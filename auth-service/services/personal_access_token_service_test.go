@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_personalAccessTokenService_CreateToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        models.CreatePersonalAccessTokenRequest
+		active     int
+		wantStatus int
+		wantErr    string
+		wantScope  string
+		wantExpiry bool
+	}{
+		{
+			name:       "token without expiry",
+			req:        models.CreatePersonalAccessTokenRequest{Name: " bulk import ", Scope: "blog:read blog:write"},
+			wantStatus: http.StatusCreated,
+			wantScope:  "blog:read blog:write",
+		},
+		{
+			name:       "token with expiry",
+			req:        models.CreatePersonalAccessTokenRequest{Name: "ci", Scope: "blog:read", ExpiresInDays: 30},
+			wantStatus: http.StatusCreated,
+			wantScope:  "blog:read",
+			wantExpiry: true,
+		},
+		{
+			name:       "missing name",
+			req:        models.CreatePersonalAccessTokenRequest{Name: "  ", Scope: "blog:read"},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "name is required",
+		},
+		{
+			name:       "scope that tokens cannot carry",
+			req:        models.CreatePersonalAccessTokenRequest{Name: "ci", Scope: "blog:read users:read"},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    fmt.Sprintf(constants.ErrInvalidTokenScope, "blog:read, blog:write"),
+		},
+		{
+			name:       "expiry too long",
+			req:        models.CreatePersonalAccessTokenRequest{Name: "ci", Scope: "blog:read", ExpiresInDays: constants.MaxPersonalAccessTokenDays + 1},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    fmt.Sprintf(constants.ErrInvalidTokenExpiry, constants.MaxPersonalAccessTokenDays),
+		},
+		{
+			name:       "too many tokens",
+			req:        models.CreatePersonalAccessTokenRequest{Name: "ci", Scope: "blog:read"},
+			active:     constants.MaxPersonalAccessTokens,
+			wantStatus: http.StatusConflict,
+			wantErr:    fmt.Sprintf(constants.ErrTooManyPersonalAccessTokens, constants.MaxPersonalAccessTokens),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patRepo := mocks.NewPersonalAccessTokenRepository(t)
+			patRepo.On("CountActiveForUser", mock.Anything, int64(7), mock.Anything).Return(tt.active, nil).Maybe()
+			var stored *models.PersonalAccessToken
+			patRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*models.PersonalAccessToken)
+				stored.ID = 4
+			}).Return(nil).Maybe()
+			svc := NewPersonalAccessTokenService(patRepo, &recordingAuditService{})
+
+			status, resp, err := svc.CreateToken(context.Background(), 7, tt.req)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, stored)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, stored)
+			assert.True(t, strings.HasPrefix(resp.Token, constants.PersonalAccessTokenPrefix))
+			assert.Equal(t, utils.HashToken(resp.Token), stored.TokenHash)
+			assert.Equal(t, resp.Token[:constants.PersonalAccessTokenDisplayLength], resp.Prefix)
+			assert.Equal(t, strings.TrimSpace(tt.req.Name), resp.Name)
+			assert.Equal(t, tt.wantScope, resp.Scope)
+			assert.Equal(t, int64(4), resp.ID)
+			assert.Equal(t, tt.wantExpiry, resp.ExpiresAt != nil)
+		})
+	}
+}
+
+func Test_personalAccessTokenService_RevokeToken(t *testing.T) {
+	patRepo := mocks.NewPersonalAccessTokenRepository(t)
+	patRepo.On("Revoke", mock.Anything, int64(4), int64(7)).Return(true, nil)
+	patRepo.On("Revoke", mock.Anything, int64(5), int64(7)).Return(false, nil)
+	svc := NewPersonalAccessTokenService(patRepo, &recordingAuditService{})
+
+	status, err := svc.RevokeToken(context.Background(), 7, 4)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, err = svc.RevokeToken(context.Background(), 7, 5)
+	assert.EqualError(t, err, constants.ErrPersonalAccessTokenNotFound)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func Test_tokenService_ValidatePersonalAccessToken(t *testing.T) {
+	const presented = constants.PersonalAccessTokenPrefix + "secret"
+	now := time.Now().UTC()
+	past, future, recently := now.Add(-time.Hour), now.Add(time.Hour), now.Add(-time.Second)
+
+	tests := []struct {
+		name      string
+		stored    models.PersonalAccessToken
+		user      models.User
+		wantErr   bool
+		wantTouch bool
+	}{
+		{
+			name:    "unknown token",
+			stored:  models.PersonalAccessToken{},
+			wantErr: true,
+		},
+		{
+			name:    "revoked token",
+			stored:  models.PersonalAccessToken{ID: 4, UserID: 7, Scope: "blog:read", RevokedAt: &past},
+			wantErr: true,
+		},
+		{
+			name:    "expired token",
+			stored:  models.PersonalAccessToken{ID: 4, UserID: 7, Scope: "blog:read", ExpiresAt: &past},
+			wantErr: true,
+		},
+		{
+			name:    "token of a disabled user",
+			stored:  models.PersonalAccessToken{ID: 4, UserID: 7, Scope: "blog:read"},
+			user:    models.User{ID: 7, Email: "dev@example.com", DisabledAt: &past},
+			wantErr: true,
+		},
+		{
+			name:      "first use is recorded",
+			stored:    models.PersonalAccessToken{ID: 4, UserID: 7, Scope: "blog:read blog:write", ExpiresAt: &future},
+			user:      models.User{ID: 7, Email: "dev@example.com", EmailVerifiedAt: &past},
+			wantTouch: true,
+		},
+		{
+			name:   "recent use is not recorded again",
+			stored: models.PersonalAccessToken{ID: 4, UserID: 7, Scope: "blog:read blog:write", LastUsedAt: &recently},
+			user:   models.User{ID: 7, Email: "dev@example.com", EmailVerifiedAt: &past},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patRepo := mocks.NewPersonalAccessTokenRepository(t)
+			patRepo.On("GetByHash", mock.Anything, utils.HashToken(presented)).Return(tt.stored, nil)
+			if tt.wantTouch {
+				patRepo.On("TouchLastUsed", mock.Anything, int64(4)).Return(nil)
+			}
+			userRepo := mocks.NewUserRepository(t)
+			userRepo.On("GetByID", mock.Anything, int64(7)).Return(tt.user, nil).Maybe()
+			roleRepo := newTestRoleRepository(t, []string{constants.RoleModerator}, []string{constants.PermissionBlogDeleteAny})
+
+			svc := NewTokenService(userRepo, nil, roleRepo, nil, patRepo, nil, nil, &recordingAuditService{}, newTestConfiguration())
+			claims, err := svc.ValidateAccessToken(context.Background(), presented)
+
+			if tt.wantErr {
+				assert.EqualError(t, err, constants.ErrInvalidPersonalAccessToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), claims.UserID)
+			assert.Equal(t, "7", claims.Subject)
+			assert.Equal(t, "dev@example.com", claims.Email)
+			assert.Equal(t, tt.stored.Scope, claims.Scope)
+			assert.Equal(t, []string{constants.PermissionBlogDeleteAny}, claims.Permissions)
+			assert.Empty(t, claims.SessionID)
+		})
+	}
+}
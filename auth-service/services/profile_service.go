@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// usernamePattern matches valid, already lower-cased usernames
+var usernamePattern = regexp.MustCompile(constants.UsernamePattern)
+
+// ProfileService lets users read and change their own account: profile fields, password and
+// email. Usernames are unique and give users a public profile. Users can also have their account
+// deleted, after a grace period in which they can change their mind.
+type ProfileService interface {
+	GetProfile(ctx context.Context, userID int64) (int, models.Profile, error)
+	UpdateProfile(ctx context.Context, userID int64, req models.UpdateProfileRequest) (int, models.Profile, error)
+	ChangePassword(ctx context.Context, userID int64, req models.ChangePasswordRequest) (int, error)
+	RequestEmailChange(ctx context.Context, userID int64, req models.ChangeEmailRequest) (int, error)
+	ConfirmEmailChange(ctx context.Context, token string) (int, error)
+	PublicProfile(ctx context.Context, username string) (int, models.PublicProfile, error)
+	ScheduleDeletion(ctx context.Context, userID int64, req models.DeleteAccountRequest) (int, models.AccountDeletionResponse, error)
+	CancelDeletion(ctx context.Context, userID int64) (int, error)
+}
+
+// profileService is an implementation of ProfileService
+type profileService struct {
+	userRepo   repositories.UserRepository
+	changeRepo repositories.EmailChangeRepository
+	tokenSvc   TokenService
+	throttle   LoginThrottle
+	hasher     PasswordHasher
+	policy     PasswordPolicy
+	mailer     Mailer
+	audit      AuditService
+	conf       config.Configuration
+}
+
+// NewProfileService returns a new instance of the profile service
+func NewProfileService(
+	userRepo repositories.UserRepository,
+	changeRepo repositories.EmailChangeRepository,
+	tokenSvc TokenService,
+	throttle LoginThrottle,
+	hasher PasswordHasher,
+	policy PasswordPolicy,
+	mailer Mailer,
+	audit AuditService,
+	conf config.Configuration,
+) ProfileService {
+	return &profileService{
+		userRepo:   userRepo,
+		changeRepo: changeRepo,
+		tokenSvc:   tokenSvc,
+		throttle:   throttle,
+		hasher:     hasher,
+		policy:     policy,
+		mailer:     mailer,
+		audit:      audit,
+		conf:       conf,
+	}
+}
+
+// GetProfile returns the profile of the user
+func (p profileService) GetProfile(ctx context.Context, userID int64) (int, models.Profile, error) {
+	status, user, err := p.getUser(ctx, userID)
+	if err != nil {
+		return status, models.Profile{}, err
+	}
+	return http.StatusOK, profileOf(user), nil
+}
+
+// UpdateProfile changes the fields present in the request and returns the updated profile
+func (p profileService) UpdateProfile(ctx context.Context, userID int64, req models.UpdateProfileRequest) (int, models.Profile, error) {
+	status, user, err := p.getUser(ctx, userID)
+	if err != nil {
+		return status, models.Profile{}, err
+	}
+
+	fields := []struct {
+		value *string
+		field *string
+		name  string
+		max   int
+	}{
+		{value: req.FirstName, field: &user.FirstName, name: "first name", max: constants.MaxNameLength},
+		{value: req.LastName, field: &user.LastName, name: "last name", max: constants.MaxNameLength},
+		{value: req.DisplayName, field: &user.DisplayName, name: "display name", max: constants.MaxDisplayNameLength},
+		{value: req.Bio, field: &user.Bio, name: "bio", max: constants.MaxBioLength},
+		{value: req.AvatarURL, field: &user.AvatarURL, name: "avatar URL", max: constants.MaxAvatarURLLength},
+	}
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		value := strings.TrimSpace(*f.value)
+		if utf8.RuneCountInString(value) > f.max {
+			return http.StatusBadRequest, models.Profile{}, fmt.Errorf(constants.ErrFieldTooLong, f.name, f.max)
+		}
+		*f.field = value
+	}
+	if req.AvatarURL != nil && user.AvatarURL != "" && !isHTTPURL(user.AvatarURL) {
+		return http.StatusBadRequest, models.Profile{}, errors.New(constants.ErrInvalidAvatarURL)
+	}
+
+	if req.Username != nil {
+		username := strings.ToLower(strings.TrimSpace(*req.Username))
+		if username != "" && !usernamePattern.MatchString(username) {
+			return http.StatusBadRequest, models.Profile{}, errors.New(constants.ErrInvalidUsername)
+		}
+		if username != "" && username != user.Username {
+			owner, err := p.userRepo.GetByUsername(ctx, username)
+			if err != nil {
+				return http.StatusInternalServerError, models.Profile{}, err
+			}
+			if owner.ID != 0 {
+				return http.StatusConflict, models.Profile{}, errors.New(constants.ErrUsernameTaken)
+			}
+		}
+		user.Username = username
+	}
+
+	if err := p.userRepo.UpdateProfile(ctx, user); err != nil {
+		// another user took the username since it was checked
+		if errors.Is(err, repositories.ErrUsernameTaken) {
+			return http.StatusConflict, models.Profile{}, err
+		}
+		log.Println("error while updating profile", err.Error())
+		return http.StatusInternalServerError, models.Profile{}, err
+	}
+	return http.StatusOK, profileOf(user), nil
+}
+
+// ChangePassword sets a new password after checking the current one. Every session of the user is
+// logged out, including the one that made the change, and the user is told by email.
+func (p profileService) ChangePassword(ctx context.Context, userID int64, req models.ChangePasswordRequest) (int, error) {
+	status, user, err := p.getUser(ctx, userID)
+	if err != nil {
+		return status, err
+	}
+	if status, err := p.checkPassword(ctx, user, req.CurrentPassword); err != nil {
+		return status, err
+	}
+	if err := p.policy.Validate(req.NewPassword); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	hashedPassword, err := p.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if err := p.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		log.Println("error while updating password", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if status, err := p.tokenSvc.LogoutEverywhere(ctx, user.ID); err != nil {
+		return status, err
+	}
+
+	p.notify(ctx, user.Email, "Your password was changed",
+		"The password of your account was just changed and all sessions were logged out.\r\n\r\n"+
+			"If this was not you, reset your password right away.")
+	log.Printf("password changed for user %d", user.ID)
+	return http.StatusOK, nil
+}
+
+// RequestEmailChange mails a confirmation link to the new address. The email only changes once
+// the link was opened, which proves the user owns the new address.
+func (p profileService) RequestEmailChange(ctx context.Context, userID int64, req models.ChangeEmailRequest) (int, error) {
+	appConf := p.conf.AppConfig()
+
+	status, user, err := p.getUser(ctx, userID)
+	if err != nil {
+		return status, err
+	}
+	if status, err := p.checkPassword(ctx, user, req.Password); err != nil {
+		return status, err
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if addr, err := mail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return http.StatusBadRequest, errors.New(constants.ErrInvalidEmail)
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return http.StatusBadRequest, errors.New(constants.ErrSameEmail)
+	}
+	if status, err := p.checkEmailAvailable(ctx, user.ID, newEmail); err != nil {
+		return status, err
+	}
+
+	if err := p.changeRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	stored := models.EmailChangeToken{
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(appConf.EmailVerificationTTL()),
+	}
+	if err := p.changeRepo.Create(ctx, &stored); err != nil {
+		log.Println("error while storing email change token", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	link, err := url.Parse(appConf.Issuer() + constants.PathConfirmEmailChange)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	err = p.mailer.Send(ctx, models.Email{
+		From:    appConf.MailFrom(),
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Someone asked to use this address for their account.\r\n\r\n"+
+			"Open this link within %s to confirm the change:\r\n%s\r\n\r\n"+
+			"If this was not you, ignore this email.",
+			appConf.EmailVerificationTTL(), link.String()),
+	})
+	if err != nil {
+		log.Printf("error while sending email change link to user %d: %v", user.ID, err)
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusAccepted, nil
+}
+
+// ConfirmEmailChange changes the email of a user with the token from a confirmation link and
+// tells the old address about it
+func (p profileService) ConfirmEmailChange(ctx context.Context, token string) (int, error) {
+	errInvalid := errors.New(constants.ErrInvalidEmailChangeToken)
+	if token == "" {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	stored, err := p.changeRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if stored.ID == 0 || stored.UsedAt != nil || time.Now().UTC().After(stored.ExpiresAt) {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	// tokens are single use; losing this race means a concurrent request used it
+	marked, err := p.changeRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		log.Println("error while consuming email change token", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !marked {
+		return http.StatusBadRequest, errInvalid
+	}
+
+	status, user, err := p.getUser(ctx, stored.UserID)
+	if err != nil {
+		return status, err
+	}
+	// the address may have been taken since the link was sent
+	if status, err := p.checkEmailAvailable(ctx, user.ID, stored.NewEmail); err != nil {
+		return status, err
+	}
+
+	if err := p.userRepo.UpdateEmail(ctx, user.ID, stored.NewEmail); err != nil {
+		log.Println("error while updating email", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if err := p.changeRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		log.Println("error while invalidating email change tokens", err.Error())
+	}
+
+	p.notify(ctx, user.Email, "Your email address was changed",
+		fmt.Sprintf("The email address of your account was changed to %s.\r\n\r\n"+
+			"If this was not you, contact support right away.", stored.NewEmail))
+	log.Printf("email changed for user %d", user.ID)
+	return http.StatusOK, nil
+}
+
+// PublicProfile returns the public profile of the user with the given username
+func (p profileService) PublicProfile(ctx context.Context, username string) (int, models.PublicProfile, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !usernamePattern.MatchString(username) {
+		return http.StatusNotFound, models.PublicProfile{}, errors.New(constants.ErrUserNotFound)
+	}
+
+	user, err := p.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return http.StatusInternalServerError, models.PublicProfile{}, err
+	}
+	if user.ID == 0 {
+		return http.StatusNotFound, models.PublicProfile{}, errors.New(constants.ErrUserNotFound)
+	}
+
+	return http.StatusOK, models.PublicProfile{
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+	}, nil
+}
+
+// ScheduleDeletion has the account of the user deleted once ACCOUNT_DELETION_GRACE has passed.
+// Until then the account works as before, so the user can still export their data, and can keep
+// the account with CancelDeletion.
+func (p profileService) ScheduleDeletion(ctx context.Context, userID int64, req models.DeleteAccountRequest) (int, models.AccountDeletionResponse, error) {
+	status, user, err := p.getUser(ctx, userID)
+	if err != nil {
+		return status, models.AccountDeletionResponse{}, err
+	}
+	if user.DeletionScheduled() {
+		return http.StatusConflict, models.AccountDeletionResponse{}, errors.New(constants.ErrDeletionAlreadyScheduled)
+	}
+	if status, err := p.checkPassword(ctx, user, req.Password); err != nil {
+		return status, models.AccountDeletionResponse{}, err
+	}
+
+	deleteAt := time.Now().UTC().Add(p.conf.AppConfig().AccountDeletionGrace())
+	scheduled, err := p.userRepo.ScheduleDeletion(ctx, user.ID, deleteAt)
+	if err != nil {
+		log.Println("error while scheduling account deletion", err.Error())
+		return http.StatusInternalServerError, models.AccountDeletionResponse{}, err
+	}
+	// a concurrent request scheduled it first
+	if !scheduled {
+		return http.StatusConflict, models.AccountDeletionResponse{}, errors.New(constants.ErrDeletionAlreadyScheduled)
+	}
+
+	p.logDeletionEvent(ctx, constants.EventDeletionScheduled, user)
+	p.notify(ctx, user.Email, "Your account will be deleted",
+		fmt.Sprintf("Your account and your blog posts will be deleted on %s.\r\n\r\n"+
+			"Until then you can sign in, download your data and cancel the deletion. "+
+			"If you did not ask for this, sign in, cancel the deletion and change your password.",
+			deleteAt.Format(time.RFC1123)))
+	log.Printf("deletion of user %d scheduled for %s", user.ID, deleteAt.Format(time.RFC3339))
+	return http.StatusAccepted, models.AccountDeletionResponse{DeletionScheduledAt: deleteAt}, nil
+}
+
+// CancelDeletion keeps an account that was scheduled for deletion
+func (p profileService) CancelDeletion(ctx context.Context, userID int64) (int, error) {
+	status, user, err := p.getUser(ctx, userID)
+	if err != nil {
+		return status, err
+	}
+
+	cancelled, err := p.userRepo.CancelDeletion(ctx, user.ID)
+	if err != nil {
+		log.Println("error while cancelling account deletion", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !cancelled {
+		return http.StatusConflict, errors.New(constants.ErrDeletionNotScheduled)
+	}
+
+	p.logDeletionEvent(ctx, constants.EventDeletionCancelled, user)
+	log.Printf("deletion of user %d cancelled", user.ID)
+	return http.StatusOK, nil
+}
+
+// logDeletionEvent records a change to the scheduled deletion of an account in the audit log
+func (p profileService) logDeletionEvent(ctx context.Context, eventType string, user models.User) {
+	p.audit.Log(ctx, models.AuthEvent{
+		Type:   eventType,
+		UserID: user.ID,
+		Email:  user.Email,
+	})
+}
+
+// getUser fetches a user, answering 404 when the user no longer exists
+func (p profileService) getUser(ctx context.Context, userID int64) (int, models.User, error) {
+	user, err := p.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, models.User{}, err
+	}
+	if user.ID == 0 {
+		return http.StatusNotFound, models.User{}, errors.New(constants.ErrUserNotFound)
+	}
+	return http.StatusOK, user, nil
+}
+
+// checkPassword checks the current password of a user. Wrong passwords count as failed logins,
+// so a stolen access token cannot be used to guess the password.
+func (p profileService) checkPassword(ctx context.Context, user models.User, password string) (int, error) {
+	ip := utils.ClientIPFromContext(ctx)
+	if err := p.throttle.Check(ctx, user.Email, ip); err != nil {
+		var throttled *models.LoginThrottledError
+		if errors.As(err, &throttled) {
+			return throttled.Status, throttled
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	matched, err := p.hasher.Verify(password, user.Password)
+	if err != nil {
+		log.Printf("error while verifying password of user %d: %v", user.ID, err)
+	}
+	if !matched {
+		if err := p.throttle.RecordFailure(ctx, user.Email, ip); err != nil {
+			log.Println("error while recording failed login", err.Error())
+		}
+		return http.StatusForbidden, errors.New(constants.ErrInvalidCurrentPassword)
+	}
+	return http.StatusOK, nil
+}
+
+// checkEmailAvailable answers 409 when another user has the email
+func (p profileService) checkEmailAvailable(ctx context.Context, userID int64, email string) (int, error) {
+	owner, err := p.userRepo.GetByUserEmail(ctx, email)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if owner.ID != 0 && owner.ID != userID {
+		return http.StatusConflict, errors.New(constants.ErrEmailTaken)
+	}
+	return http.StatusOK, nil
+}
+
+// notify mails a security notice to the user. Failures are logged since the change already happened.
+func (p profileService) notify(ctx context.Context, to, subject, body string) {
+	err := p.mailer.Send(ctx, models.Email{
+		From:    p.conf.AppConfig().MailFrom(),
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+	if err != nil {
+		log.Printf("error while sending %q notice: %v", subject, err)
+	}
+}
+
+// profileOf returns the profile view of a user
+func profileOf(user models.User) models.Profile {
+	return models.Profile{
+		ID:            user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified(),
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		DisplayName:   user.DisplayName,
+		Username:      user.Username,
+		Bio:           user.Bio,
+		AvatarURL:     user.AvatarURL,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
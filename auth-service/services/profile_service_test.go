@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// strPtr returns a pointer to s, for the optional fields of update requests
+func strPtr(s string) *string {
+	return &s
+}
+
+func Test_profileService_UpdateProfile(t *testing.T) {
+	user := models.User{ID: 7, Email: "asif@example.com", FirstName: "Asif", Username: "asif", Bio: "old bio"}
+
+	tests := []struct {
+		name       string
+		req        models.UpdateProfileRequest
+		taken      bool
+		raced      bool
+		wantStatus int
+		wantErr    string
+		want       models.User
+	}{
+		{
+			name:       "present fields change and absent ones stay",
+			req:        models.UpdateProfileRequest{DisplayName: strPtr("  Asif K. "), Username: strPtr("Asif_K"), AvatarURL: strPtr("https://cdn.example.com/a.png")},
+			wantStatus: http.StatusOK,
+			want:       models.User{ID: 7, Email: user.Email, FirstName: "Asif", DisplayName: "Asif K.", Username: "asif_k", Bio: "old bio", AvatarURL: "https://cdn.example.com/a.png"},
+		},
+		{
+			name:       "empty strings clear fields",
+			req:        models.UpdateProfileRequest{Username: strPtr(""), Bio: strPtr("")},
+			wantStatus: http.StatusOK,
+			want:       models.User{ID: 7, Email: user.Email, FirstName: "Asif"},
+		},
+		{name: "invalid username", req: models.UpdateProfileRequest{Username: strPtr("a b")}, wantStatus: http.StatusBadRequest, wantErr: constants.ErrInvalidUsername},
+		{name: "taken username", req: models.UpdateProfileRequest{Username: strPtr("taken")}, taken: true, wantStatus: http.StatusConflict, wantErr: constants.ErrUsernameTaken},
+		{name: "username taken after the check", req: models.UpdateProfileRequest{Username: strPtr("taken")}, raced: true, wantStatus: http.StatusConflict, wantErr: constants.ErrUsernameTaken},
+		{
+			name:       "bio too long",
+			req:        models.UpdateProfileRequest{Bio: strPtr(strings.Repeat("x", constants.MaxBioLength+1))},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    fmt.Sprintf(constants.ErrFieldTooLong, "bio", constants.MaxBioLength),
+		},
+		{name: "avatar is not a web URL", req: models.UpdateProfileRequest{AvatarURL: strPtr("javascript:alert(1)")}, wantStatus: http.StatusBadRequest, wantErr: constants.ErrInvalidAvatarURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewUserRepository(t)
+			userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+			owner := models.User{}
+			if tt.taken {
+				owner = models.User{ID: 8, Username: "taken"}
+			}
+			userRepo.On("GetByUsername", mock.Anything, mock.Anything).Return(owner, nil).Maybe()
+			if tt.wantStatus == http.StatusOK {
+				userRepo.On("UpdateProfile", mock.Anything, tt.want).Return(nil)
+			}
+			if tt.raced {
+				userRepo.On("UpdateProfile", mock.Anything, mock.Anything).Return(repositories.ErrUsernameTaken)
+			}
+			svc := NewProfileService(userRepo, nil, nil, nil, nil, nil, nil, &recordingAuditService{}, newTestConfiguration())
+
+			status, profile, err := svc.UpdateProfile(context.Background(), user.ID, tt.req)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.Username, profile.Username)
+		})
+	}
+}
+
+func Test_profileService_ChangePassword(t *testing.T) {
+	hasher := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024)
+	hash, err := hasher.Hash("old password")
+	require.NoError(t, err)
+	user := models.User{ID: 7, Email: "asif@example.com", Password: hash}
+	const newPassword = "new password"
+
+	t.Run("a wrong current password is refused and counted", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		throttle := newTestLoginThrottle()
+		conf := newTestConfiguration()
+		svc := NewProfileService(userRepo, nil, nil, throttle, hasher, NewPasswordPolicy(conf), &recordingMailer{}, &recordingAuditService{}, conf)
+
+		status, err := svc.ChangePassword(context.Background(), user.ID, models.ChangePasswordRequest{CurrentPassword: "guess", NewPassword: newPassword})
+
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.EqualError(t, err, constants.ErrInvalidCurrentPassword)
+		throttledError(t, throttle.Check(context.Background(), user.Email, ""))
+	})
+
+	t.Run("the right current password sets the new one and logs out", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		userRepo.On("UpdatePassword", mock.Anything, user.ID, mock.MatchedBy(func(hash string) bool {
+			matched, err := hasher.Verify(newPassword, hash)
+			return err == nil && matched
+		})).Return(nil)
+		refreshRepo := mocks.NewRefreshTokenRepository(t)
+		refreshRepo.On("RevokeAllForUser", mock.Anything, user.ID).Return(nil)
+		revokedRepo := mocks.NewRevokedTokenRepository(t)
+		revokedRepo.On("SetUserCutoff", mock.Anything, mock.Anything).Return(nil)
+		sessionRepo := mocks.NewSessionRepository(t)
+		sessionRepo.On("RevokeAllForUser", mock.Anything, user.ID).Return(nil)
+		mailer := &recordingMailer{}
+		conf := newTestConfiguration()
+		tokenSvc := NewTokenService(userRepo, refreshRepo, nil, sessionRepo, nil, NewRevocationStore(revokedRepo, sessionRepo, conf), nil, &recordingAuditService{}, conf)
+		svc := NewProfileService(userRepo, nil, tokenSvc, newTestLoginThrottle(), hasher, NewPasswordPolicy(conf), mailer, &recordingAuditService{}, conf)
+
+		status, err := svc.ChangePassword(context.Background(), user.ID, models.ChangePasswordRequest{CurrentPassword: "old password", NewPassword: newPassword})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		require.Len(t, mailer.sent, 1)
+		assert.Equal(t, user.Email, mailer.sent[0].To)
+	})
+}
+
+func Test_profileService_EmailChange(t *testing.T) {
+	hasher := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024)
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	user := models.User{ID: 7, Email: "asif@example.com", Password: hash}
+	const newEmail = "asif@new.example.com"
+
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	userRepo.On("GetByUserEmail", mock.Anything, newEmail).Return(models.User{}, nil)
+	userRepo.On("GetByUserEmail", mock.Anything, "taken@example.com").Return(models.User{ID: 8}, nil)
+	changeRepo := mocks.NewEmailChangeRepository(t)
+	changeRepo.On("InvalidateForUser", mock.Anything, user.ID).Return(nil)
+	var stored models.EmailChangeToken
+	changeRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = *args.Get(1).(*models.EmailChangeToken)
+		stored.ID = 3
+	}).Return(nil)
+	mailer := &recordingMailer{}
+	svc := NewProfileService(userRepo, changeRepo, nil, newTestLoginThrottle(), hasher, nil, mailer, &recordingAuditService{}, newTestConfiguration())
+	ctx := context.Background()
+
+	status, err := svc.RequestEmailChange(ctx, user.ID, models.ChangeEmailRequest{NewEmail: "taken@example.com", Password: "correct horse"})
+	assert.Equal(t, http.StatusConflict, status)
+	assert.EqualError(t, err, constants.ErrEmailTaken)
+
+	status, err = svc.RequestEmailChange(ctx, user.ID, models.ChangeEmailRequest{NewEmail: newEmail, Password: "correct horse"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+
+	// the link goes to the new address and only the hash of its token is stored
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, newEmail, mailer.sent[0].To)
+	body := mailer.sent[0].Body
+	link, err := url.Parse(strings.Fields(body[strings.Index(body, constants.DefaultTokenIssuer+constants.PathConfirmEmailChange):])[0])
+	require.NoError(t, err)
+	token := link.Query().Get("token")
+	assert.Equal(t, utils.HashToken(token), stored.TokenHash)
+	assert.Equal(t, newEmail, stored.NewEmail)
+
+	// confirming changes the email and tells the old address
+	changeRepo.On("GetByHash", mock.Anything, stored.TokenHash).Return(stored, nil)
+	changeRepo.On("MarkUsed", mock.Anything, stored.ID).Return(true, nil)
+	userRepo.On("UpdateEmail", mock.Anything, user.ID, newEmail).Return(nil)
+
+	status, err = svc.ConfirmEmailChange(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	require.Len(t, mailer.sent, 2)
+	assert.Equal(t, user.Email, mailer.sent[1].To)
+	assert.WithinDuration(t, time.Now().UTC().Add(constants.DefaultEmailVerificationTTL), stored.ExpiresAt, time.Minute)
+}
+
+func Test_profileService_PublicProfile(t *testing.T) {
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("GetByUsername", mock.Anything, "asif").Return(models.User{ID: 7, Email: "asif@example.com", Username: "asif", Bio: "hi"}, nil)
+	userRepo.On("GetByUsername", mock.Anything, "nobody").Return(models.User{}, nil)
+	svc := NewProfileService(userRepo, nil, nil, nil, nil, nil, nil, &recordingAuditService{}, newTestConfiguration())
+
+	status, profile, err := svc.PublicProfile(context.Background(), "Asif")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, models.PublicProfile{Username: "asif", Bio: "hi"}, profile)
+
+	status, _, err = svc.PublicProfile(context.Background(), "nobody")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.EqualError(t, err, constants.ErrUserNotFound)
+}
+
+func Test_profileService_ScheduleDeletion(t *testing.T) {
+	hasher := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024)
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	user := models.User{ID: 7, Email: "asif@example.com", Password: hash}
+
+	t.Run("a wrong password is refused", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		svc := NewProfileService(userRepo, nil, nil, newTestLoginThrottle(), hasher, nil, &recordingMailer{}, &recordingAuditService{}, newTestConfiguration())
+
+		status, _, err := svc.ScheduleDeletion(context.Background(), user.ID, models.DeleteAccountRequest{Password: "guess"})
+
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.EqualError(t, err, constants.ErrInvalidCurrentPassword)
+	})
+
+	t.Run("deletion is scheduled after the grace period and announced", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		userRepo.On("ScheduleDeletion", mock.Anything, user.ID, mock.MatchedBy(func(at time.Time) bool {
+			return at.Sub(time.Now().UTC().Add(constants.DefaultAccountDeletionGrace)).Abs() < time.Minute
+		})).Return(true, nil)
+		mailer := &recordingMailer{}
+		events := &recordingAuditService{}
+		svc := NewProfileService(userRepo, nil, nil, newTestLoginThrottle(), hasher, nil, mailer, events, newTestConfiguration())
+
+		status, resp, err := svc.ScheduleDeletion(context.Background(), user.ID, models.DeleteAccountRequest{Password: "correct horse"})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.WithinDuration(t, time.Now().UTC().Add(constants.DefaultAccountDeletionGrace), resp.DeletionScheduledAt, time.Minute)
+		require.Len(t, mailer.sent, 1)
+		assert.Equal(t, user.Email, mailer.sent[0].To)
+		require.Len(t, events.events, 1)
+		assert.Equal(t, constants.EventDeletionScheduled, events.events[0].Type)
+	})
+
+	t.Run("an account is only scheduled once", func(t *testing.T) {
+		scheduled := user
+		deleteAt := time.Now().UTC().Add(time.Hour)
+		scheduled.DeletionScheduledAt = &deleteAt
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(scheduled, nil)
+		svc := NewProfileService(userRepo, nil, nil, newTestLoginThrottle(), hasher, nil, &recordingMailer{}, &recordingAuditService{}, newTestConfiguration())
+
+		status, _, err := svc.ScheduleDeletion(context.Background(), user.ID, models.DeleteAccountRequest{Password: "correct horse"})
+
+		assert.Equal(t, http.StatusConflict, status)
+		assert.EqualError(t, err, constants.ErrDeletionAlreadyScheduled)
+	})
+}
+
+func Test_profileService_CancelDeletion(t *testing.T) {
+	user := models.User{ID: 7, Email: "asif@example.com"}
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	userRepo.On("CancelDeletion", mock.Anything, user.ID).Return(true, nil).Once()
+	userRepo.On("CancelDeletion", mock.Anything, user.ID).Return(false, nil).Once()
+	events := &recordingAuditService{}
+	svc := NewProfileService(userRepo, nil, nil, nil, nil, nil, nil, events, newTestConfiguration())
+
+	status, err := svc.CancelDeletion(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, constants.EventDeletionCancelled, events.events[0].Type)
+
+	status, err = svc.CancelDeletion(context.Background(), user.ID)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.EqualError(t, err, constants.ErrDeletionNotScheduled)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// RevocationStore keeps revoked access tokens and sessions in Postgres and serves lookups from memory.
+// The in-memory copy is refreshed periodically so revocations made by other instances are picked up.
+type RevocationStore interface {
+	utils.RevocationChecker
+	RevokeToken(ctx context.Context, jti string, userID int64, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+	Load(ctx context.Context) error
+	Run(ctx context.Context)
+}
+
+// revocationStore is an implementation of RevocationStore
+type revocationStore struct {
+	repo        repositories.RevokedTokenRepository
+	sessionRepo repositories.SessionRepository
+	conf        config.Configuration
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time // jti -> token expiry
+	sessions map[string]struct{}  // ids of revoked sessions
+	cutoffs  map[int64]time.Time  // user id -> tokens issued before this time are revoked
+}
+
+// NewRevocationStore returns a new instance of the revocation store
+func NewRevocationStore(
+	repo repositories.RevokedTokenRepository,
+	sessionRepo repositories.SessionRepository,
+	conf config.Configuration,
+) RevocationStore {
+	return &revocationStore{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		conf:        conf,
+		tokens:      map[string]time.Time{},
+		sessions:    map[string]struct{}{},
+		cutoffs:     map[int64]time.Time{},
+	}
+}
+
+// IsRevoked reports whether the token was revoked individually, with its session or by a
+// "logout everywhere"
+func (s *revocationStore) IsRevoked(jti, sessionID string, userID int64, issuedAt time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if jti != "" {
+		if _, ok := s.tokens[jti]; ok {
+			return true
+		}
+	}
+	if sessionID != "" {
+		if _, ok := s.sessions[sessionID]; ok {
+			return true
+		}
+	}
+	if cutoff, ok := s.cutoffs[userID]; ok && issuedAt.Before(cutoff) {
+		return true
+	}
+	return false
+}
+
+// RevokeToken revokes a single access token until it expires
+func (s *revocationStore) RevokeToken(ctx context.Context, jti string, userID int64, expiresAt time.Time) error {
+	err := s.repo.Create(ctx, models.RevokedToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = expiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeSession revokes every access token of a session
+func (s *revocationStore) RevokeSession(ctx context.Context, sessionID string) error {
+	if err := s.sessionRepo.RevokeByFamily(ctx, sessionID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.sessions[sessionID] = struct{}{}
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeAllForUser revokes every access token issued to the user so far and ends their sessions.
+// The cutoff is rounded up to the microsecond Postgres keeps, so that it covers every token issued
+// before it both in memory and once reloaded.
+func (s *revocationStore) RevokeAllForUser(ctx context.Context, userID int64) error {
+	revokedBefore := time.Now().UTC().Truncate(time.Microsecond).Add(time.Microsecond)
+	cutoff := models.UserTokenCutoff{UserID: userID, RevokedBefore: revokedBefore}
+	if err := s.repo.SetUserCutoff(ctx, cutoff); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if cutoff.RevokedBefore.After(s.cutoffs[userID]) {
+		s.cutoffs[userID] = cutoff.RevokedBefore
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Load replaces the in-memory cache with the revocations stored in the database
+func (s *revocationStore) Load(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	revoked, err := s.repo.ListActive(ctx, now)
+	if err != nil {
+		return err
+	}
+	// a cutoff or session revoked longer ago than the access token lifetime cannot match a token
+	// that is still valid
+	validSince := now.Add(-s.conf.AppConfig().AccessTokenTTL())
+	cutoffs, err := s.repo.ListUserCutoffs(ctx, validSince)
+	if err != nil {
+		return err
+	}
+	revokedSessions, err := s.sessionRepo.ListRevokedFamilies(ctx, validSince)
+	if err != nil {
+		return err
+	}
+
+	tokens := make(map[string]time.Time, len(revoked))
+	for _, token := range revoked {
+		tokens[token.JTI] = token.ExpiresAt
+	}
+	sessions := make(map[string]struct{}, len(revokedSessions))
+	for _, sessionID := range revokedSessions {
+		sessions[sessionID] = struct{}{}
+	}
+	userCutoffs := make(map[int64]time.Time, len(cutoffs))
+	for _, cutoff := range cutoffs {
+		userCutoffs[cutoff.UserID] = cutoff.RevokedBefore
+	}
+
+	s.mu.Lock()
+	s.tokens = tokens
+	s.sessions = sessions
+	s.cutoffs = userCutoffs
+	s.mu.Unlock()
+	return nil
+}
+
+// Run periodically purges expired revocations from the database and reloads the cache until ctx is done
+func (s *revocationStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.RevocationSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.cleanup(ctx)
+			if err := s.Load(ctx); err != nil {
+				log.Printf("error reloading revoked tokens: %v", err)
+			}
+		}
+	}
+}
+
+// cleanup deletes revocations that can no longer match a valid token, and sessions that ended
+// for good: revoked ones once their access tokens expired, and those unused for longer than the
+// refresh token lifetime
+func (s *revocationStore) cleanup(ctx context.Context) {
+	appConf := s.conf.AppConfig()
+	now := time.Now().UTC()
+	if _, err := s.repo.DeleteExpired(ctx, now); err != nil {
+		log.Printf("error deleting expired revoked tokens: %v", err)
+	}
+	if _, err := s.repo.DeleteUserCutoffs(ctx, now.Add(-appConf.AccessTokenTTL())); err != nil {
+		log.Printf("error deleting expired token cutoffs: %v", err)
+	}
+	if _, err := s.sessionRepo.DeleteEnded(ctx, now.Add(-appConf.AccessTokenTTL()), now.Add(-appConf.RefreshTokenTTL())); err != nil {
+		log.Printf("error deleting ended sessions: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configmocks "auth-service/config/mocks"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_revocationStore_IsRevoked(t *testing.T) {
+	repo := mocks.NewRevokedTokenRepository(t)
+	conf := configmocks.NewConfiguration(t)
+	conf.On("AppConfig").Return(newTestAppConfig())
+
+	now := time.Now().UTC()
+	repo.On("ListActive", mock.Anything, mock.Anything).Return([]models.RevokedToken{
+		{JTI: "loaded-jti", UserID: 1, ExpiresAt: now.Add(time.Minute)},
+	}, nil)
+	repo.On("ListUserCutoffs", mock.Anything, mock.Anything).Return([]models.UserTokenCutoff(nil), nil)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("SetUserCutoff", mock.Anything, mock.Anything).Return(nil)
+	sessionRepo := mocks.NewSessionRepository(t)
+	sessionRepo.On("ListRevokedFamilies", mock.Anything, mock.Anything).Return([]string{"loaded-session"}, nil)
+	sessionRepo.On("RevokeByFamily", mock.Anything, "new-session").Return(nil)
+	sessionRepo.On("RevokeAllForUser", mock.Anything, int64(2)).Return(nil)
+
+	store := NewRevocationStore(repo, sessionRepo, conf)
+	ctx := context.Background()
+	assert.NoError(t, store.Load(ctx))
+
+	issuedAt := now.Add(-time.Minute)
+	assert.True(t, store.IsRevoked("loaded-jti", "", 1, issuedAt), "token loaded from the database")
+	assert.False(t, store.IsRevoked("other-jti", "session", 1, issuedAt))
+
+	assert.NoError(t, store.RevokeToken(ctx, "new-jti", 1, now.Add(time.Minute)))
+	assert.True(t, store.IsRevoked("new-jti", "", 1, issuedAt), "token revoked at runtime")
+
+	assert.True(t, store.IsRevoked("other-jti", "loaded-session", 1, issuedAt), "session loaded from the database")
+	assert.NoError(t, store.RevokeSession(ctx, "new-session"))
+	assert.True(t, store.IsRevoked("other-jti", "new-session", 1, issuedAt), "session revoked at runtime")
+
+	beforeLogout := time.Now().UTC()
+	assert.NoError(t, store.RevokeAllForUser(ctx, 2))
+	afterLogout := time.Now().UTC().Add(time.Microsecond)
+	assert.True(t, store.IsRevoked("any-jti", "", 2, issuedAt), "token issued before logout everywhere")
+	assert.True(t, store.IsRevoked("any-jti", "", 2, beforeLogout), "token issued right before logout everywhere")
+	assert.False(t, store.IsRevoked("any-jti", "", 2, afterLogout), "token issued right after logout everywhere")
+	assert.False(t, store.IsRevoked("any-jti", "", 2, now.Add(time.Hour)), "token issued after logout everywhere")
+	assert.False(t, store.IsRevoked("any-jti", "", 3, issuedAt), "other users are unaffected")
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+)
+
+// RoleService manages the roles granted to users and answers permission checks
+type RoleService interface {
+	ListRoles(ctx context.Context) (int, []models.Role, error)
+	UserRoles(ctx context.Context, userID int64) (int, models.UserRoles, error)
+	AssignRole(ctx context.Context, actorID, userID int64, role string) (int, error)
+	RemoveRole(ctx context.Context, actorID, userID int64, role string) (int, error)
+	HasPermission(ctx context.Context, userID int64, permission string) (bool, error)
+	BootstrapAdmin(ctx context.Context, email string) error
+}
+
+// roleService is an implementation of RoleService
+type roleService struct {
+	roleRepo repositories.RoleRepository
+	userRepo repositories.UserRepository
+	audit    AuditService
+}
+
+// NewRoleService returns a new instance of the role service
+func NewRoleService(
+	roleRepo repositories.RoleRepository,
+	userRepo repositories.UserRepository,
+	audit AuditService,
+) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		audit:    audit,
+	}
+}
+
+// ListRoles returns every role with its permissions
+func (s roleService) ListRoles(ctx context.Context) (int, []models.Role, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		log.Println("error while listing roles", err.Error())
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, roles, nil
+}
+
+// UserRoles returns the roles of a user and the permissions they grant
+func (s roleService) UserRoles(ctx context.Context, userID int64) (int, models.UserRoles, error) {
+	if status, err := s.userExists(ctx, userID); err != nil {
+		return status, models.UserRoles{}, err
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user roles", err.Error())
+		return http.StatusInternalServerError, models.UserRoles{}, err
+	}
+	permissions, err := s.roleRepo.GetUserPermissions(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user permissions", err.Error())
+		return http.StatusInternalServerError, models.UserRoles{}, err
+	}
+	return http.StatusOK, models.UserRoles{UserID: userID, Roles: roles, Permissions: permissions}, nil
+}
+
+// AssignRole grants a role to a user. Granting a role the user already has is not an error.
+// The change shows up in the user's access tokens once they are refreshed.
+func (s roleService) AssignRole(ctx context.Context, actorID, userID int64, role string) (int, error) {
+	status, found, err := s.resolve(ctx, userID, role)
+	if err != nil {
+		return status, err
+	}
+
+	assigned, err := s.roleRepo.Assign(ctx, userID, found.ID, actorID)
+	if err != nil {
+		log.Println("error while assigning role", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if assigned {
+		s.audit.Record(ctx, actorID, constants.AuditRoleAssigned, userID, role)
+		log.Printf("user %d granted role %s to user %d", actorID, role, userID)
+	}
+	return http.StatusOK, nil
+}
+
+// RemoveRole takes a role away from a user. Admins cannot drop their own admin role, so there
+// is always someone left who can manage roles.
+func (s roleService) RemoveRole(ctx context.Context, actorID, userID int64, role string) (int, error) {
+	if actorID == userID && role == constants.RoleAdmin {
+		return http.StatusBadRequest, errors.New(constants.ErrCannotRemoveOwnAdmin)
+	}
+
+	status, found, err := s.resolve(ctx, userID, role)
+	if err != nil {
+		return status, err
+	}
+
+	removed, err := s.roleRepo.Remove(ctx, userID, found.ID)
+	if err != nil {
+		log.Println("error while removing role", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if removed {
+		s.audit.Record(ctx, actorID, constants.AuditRoleRemoved, userID, role)
+		log.Printf("user %d removed role %s from user %d", actorID, role, userID)
+	}
+	return http.StatusOK, nil
+}
+
+// HasPermission reports whether any role of the user grants permission. It reads the database,
+// so a removed role takes effect immediately rather than when the user's token expires.
+func (s roleService) HasPermission(ctx context.Context, userID int64, permission string) (bool, error) {
+	permissions, err := s.roleRepo.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(permissions, permission), nil
+}
+
+// BootstrapAdmin grants the admin role to the user with the given email. A missing user is only
+// logged, since the admin may register after the first start.
+func (s roleService) BootstrapAdmin(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByUserEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		log.Printf("bootstrap admin %s has not registered yet", email)
+		return nil
+	}
+
+	role, err := s.roleRepo.GetByName(ctx, constants.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if role.ID == 0 {
+		return errors.New(constants.ErrRoleNotFound)
+	}
+
+	assigned, err := s.roleRepo.Assign(ctx, user.ID, role.ID, 0)
+	if err != nil {
+		return err
+	}
+	if assigned {
+		log.Printf("granted admin role to bootstrap admin %d", user.ID)
+	}
+	return nil
+}
+
+// resolve checks that the user exists and looks up the role by name
+func (s roleService) resolve(ctx context.Context, userID int64, role string) (int, models.Role, error) {
+	if status, err := s.userExists(ctx, userID); err != nil {
+		return status, models.Role{}, err
+	}
+
+	found, err := s.roleRepo.GetByName(ctx, role)
+	if err != nil {
+		log.Println("error while fetching role", err.Error())
+		return http.StatusInternalServerError, models.Role{}, err
+	}
+	if found.ID == 0 {
+		return http.StatusBadRequest, models.Role{}, errors.New(constants.ErrRoleNotFound)
+	}
+	return http.StatusOK, found, nil
+}
+
+// userExists returns 404 when there is no user with the given id
+func (s roleService) userExists(ctx context.Context, userID int64) (int, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if user.ID == 0 {
+		return http.StatusNotFound, errors.New(constants.ErrUserNotFound)
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_roleService_AssignRole(t *testing.T) {
+	const adminID, userID = 1, 7
+	moderator := models.Role{ID: 2, Name: constants.RoleModerator}
+
+	tests := []struct {
+		name       string
+		userID     int64
+		role       string
+		prepare    func(userRepo *mocks.UserRepository, roleRepo *mocks.RoleRepository)
+		wantStatus int
+		wantErr    string
+		wantAudit  bool
+	}{
+		{
+			name:   "role is granted",
+			userID: userID,
+			role:   constants.RoleModerator,
+			prepare: func(userRepo *mocks.UserRepository, roleRepo *mocks.RoleRepository) {
+				userRepo.On("GetByID", mock.Anything, int64(userID)).Return(models.User{ID: userID}, nil)
+				roleRepo.On("GetByName", mock.Anything, constants.RoleModerator).Return(moderator, nil)
+				roleRepo.On("Assign", mock.Anything, int64(userID), moderator.ID, int64(adminID)).Return(true, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantAudit:  true,
+		},
+		{
+			name:   "granting a role twice is not an error",
+			userID: userID,
+			role:   constants.RoleModerator,
+			prepare: func(userRepo *mocks.UserRepository, roleRepo *mocks.RoleRepository) {
+				userRepo.On("GetByID", mock.Anything, int64(userID)).Return(models.User{ID: userID}, nil)
+				roleRepo.On("GetByName", mock.Anything, constants.RoleModerator).Return(moderator, nil)
+				roleRepo.On("Assign", mock.Anything, int64(userID), moderator.ID, int64(adminID)).Return(false, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "unknown user",
+			userID: 99,
+			role:   constants.RoleModerator,
+			prepare: func(userRepo *mocks.UserRepository, _ *mocks.RoleRepository) {
+				userRepo.On("GetByID", mock.Anything, int64(99)).Return(models.User{}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+			wantErr:    constants.ErrUserNotFound,
+		},
+		{
+			name:   "unknown role",
+			userID: userID,
+			role:   "superuser",
+			prepare: func(userRepo *mocks.UserRepository, roleRepo *mocks.RoleRepository) {
+				userRepo.On("GetByID", mock.Anything, int64(userID)).Return(models.User{ID: userID}, nil)
+				roleRepo.On("GetByName", mock.Anything, "superuser").Return(models.Role{}, nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    constants.ErrRoleNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := mocks.NewUserRepository(t)
+			roleRepo := mocks.NewRoleRepository(t)
+			tt.prepare(userRepo, roleRepo)
+			auditRepo := mocks.NewAuditRepository(t)
+			if tt.wantAudit {
+				expectAudit(auditRepo, adminID, constants.AuditRoleAssigned, userID)
+			}
+
+			status, err := NewRoleService(roleRepo, userRepo, NewAuditService(auditRepo)).AssignRole(context.Background(), adminID, tt.userID, tt.role)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_roleService_RemoveRole(t *testing.T) {
+	const adminID = 1
+	admin := models.Role{ID: 1, Name: constants.RoleAdmin}
+
+	t.Run("admins cannot remove their own admin role", func(t *testing.T) {
+		svc := NewRoleService(mocks.NewRoleRepository(t), mocks.NewUserRepository(t), nil)
+
+		status, err := svc.RemoveRole(context.Background(), adminID, adminID, constants.RoleAdmin)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, constants.ErrCannotRemoveOwnAdmin)
+	})
+
+	t.Run("admin role of another admin is removed", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, int64(2)).Return(models.User{ID: 2}, nil)
+		roleRepo := mocks.NewRoleRepository(t)
+		roleRepo.On("GetByName", mock.Anything, constants.RoleAdmin).Return(admin, nil)
+		roleRepo.On("Remove", mock.Anything, int64(2), admin.ID).Return(true, nil)
+		auditRepo := mocks.NewAuditRepository(t)
+		expectAudit(auditRepo, adminID, constants.AuditRoleRemoved, 2)
+
+		status, err := NewRoleService(roleRepo, userRepo, NewAuditService(auditRepo)).RemoveRole(context.Background(), adminID, 2, constants.RoleAdmin)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_roleService_HasPermission(t *testing.T) {
+	roleRepo := newTestRoleRepository(t, []string{constants.RoleAdmin}, []string{constants.PermissionBlogDeleteAny, constants.PermissionRolesManage})
+	svc := NewRoleService(roleRepo, nil, nil)
+
+	got, err := svc.HasPermission(context.Background(), 1, constants.PermissionRolesManage)
+	assert.NoError(t, err)
+	assert.True(t, got)
+
+	got, err = svc.HasPermission(context.Background(), 1, "users:delete")
+	assert.NoError(t, err)
+	assert.False(t, got)
+}
@@ -2,17 +2,58 @@ package services
 
 import (
 	"auth-service/config"
+	"auth-service/constants"
 	"auth-service/repositories"
 )
 
 // Services is an interface  for services
 type Services interface {
 	UserService() UserService
+	TokenService() TokenService
+	RevocationStore() RevocationStore
+	KeyManager() KeyManager
+	OAuthService() OAuthService
+	RoleService() RoleService
+	PasswordService() PasswordService
+	EmailVerificationService() EmailVerificationService
+	LoginThrottle() LoginThrottle
+	PasswordPolicy() PasswordPolicy
+	MFAService() MFAService
+	ProfileService() ProfileService
+	AuditService() AuditService
+	UserAdminService() UserAdminService
+	SessionService() SessionService
+	PersonalAccessTokenService() PersonalAccessTokenService
+	MagicLinkService() MagicLinkService
+	InviteService() InviteService
+	DataExportService() DataExportService
+	AccountPurger() AccountPurger
+	OutboxService() OutboxService
 }
 
 // svc is the concrete  implementation of the Services interface
 type svc struct {
-	uSvc UserService
+	uSvc        UserService
+	tokenSvc    TokenService
+	revocations RevocationStore
+	keys        KeyManager
+	oauthSvc    OAuthService
+	roleSvc     RoleService
+	passwordSvc PasswordService
+	verifySvc   EmailVerificationService
+	throttle    LoginThrottle
+	policy      PasswordPolicy
+	mfaSvc      MFAService
+	profileSvc  ProfileService
+	auditSvc    AuditService
+	adminSvc    UserAdminService
+	sessionSvc  SessionService
+	patSvc      PersonalAccessTokenService
+	magicSvc    MagicLinkService
+	inviteSvc   InviteService
+	exportSvc   DataExportService
+	purger      AccountPurger
+	outboxSvc   OutboxService
 }
 
 // UserService  is the method  to get user service
@@ -20,12 +61,153 @@ func (s *svc) UserService() UserService {
 	return s.uSvc
 }
 
+// TokenService is the method to get token service
+func (s *svc) TokenService() TokenService {
+	return s.tokenSvc
+}
+
+// RevocationStore is the method to get the access token revocation store
+func (s *svc) RevocationStore() RevocationStore {
+	return s.revocations
+}
+
+// KeyManager is the method to get the access token signing key manager
+func (s *svc) KeyManager() KeyManager {
+	return s.keys
+}
+
+// OAuthService is the method to get the OAuth service
+func (s *svc) OAuthService() OAuthService {
+	return s.oauthSvc
+}
+
+// RoleService is the method to get the role service
+func (s *svc) RoleService() RoleService {
+	return s.roleSvc
+}
+
+// PasswordService is the method to get the password reset service
+func (s *svc) PasswordService() PasswordService {
+	return s.passwordSvc
+}
+
+// EmailVerificationService is the method to get the email verification service
+func (s *svc) EmailVerificationService() EmailVerificationService {
+	return s.verifySvc
+}
+
+// LoginThrottle is the method to get the failed login throttle
+func (s *svc) LoginThrottle() LoginThrottle {
+	return s.throttle
+}
+
+// PasswordPolicy is the method to get the password policy
+func (s *svc) PasswordPolicy() PasswordPolicy {
+	return s.policy
+}
+
+// MFAService is the method to get the two-factor authentication service
+func (s *svc) MFAService() MFAService {
+	return s.mfaSvc
+}
+
+// ProfileService is the method to get the profile service
+func (s *svc) ProfileService() ProfileService {
+	return s.profileSvc
+}
+
+// AuditService is the method to get the audit log service
+func (s *svc) AuditService() AuditService {
+	return s.auditSvc
+}
+
+// UserAdminService is the method to get the user admin service
+func (s *svc) UserAdminService() UserAdminService {
+	return s.adminSvc
+}
+
+// SessionService is the method to get the session service
+func (s *svc) SessionService() SessionService {
+	return s.sessionSvc
+}
+
+// PersonalAccessTokenService is the method to get the personal access token service
+func (s *svc) PersonalAccessTokenService() PersonalAccessTokenService {
+	return s.patSvc
+}
+
+// MagicLinkService is the method to get the passwordless login service
+func (s *svc) MagicLinkService() MagicLinkService {
+	return s.magicSvc
+}
+
+// InviteService is the method to get the registration invite service
+func (s *svc) InviteService() InviteService {
+	return s.inviteSvc
+}
+
+// DataExportService is the method to get the data export service
+func (s *svc) DataExportService() DataExportService {
+	return s.exportSvc
+}
+
+// AccountPurger is the method to get the purger of accounts scheduled for deletion
+func (s *svc) AccountPurger() AccountPurger {
+	return s.purger
+}
+
+// OutboxService is the method to get the outbox event service
+func (s *svc) OutboxService() OutboxService {
+	return s.outboxSvc
+}
+
 // NewServices function   to create a new instance of Services
 func NewServices(repo repositories.Repository, conf config.Configuration) Services {
 	// repo and service init
 	userRepo := repo.UserRepository()
-	uSvc := NewUserService(userRepo, conf)
+	sessionRepo := repo.SessionRepository()
+	revocations := NewRevocationStore(repo.RevokedTokenRepository(), sessionRepo, conf)
+	keys := NewKeyManager(conf)
+	roleRepo := repo.RoleRepository()
+	auditSvc := NewAuditService(repo.AuditRepository())
+	tokenSvc := NewTokenService(userRepo, repo.RefreshTokenRepository(), roleRepo, sessionRepo, repo.PersonalAccessTokenRepository(), revocations, keys, auditSvc, conf)
+	mailer := NewMailer(conf)
+	verifySvc := NewEmailVerificationService(userRepo, repo.EmailVerificationRepository(), mailer, conf)
+	attempts := repo.LoginAttemptRepository()
+	if conf.AppConfig().LoginAttemptStore() == constants.LoginAttemptStoreMemory {
+		attempts = repositories.NewMemoryLoginAttemptRepository()
+	}
+	throttle := NewLoginThrottle(attempts, conf)
+	hasher := NewPasswordHasher(conf)
+	policy := NewPasswordPolicy(conf)
+	mfaSvc := NewMFAService(userRepo, repo.MFARepository(), conf)
+	inviteSvc := NewInviteService(repo.InviteRepository(), roleRepo, auditSvc, conf)
+	uSvc := NewUserService(userRepo, tokenSvc, verifySvc, throttle, hasher, policy, mfaSvc, inviteSvc, auditSvc, conf)
+	oauthSvc := NewOAuthService(repo.ClientRepository(), repo.AuthorizationCodeRepository(), userRepo, uSvc, tokenSvc, conf)
+	passwordSvc := NewPasswordService(userRepo, repo.PasswordResetRepository(), tokenSvc, hasher, policy, mailer, conf)
+	sessionSvc := NewSessionService(sessionRepo, tokenSvc, auditSvc, conf)
+	patSvc := NewPersonalAccessTokenService(repo.PersonalAccessTokenRepository(), auditSvc)
 	return &svc{
-		uSvc: uSvc,
+		uSvc:        uSvc,
+		tokenSvc:    tokenSvc,
+		revocations: revocations,
+		keys:        keys,
+		oauthSvc:    oauthSvc,
+		roleSvc:     NewRoleService(roleRepo, userRepo, auditSvc),
+		passwordSvc: passwordSvc,
+		verifySvc:   verifySvc,
+		throttle:    throttle,
+		policy:      policy,
+		mfaSvc:      mfaSvc,
+		profileSvc:  NewProfileService(userRepo, repo.EmailChangeRepository(), tokenSvc, throttle, hasher, policy, mailer, auditSvc, conf),
+		auditSvc:    auditSvc,
+		sessionSvc:  sessionSvc,
+		patSvc:      patSvc,
+		magicSvc:    NewMagicLinkService(userRepo, repo.MagicLinkRepository(), tokenSvc, mfaSvc, mailer, auditSvc, conf),
+		adminSvc:    NewUserAdminService(userRepo, roleRepo, mfaSvc, tokenSvc, passwordSvc, throttle, auditSvc),
+		inviteSvc:   inviteSvc,
+		exportSvc:   NewDataExportService(userRepo, roleRepo, sessionSvc, patSvc, auditSvc, NewBlogClient(conf)),
+		purger:      NewAccountPurger(userRepo, auditSvc),
+		outboxSvc:   NewOutboxService(repo.OutboxRepository()),
 	}
 }
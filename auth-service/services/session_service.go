@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+)
+
+// SessionService lets users see the devices they are logged in on and log out single devices
+type SessionService interface {
+	ListSessions(ctx context.Context, userID int64, currentSessionID string) (int, []models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID int64) (int, error)
+}
+
+// sessionService is an implementation of SessionService
+type sessionService struct {
+	sessionRepo repositories.SessionRepository
+	tokenSvc    TokenService
+	audit       AuditService
+	conf        config.Configuration
+}
+
+// NewSessionService returns a new instance of the session service
+func NewSessionService(
+	sessionRepo repositories.SessionRepository,
+	tokenSvc TokenService,
+	audit AuditService,
+	conf config.Configuration,
+) SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		tokenSvc:    tokenSvc,
+		audit:       audit,
+		conf:        conf,
+	}
+}
+
+// ListSessions returns the live sessions of a user, most recently used first. Sessions unused
+// for longer than the refresh token lifetime have no valid tokens left and are not listed. The
+// session of currentSessionID is marked current.
+func (s sessionService) ListSessions(ctx context.Context, userID int64, currentSessionID string) (int, []models.Session, error) {
+	seenSince := time.Now().UTC().Add(-s.conf.AppConfig().RefreshTokenTTL())
+	sessions, err := s.sessionRepo.ListActiveForUser(ctx, userID, seenSince)
+	if err != nil {
+		log.Println("error while listing sessions", err.Error())
+		return http.StatusInternalServerError, nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = currentSessionID != "" && sessions[i].FamilyID == currentSessionID
+	}
+	return http.StatusOK, sessions, nil
+}
+
+// RevokeSession logs a user out of one of their sessions. Sessions of other users and ended
+// sessions are not found.
+func (s sessionService) RevokeSession(ctx context.Context, userID, sessionID int64) (int, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		log.Println("error while fetching session", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if session.ID == 0 || session.UserID != userID || session.RevokedAt != nil {
+		return http.StatusNotFound, errors.New(constants.ErrSessionNotFound)
+	}
+
+	if status, err := s.tokenSvc.RevokeSession(ctx, session.FamilyID); err != nil {
+		return status, err
+	}
+
+	s.audit.Log(ctx, models.AuthEvent{
+		Type:     constants.EventSessionRevoked,
+		UserID:   userID,
+		ClientID: session.ClientID,
+		Details:  map[string]string{constants.EventDetailSession: session.FamilyID},
+	})
+	log.Printf("user %d revoked session %d", userID, session.ID)
+	return http.StatusOK, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sessionService_ListSessions(t *testing.T) {
+	sessionRepo := mocks.NewSessionRepository(t)
+	sessionRepo.On("ListActiveForUser", mock.Anything, int64(7), mock.MatchedBy(func(since time.Time) bool {
+		return since.Before(time.Now().UTC().Add(-constants.DefaultRefreshTokenTTL + time.Minute))
+	})).Return([]models.Session{
+		{ID: 1, UserID: 7, FamilyID: "phone"},
+		{ID: 2, UserID: 7, FamilyID: "laptop"},
+	}, nil)
+	svc := NewSessionService(sessionRepo, nil, &recordingAuditService{}, newTestConfiguration())
+
+	status, sessions, err := svc.ListSessions(context.Background(), 7, "laptop")
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	require.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func Test_sessionService_RevokeSession(t *testing.T) {
+	revokedAt := time.Now().UTC()
+	tests := []struct {
+		name       string
+		session    models.Session
+		wantStatus int
+	}{
+		{name: "unknown session", session: models.Session{}, wantStatus: http.StatusNotFound},
+		{name: "session of another user", session: models.Session{ID: 3, UserID: 8, FamilyID: "family"}, wantStatus: http.StatusNotFound},
+		{name: "revoked session", session: models.Session{ID: 3, UserID: 7, FamilyID: "family", RevokedAt: &revokedAt}, wantStatus: http.StatusNotFound},
+		{name: "own session is revoked", session: models.Session{ID: 3, UserID: 7, FamilyID: "family"}, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionRepo := mocks.NewSessionRepository(t)
+			sessionRepo.On("GetByID", mock.Anything, int64(3)).Return(tt.session, nil)
+			refreshRepo := mocks.NewRefreshTokenRepository(t)
+			if tt.wantStatus == http.StatusOK {
+				refreshRepo.On("RevokeFamily", mock.Anything, "family").Return(nil)
+				sessionRepo.On("RevokeByFamily", mock.Anything, "family").Return(nil)
+			}
+			conf := newTestConfiguration()
+			revocations := NewRevocationStore(nil, sessionRepo, conf)
+			tokenSvc := NewTokenService(nil, refreshRepo, nil, sessionRepo, nil, revocations, nil, &recordingAuditService{}, conf)
+			svc := NewSessionService(sessionRepo, tokenSvc, &recordingAuditService{}, conf)
+
+			status, err := svc.RevokeSession(context.Background(), 7, 3)
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantStatus != http.StatusOK {
+				assert.EqualError(t, err, constants.ErrSessionNotFound)
+				return
+			}
+			require.NoError(t, err)
+			// access tokens of the session stop verifying right away
+			assert.True(t, revocations.IsRevoked("any-jti", "family", 7, time.Now()))
+		})
+	}
+}
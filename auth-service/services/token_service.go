@@ -0,0 +1,636 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+	"auth-service/utils"
+)
+
+// TokenService is an interface for issuing and rotating tokens
+type TokenService interface {
+	IssueTokens(ctx context.Context, user models.User, grant models.TokenGrant) (models.LoginResponse, error)
+	IssueClientToken(ctx context.Context, client models.Client, scope string) (models.LoginResponse, error)
+	IssueImpersonationToken(ctx context.Context, actorID int64, user models.User) (models.LoginResponse, error)
+	Refresh(ctx context.Context, refreshToken, clientID string) (int, models.LoginResponse, error)
+	ValidateAccessToken(ctx context.Context, token string) (*utils.TokenClaims, error)
+	Introspect(ctx context.Context, token, tokenTypeHint string) (models.IntrospectionResponse, error)
+	Logout(ctx context.Context, claims *utils.TokenClaims, refreshToken string) (int, error)
+	LogoutEverywhere(ctx context.Context, userID int64) (int, error)
+	RevokeSession(ctx context.Context, sessionID string) (int, error)
+	Discovery() models.OpenIDConfiguration
+}
+
+// tokenService is an implementation of TokenService
+type tokenService struct {
+	userRepo    repositories.UserRepository
+	refreshRepo repositories.RefreshTokenRepository
+	roleRepo    repositories.RoleRepository
+	sessionRepo repositories.SessionRepository
+	patRepo     repositories.PersonalAccessTokenRepository
+	revocations RevocationStore
+	keys        KeyManager
+	audit       AuditService
+	conf        config.Configuration
+}
+
+// NewTokenService returns a new instance of the token service
+func NewTokenService(
+	userRepo repositories.UserRepository,
+	refreshRepo repositories.RefreshTokenRepository,
+	roleRepo repositories.RoleRepository,
+	sessionRepo repositories.SessionRepository,
+	patRepo repositories.PersonalAccessTokenRepository,
+	revocations RevocationStore,
+	keys KeyManager,
+	audit AuditService,
+	conf config.Configuration,
+) TokenService {
+	return &tokenService{
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		roleRepo:    roleRepo,
+		sessionRepo: sessionRepo,
+		patRepo:     patRepo,
+		revocations: revocations,
+		keys:        keys,
+		audit:       audit,
+		conf:        conf,
+	}
+}
+
+// IssueTokens starts a new session with its own refresh-token family for the user and returns an
+// access/refresh token pair. Grants that include the openid scope also get an ID token for the client.
+func (t tokenService) IssueTokens(ctx context.Context, user models.User, grant models.TokenGrant) (models.LoginResponse, error) {
+	familyID, err := utils.GenerateRandomID()
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	session := models.Session{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		ClientID:  grant.ClientID,
+		UserAgent: utils.UserAgentFromContext(ctx),
+		IP:        utils.ClientIPFromContext(ctx),
+	}
+	if err := t.sessionRepo.Create(ctx, &session); err != nil {
+		log.Println("error while storing session", err.Error())
+		return models.LoginResponse{}, err
+	}
+	return t.issueTokens(ctx, user, familyID, grant)
+}
+
+// IssueClientToken signs an access token for a client acting on its own behalf (client_credentials grant).
+// The token carries the client id instead of a user and comes without a refresh token.
+func (t tokenService) IssueClientToken(_ context.Context, client models.Client, scope string) (models.LoginResponse, error) {
+	appConf := t.conf.AppConfig()
+	now := time.Now().UTC()
+
+	jti, err := utils.GenerateRandomID()
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	claims := utils.NewTokenClaims("", now.Unix())
+	claims.IssuedAtMicros = now.UnixMicro()
+	claims.Id = jti
+	claims.Subject = client.ClientID
+	claims.Issuer = appConf.Issuer()
+	claims.Audience = appConf.Audience()
+	claims.ClientID = client.ClientID
+	claims.Scope = scope
+	expiresAt := now.Add(appConf.AccessTokenTTL()).Unix()
+
+	signingKey, err := t.keys.SigningKey()
+	if err != nil {
+		log.Println("error while fetching signing key", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	accessToken, err := utils.GenerateTokenWithSigningKey(claims, signingKey, expiresAt)
+	if err != nil {
+		log.Println("error while generating token", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+		Scope:       scope,
+	}, nil
+}
+
+// IssueImpersonationToken signs an access token for the admin actorID acting as user. It carries the claims
+// of the user and names the admin in the act claim, so that services can tell it apart and refuse
+// what support staff must not do on a user's behalf. It lasts ImpersonationTokenTTL and comes
+// without a refresh token or session; logging the user out everywhere revokes it.
+func (t tokenService) IssueImpersonationToken(ctx context.Context, actorID int64, user models.User) (models.LoginResponse, error) {
+	appConf := t.conf.AppConfig()
+	now := time.Now().UTC()
+
+	jti, err := utils.GenerateRandomID()
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	roles, err := t.roleRepo.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		log.Println("error while fetching user roles", err.Error())
+		return models.LoginResponse{}, err
+	}
+	permissions, err := t.roleRepo.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		log.Println("error while fetching user permissions", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	claims := utils.NewTokenClaims(user.Email, now.Unix())
+	claims.IssuedAtMicros = now.UnixMicro()
+	claims.Id = jti
+	claims.Subject = strconv.FormatInt(user.ID, 10)
+	claims.Issuer = appConf.Issuer()
+	claims.Audience = appConf.Audience()
+	claims.UserID = user.ID
+	claims.Scope = t.accessScope(user, "")
+	claims.Roles = roles
+	claims.Permissions = permissions
+	claims.Actor = &models.Actor{Subject: strconv.FormatInt(actorID, 10), UserID: actorID}
+	expiresAt := now.Add(appConf.ImpersonationTokenTTL()).Unix()
+
+	signingKey, err := t.keys.SigningKey()
+	if err != nil {
+		log.Println("error while fetching signing key", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	accessToken, err := utils.GenerateTokenWithSigningKey(claims, signingKey, expiresAt)
+	if err != nil {
+		log.Println("error while generating token", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+		Email:       user.Email,
+		Scope:       claims.Scope,
+	}, nil
+}
+
+// Refresh rotates a refresh token: the presented token is consumed and a new pair is issued in the
+// same family. Presenting a token that was already consumed revokes the whole family, since it
+// means either the client or an attacker holds a stolen copy. Tokens issued to a client can only
+// be refreshed by that client; first-party tokens are refreshed with an empty clientID.
+func (t tokenService) Refresh(ctx context.Context, refreshToken, clientID string) (int, models.LoginResponse, error) {
+	errInvalid := errors.New(constants.ErrInvalidRefreshToken)
+	if refreshToken == "" {
+		return http.StatusBadRequest, models.LoginResponse{}, errInvalid
+	}
+
+	stored, err := t.refreshRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		log.Println("error while fetching refresh token", err.Error())
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if stored.ID == 0 || stored.RevokedAt != nil || stored.ClientID != clientID {
+		return http.StatusUnauthorized, models.LoginResponse{}, errInvalid
+	}
+
+	if stored.UsedAt != nil {
+		return t.revokeReusedFamily(ctx, stored)
+	}
+
+	if time.Now().UTC().After(stored.ExpiresAt) {
+		return http.StatusUnauthorized, models.LoginResponse{}, errInvalid
+	}
+
+	// mark the token as used before issuing a new one; losing this race means a concurrent
+	// request already rotated the same token, which is treated as a replay
+	marked, err := t.refreshRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		log.Println("error while consuming refresh token", err.Error())
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if !marked {
+		return t.revokeReusedFamily(ctx, stored)
+	}
+
+	user, err := t.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		log.Println("error while fetching refresh token owner", err.Error())
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if user.ID == 0 || user.Disabled() {
+		return http.StatusUnauthorized, models.LoginResponse{}, errInvalid
+	}
+
+	resp, err := t.issueTokens(ctx, user, stored.FamilyID, models.TokenGrant{ClientID: stored.ClientID, Scope: stored.Scope})
+	if err != nil {
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+	if err := t.sessionRepo.Touch(ctx, stored.FamilyID, utils.ClientIPFromContext(ctx), utils.UserAgentFromContext(ctx)); err != nil {
+		log.Println("error while updating session", err.Error())
+	}
+	t.audit.Log(ctx, models.AuthEvent{
+		Type:     constants.EventTokenRefreshed,
+		UserID:   user.ID,
+		Email:    user.Email,
+		ClientID: stored.ClientID,
+		Details:  map[string]string{constants.EventDetailSession: stored.FamilyID},
+	})
+	return http.StatusOK, resp, nil
+}
+
+// ValidateAccessToken verifies an access token and rejects it if it was revoked or is not typed
+// as an access token, as ID tokens signed with the same keys are not.
+// HS256 tokens signed with SECRET_KEY are still accepted until LEGACY_TOKENS_UNTIL, so tokens
+// issued before the switch to asymmetric keys keep working during the migration. They carry no
+// user id or jti and escape revocation, so the deadline should be close. Personal access tokens
+// are recognized by their prefix and looked up in the database instead.
+func (t tokenService) ValidateAccessToken(ctx context.Context, token string) (*utils.TokenClaims, error) {
+	if strings.HasPrefix(token, constants.PersonalAccessTokenPrefix) {
+		return t.validatePersonalAccessToken(ctx, token)
+	}
+
+	appConf := t.conf.AppConfig()
+	secret := appConf.SecretKey()
+	if !time.Now().Before(appConf.LegacyTokensUntil()) {
+		secret = ""
+	}
+	return utils.ValidateTokenClaims(
+		token,
+		secret,
+		utils.WithKeyResolver(t.keys),
+		utils.WithIssuer(appConf.Issuer()),
+		utils.WithTokenType(constants.AccessTokenJWTType),
+		utils.WithRevocationChecker(t.revocations),
+	)
+}
+
+// Introspect reports whether a token is active and, if so, what it carries (RFC 7662).
+// Access tokens are tried first unless the hint says otherwise; refresh tokens are looked up in the database.
+// Tokens of users who were disabled or deleted since they were issued are inactive.
+func (t tokenService) Introspect(ctx context.Context, token, tokenTypeHint string) (models.IntrospectionResponse, error) {
+	if tokenTypeHint != constants.TokenTypeHintRefreshToken {
+		if claims, err := t.ValidateAccessToken(ctx, token); err == nil {
+			if claims.UserID == 0 {
+				return introspectionFromClaims(claims), nil
+			}
+			if active, err := t.ownerActive(ctx, claims.UserID); err != nil || !active {
+				return models.IntrospectionResponse{Active: false}, err
+			}
+			resp := introspectionFromClaims(claims)
+			if strings.HasPrefix(token, constants.PersonalAccessTokenPrefix) {
+				resp.TokenType = constants.TokenTypePersonalAccessToken
+			}
+			return resp, nil
+		}
+		// personal access tokens are never refresh tokens
+		if strings.HasPrefix(token, constants.PersonalAccessTokenPrefix) {
+			return models.IntrospectionResponse{Active: false}, nil
+		}
+	}
+
+	stored, err := t.refreshRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		log.Println("error while fetching refresh token", err.Error())
+		return models.IntrospectionResponse{}, err
+	}
+	if stored.ID == 0 || stored.UsedAt != nil || stored.RevokedAt != nil || time.Now().UTC().After(stored.ExpiresAt) {
+		return models.IntrospectionResponse{Active: false}, nil
+	}
+	if active, err := t.ownerActive(ctx, stored.UserID); err != nil || !active {
+		return models.IntrospectionResponse{Active: false}, err
+	}
+
+	return models.IntrospectionResponse{
+		Active:    true,
+		Subject:   strconv.FormatInt(stored.UserID, 10),
+		Issuer:    t.conf.AppConfig().Issuer(),
+		Scope:     stored.Scope,
+		ClientID:  stored.ClientID,
+		ExpiresAt: stored.ExpiresAt.Unix(),
+		IssuedAt:  stored.CreatedAt.Unix(),
+		TokenType: constants.TokenTypeHintRefreshToken,
+	}, nil
+}
+
+// ownerActive reports whether the user a token was issued to still exists and is not disabled
+func (t tokenService) ownerActive(ctx context.Context, userID int64) (bool, error) {
+	user, err := t.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching token owner", err.Error())
+		return false, err
+	}
+	return user.ID != 0 && !user.Disabled(), nil
+}
+
+// Logout revokes the presented access token and its session and, when given, the session of the
+// refresh token it came with
+func (t tokenService) Logout(ctx context.Context, claims *utils.TokenClaims, refreshToken string) (int, error) {
+	if claims.Id != "" {
+		expiresAt := time.Unix(claims.ExpiresAt, 0).UTC()
+		if err := t.revocations.RevokeToken(ctx, claims.Id, claims.UserID, expiresAt); err != nil {
+			log.Println("error while revoking access token", err.Error())
+			return http.StatusInternalServerError, err
+		}
+	}
+	if claims.SessionID != "" {
+		if status, err := t.revokeUserSession(ctx, claims.UserID, claims.SessionID); err != nil {
+			return status, err
+		}
+	}
+
+	if refreshToken == "" {
+		return http.StatusOK, nil
+	}
+
+	stored, err := t.refreshRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		log.Println("error while fetching refresh token", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	// never let a caller revoke somebody else's session with a leaked refresh token
+	if stored.ID == 0 || stored.UserID != claims.UserID {
+		return http.StatusBadRequest, errors.New(constants.ErrInvalidRefreshToken)
+	}
+	return t.revokeUserSession(ctx, claims.UserID, stored.FamilyID)
+}
+
+// LogoutEverywhere revokes every refresh token and every access token issued to the user so far
+func (t tokenService) LogoutEverywhere(ctx context.Context, userID int64) (int, error) {
+	if err := t.refreshRepo.RevokeAllForUser(ctx, userID); err != nil {
+		log.Println("error while revoking refresh tokens", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if err := t.revocations.RevokeAllForUser(ctx, userID); err != nil {
+		log.Println("error while revoking access tokens", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	t.audit.Log(ctx, models.AuthEvent{Type: constants.EventAllTokensRevoked, UserID: userID})
+	return http.StatusOK, nil
+}
+
+// RevokeSession ends a session: its refresh tokens are revoked and its access tokens stop
+// verifying. The session id is the id of its refresh token family.
+func (t tokenService) RevokeSession(ctx context.Context, sessionID string) (int, error) {
+	if err := t.refreshRepo.RevokeFamily(ctx, sessionID); err != nil {
+		log.Println("error while revoking refresh token family", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if err := t.revocations.RevokeSession(ctx, sessionID); err != nil {
+		log.Println("error while revoking session", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// Discovery returns the OpenID Connect discovery document; endpoints are relative to the issuer
+func (t tokenService) Discovery() models.OpenIDConfiguration {
+	issuer := t.conf.AppConfig().Issuer()
+	return models.OpenIDConfiguration{
+		Issuer:                           issuer,
+		UserInfoEndpoint:                 issuer + constants.PathUserInfo,
+		JWKSURI:                          issuer + constants.PathJWKS,
+		IntrospectionEndpoint:            issuer + constants.PathIntrospect,
+		AuthorizationEndpoint:            issuer + constants.PathAuthorize,
+		TokenEndpoint:                    issuer + constants.PathToken,
+		ScopesSupported:                  constants.SupportedScopes,
+		ResponseTypesSupported:           []string{constants.ResponseTypeCode},
+		GrantTypesSupported:              []string{constants.GrantTypeAuthorizationCode, constants.GrantTypeRefreshToken, constants.GrantTypeClientCredentials},
+		CodeChallengeMethodsSupported:    []string{constants.CodeChallengeMethodS256},
+		TokenEndpointAuthMethods:         []string{constants.ClientAuthNone, constants.ClientAuthBasic, constants.ClientAuthPost},
+		SubjectTypesSupported:            []string{constants.SubjectTypePublic},
+		IDTokenSigningAlgValuesSupported: []string{constants.SigningAlgRS256, constants.SigningAlgEdDSA},
+		ClaimsSupported:                  []string{"iss", "sub", "aud", "exp", "iat", "jti", "nonce", "email", "name", "given_name", "family_name", "preferred_username", "picture"},
+	}
+}
+
+// introspectionFromClaims maps verified access token claims to an introspection response
+func introspectionFromClaims(claims *utils.TokenClaims) models.IntrospectionResponse {
+	return models.IntrospectionResponse{
+		Active:      true,
+		Subject:     claims.Subject,
+		Issuer:      claims.Issuer,
+		Audience:    claims.Audience,
+		Email:       claims.Email,
+		Scope:       claims.Scope,
+		ClientID:    claims.ClientID,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		ExpiresAt:   claims.ExpiresAt,
+		IssuedAt:    claims.IssuedAt,
+		TokenType:   constants.TokenTypeBearer,
+		JTI:         claims.Id,
+		Actor:       claims.Actor,
+	}
+}
+
+// validatePersonalAccessToken looks up a personal access token and returns claims equivalent to those
+// of an access token of its owner, limited to the token's scope. Revoked and expired tokens and the
+// tokens of disabled users are rejected. The last use is recorded at most once per
+// PersonalAccessTokenTouchInterval.
+func (t tokenService) validatePersonalAccessToken(ctx context.Context, token string) (*utils.TokenClaims, error) {
+	stored, err := t.patRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		log.Println("error while fetching personal access token", err.Error())
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if stored.ID == 0 || stored.RevokedAt != nil || stored.Expired(now) {
+		return nil, errors.New(constants.ErrInvalidPersonalAccessToken)
+	}
+
+	user, err := t.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		log.Println("error while fetching token owner", err.Error())
+		return nil, err
+	}
+	if user.ID == 0 || user.Disabled() {
+		return nil, errors.New(constants.ErrInvalidPersonalAccessToken)
+	}
+
+	roles, err := t.roleRepo.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		log.Println("error while fetching user roles", err.Error())
+		return nil, err
+	}
+	permissions, err := t.roleRepo.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		log.Println("error while fetching user permissions", err.Error())
+		return nil, err
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) > constants.PersonalAccessTokenTouchInterval {
+		// a failed write only loses the timestamp, so the request goes on
+		if err := t.patRepo.TouchLastUsed(ctx, stored.ID); err != nil {
+			log.Println("error while recording personal access token use", err.Error())
+		}
+	}
+
+	appConf := t.conf.AppConfig()
+	claims := utils.NewTokenClaims(user.Email, stored.CreatedAt.Unix())
+	claims.Subject = strconv.FormatInt(user.ID, 10)
+	claims.Issuer = appConf.Issuer()
+	claims.Audience = appConf.Audience()
+	claims.UserID = user.ID
+	claims.Scope = t.accessScope(user, stored.Scope)
+	claims.Roles = roles
+	claims.Permissions = permissions
+	if stored.ExpiresAt != nil {
+		claims.ExpiresAt = stored.ExpiresAt.Unix()
+	}
+	return &claims, nil
+}
+
+// revokeUserSession revokes a session of the user and records it
+func (t tokenService) revokeUserSession(ctx context.Context, userID int64, sessionID string) (int, error) {
+	if status, err := t.RevokeSession(ctx, sessionID); err != nil {
+		return status, err
+	}
+	t.audit.Log(ctx, models.AuthEvent{
+		Type:    constants.EventSessionRevoked,
+		UserID:  userID,
+		Details: map[string]string{constants.EventDetailSession: sessionID},
+	})
+	return http.StatusOK, nil
+}
+
+// revokeReusedFamily revokes the session of a family after a consumed token was replayed
+func (t tokenService) revokeReusedFamily(ctx context.Context, stored models.RefreshToken) (int, models.LoginResponse, error) {
+	log.Printf("refresh token reuse detected for user %d, revoking family %s", stored.UserID, stored.FamilyID)
+	if status, err := t.RevokeSession(ctx, stored.FamilyID); err != nil {
+		return status, models.LoginResponse{}, err
+	}
+	t.audit.Log(ctx, models.AuthEvent{
+		Type:     constants.EventRefreshTokenReused,
+		UserID:   stored.UserID,
+		ClientID: stored.ClientID,
+		Details:  map[string]string{constants.EventDetailSession: stored.FamilyID},
+	})
+	return http.StatusUnauthorized, models.LoginResponse{}, errors.New(constants.ErrRefreshTokenReused)
+}
+
+// issueTokens signs a short-lived access token and stores a new refresh token in the given family.
+// The user's roles are read on every issue, so role changes apply from the next refresh on. The same
+// holds for email verification: the refresh token keeps the granted scope and only the access token
+// is restricted while the user is unverified.
+func (t tokenService) issueTokens(ctx context.Context, user models.User, familyID string, grant models.TokenGrant) (models.LoginResponse, error) {
+	appConf := t.conf.AppConfig()
+	now := time.Now().UTC()
+
+	jti, err := utils.GenerateRandomID()
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	roles, err := t.roleRepo.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		log.Println("error while fetching user roles", err.Error())
+		return models.LoginResponse{}, err
+	}
+	permissions, err := t.roleRepo.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		log.Println("error while fetching user permissions", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	claims := utils.NewTokenClaims(user.Email, now.Unix())
+	claims.IssuedAtMicros = now.UnixMicro()
+	claims.Id = jti
+	claims.Subject = strconv.FormatInt(user.ID, 10)
+	claims.Issuer = appConf.Issuer()
+	claims.Audience = appConf.Audience()
+	claims.UserID = user.ID
+	claims.ClientID = grant.ClientID
+	claims.Scope = t.accessScope(user, grant.Scope)
+	claims.Roles = roles
+	claims.Permissions = permissions
+	claims.SessionID = familyID
+	expiresAt := now.Add(appConf.AccessTokenTTL()).Unix()
+
+	signingKey, err := t.keys.SigningKey()
+	if err != nil {
+		log.Println("error while fetching signing key", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	accessToken, err := utils.GenerateTokenWithSigningKey(claims, signingKey, expiresAt)
+	if err != nil {
+		log.Println("error while generating token", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	stored := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  familyID,
+		ClientID:  grant.ClientID,
+		Scope:     grant.Scope,
+		ExpiresAt: now.Add(appConf.RefreshTokenTTL()),
+	}
+	if err := t.refreshRepo.Create(ctx, &stored); err != nil {
+		log.Println("error while storing refresh token", err.Error())
+		return models.LoginResponse{}, err
+	}
+
+	var idToken string
+	if grant.ClientID != "" && utils.HasScope(grant.Scope, constants.ScopeOpenID) {
+		idToken, err = t.issueIDToken(user, grant, signingKey, now, expiresAt)
+		if err != nil {
+			log.Println("error while generating id token", err.Error())
+			return models.LoginResponse{}, err
+		}
+	}
+
+	return models.LoginResponse{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: stored.ExpiresAt.Unix(),
+		Email:            user.Email,
+		Scope:            claims.Scope,
+		IDToken:          idToken,
+	}, nil
+}
+
+// accessScope returns the scope of an access token for the granted scope. Under the restricted
+// email verification policy, unverified users lose blog:write; first-party tokens, which are
+// otherwise unscoped, are limited to blog:read so that blog-service checks their scope at all.
+func (t tokenService) accessScope(user models.User, scope string) string {
+	if user.EmailVerified() || t.conf.AppConfig().EmailVerificationPolicy() != constants.EmailVerificationRestricted {
+		return scope
+	}
+	if restricted := utils.RemoveScope(scope, constants.ScopeBlogWrite); restricted != "" {
+		return restricted
+	}
+	return constants.ScopeBlogRead
+}
+
+// issueIDToken signs an OpenID Connect ID token for the client the grant was made to
+func (t tokenService) issueIDToken(user models.User, grant models.TokenGrant, key utils.SigningKey, now time.Time, expiresAt int64) (string, error) {
+	claims := utils.IDTokenClaims{Nonce: grant.Nonce}
+	claims.Issuer = t.conf.AppConfig().Issuer()
+	claims.Subject = strconv.FormatInt(user.ID, 10)
+	claims.Audience = grant.ClientID
+	claims.IssuedAt = now.Unix()
+	if utils.HasScope(grant.Scope, constants.ScopeEmail) {
+		claims.Email = user.Email
+	}
+	return utils.GenerateIDToken(claims, key, expiresAt)
+}
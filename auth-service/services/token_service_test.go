@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/config"
+	configmocks "auth-service/config/mocks"
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAppConfig returns an AppConfig backed by an in-memory viper instance
+func newTestAppConfig() config.AppConfig {
+	env := viper.New()
+	env.Set(constants.SecretKey, "test-secret")
+	return config.NewAppConfig(env)
+}
+
+// newTestConfiguration wraps newTestAppConfig for services that need a Configuration
+func newTestConfiguration() config.Configuration {
+	return config.NewConfiguration(newTestAppConfig())
+}
+
+// newTestKeyManager returns a loaded KeyManager keeping its keys in a temporary directory
+func newTestKeyManager(t *testing.T, algorithm string) KeyManager {
+	env := viper.New()
+	env.Set(constants.JWTKeysDir, t.TempDir())
+	env.Set(constants.JWTSigningAlg, algorithm)
+
+	keys := NewKeyManager(config.NewConfiguration(config.NewAppConfig(env)))
+	if err := keys.Load(); err != nil {
+		t.Fatalf("loading signing keys: %v", err)
+	}
+	return keys
+}
+
+// newTestRoleRepository returns a RoleRepository granting roles and permissions to every user
+func newTestRoleRepository(t *testing.T, roles, permissions []string) *mocks.RoleRepository {
+	roleRepo := mocks.NewRoleRepository(t)
+	roleRepo.On("GetUserRoles", mock.Anything, mock.Anything).Return(roles, nil).Maybe()
+	roleRepo.On("GetUserPermissions", mock.Anything, mock.Anything).Return(permissions, nil).Maybe()
+	return roleRepo
+}
+
+// newTestSessionRepository returns a SessionRepository that accepts new and refreshed sessions
+func newTestSessionRepository(t *testing.T) *mocks.SessionRepository {
+	sessionRepo := mocks.NewSessionRepository(t)
+	sessionRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	sessionRepo.On("Touch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return sessionRepo
+}
+
+func Test_tokenService_IssueTokensRoles(t *testing.T) {
+	user := models.User{ID: 7, Email: "asif@example.com"}
+	refreshRepo := mocks.NewRefreshTokenRepository(t)
+	refreshRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	roleRepo := newTestRoleRepository(t, []string{constants.RoleModerator}, []string{constants.PermissionBlogDeleteAny})
+
+	svc := NewTokenService(nil, refreshRepo, roleRepo, newTestSessionRepository(t), nil, nil, newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{}, newTestConfiguration())
+	resp, err := svc.IssueTokens(context.Background(), user, models.TokenGrant{})
+	require.NoError(t, err)
+
+	claims, err := svc.ValidateAccessToken(context.Background(), resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{constants.RoleModerator}, claims.Roles)
+	assert.Equal(t, []string{constants.PermissionBlogDeleteAny}, claims.Permissions)
+	assert.NotEmpty(t, claims.SessionID)
+}
+
+func Test_tokenService_IssueTokensEmailVerification(t *testing.T) {
+	verifiedAt := time.Now().UTC()
+	tests := []struct {
+		name      string
+		policy    string
+		verified  bool
+		grant     models.TokenGrant
+		wantScope string
+	}{
+		{name: "verified user keeps an unscoped token", policy: constants.EmailVerificationRestricted, verified: true, wantScope: ""},
+		{name: "unverified user is limited to blog:read", policy: constants.EmailVerificationRestricted, wantScope: constants.ScopeBlogRead},
+		{
+			name:      "unverified user loses blog:write of a client grant",
+			policy:    constants.EmailVerificationRestricted,
+			grant:     models.TokenGrant{ClientID: testClientID, Scope: "openid blog:read blog:write"},
+			wantScope: "openid blog:read",
+		},
+		{name: "policy off leaves unverified users unscoped", policy: constants.EmailVerificationOff, wantScope: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := models.User{ID: 7, Email: "asif@example.com"}
+			if tt.verified {
+				user.EmailVerifiedAt = &verifiedAt
+			}
+			var stored *models.RefreshToken
+			refreshRepo := mocks.NewRefreshTokenRepository(t)
+			refreshRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*models.RefreshToken)
+			}).Return(nil)
+			env := viper.New()
+			env.Set(constants.EmailVerificationPolicy, tt.policy)
+
+			svc := NewTokenService(nil, refreshRepo, newTestRoleRepository(t, nil, nil), newTestSessionRepository(t), nil, nil,
+				newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{}, config.NewConfiguration(config.NewAppConfig(env)))
+			resp, err := svc.IssueTokens(context.Background(), user, tt.grant)
+			require.NoError(t, err)
+
+			claims, err := svc.ValidateAccessToken(context.Background(), resp.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantScope, claims.Scope)
+			assert.Equal(t, tt.wantScope, resp.Scope)
+			// the refresh token keeps the grant, so refreshing after verification lifts the restriction
+			assert.Equal(t, tt.grant.Scope, stored.Scope)
+		})
+	}
+}
+
+func Test_tokenService_Refresh(t *testing.T) {
+	type fields struct {
+		userRepo    *mocks.UserRepository
+		refreshRepo *mocks.RefreshTokenRepository
+		sessionRepo *mocks.SessionRepository
+		conf        *configmocks.Configuration
+	}
+	const presented = "presented-refresh-token"
+	usedAt := time.Now().UTC().Add(-time.Minute)
+	user := models.User{ID: 7, Email: "asif@example.com"}
+
+	tests := []struct {
+		name       string
+		token      string
+		prepare    func(*fields)
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "empty token is rejected",
+			token:      "",
+			prepare:    func(f *fields) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    constants.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "unknown token is rejected",
+			token: presented,
+			prepare: func(f *fields) {
+				f.refreshRepo.On("GetByHash", mock.Anything, utils.HashToken(presented)).
+					Return(models.RefreshToken{}, nil)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "expired token is rejected",
+			token: presented,
+			prepare: func(f *fields) {
+				f.refreshRepo.On("GetByHash", mock.Anything, mock.Anything).Return(models.RefreshToken{
+					ID:        1,
+					UserID:    user.ID,
+					FamilyID:  "family",
+					ExpiresAt: time.Now().UTC().Add(-time.Hour),
+				}, nil)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "token issued to a client cannot be refreshed as a first-party token",
+			token: presented,
+			prepare: func(f *fields) {
+				f.refreshRepo.On("GetByHash", mock.Anything, mock.Anything).Return(models.RefreshToken{
+					ID:        1,
+					UserID:    user.ID,
+					FamilyID:  "family",
+					ClientID:  "some-client",
+					ExpiresAt: time.Now().UTC().Add(time.Hour),
+				}, nil)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrInvalidRefreshToken,
+		},
+		{
+			name:  "replayed token revokes the family",
+			token: presented,
+			prepare: func(f *fields) {
+				f.refreshRepo.On("GetByHash", mock.Anything, mock.Anything).Return(models.RefreshToken{
+					ID:        1,
+					UserID:    user.ID,
+					FamilyID:  "family",
+					ExpiresAt: time.Now().UTC().Add(time.Hour),
+					UsedAt:    &usedAt,
+				}, nil)
+				f.refreshRepo.On("RevokeFamily", mock.Anything, "family").Return(nil).Once()
+				f.sessionRepo.On("RevokeByFamily", mock.Anything, "family").Return(nil).Once()
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrRefreshTokenReused,
+		},
+		{
+			name:  "concurrent rotation of the same token revokes the family",
+			token: presented,
+			prepare: func(f *fields) {
+				f.refreshRepo.On("GetByHash", mock.Anything, mock.Anything).Return(models.RefreshToken{
+					ID:        1,
+					UserID:    user.ID,
+					FamilyID:  "family",
+					ExpiresAt: time.Now().UTC().Add(time.Hour),
+				}, nil)
+				f.refreshRepo.On("MarkUsed", mock.Anything, int64(1)).Return(false, nil)
+				f.refreshRepo.On("RevokeFamily", mock.Anything, "family").Return(nil).Once()
+				f.sessionRepo.On("RevokeByFamily", mock.Anything, "family").Return(nil).Once()
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    constants.ErrRefreshTokenReused,
+		},
+		{
+			name:  "valid token is rotated within its family",
+			token: presented,
+			prepare: func(f *fields) {
+				f.refreshRepo.On("GetByHash", mock.Anything, mock.Anything).Return(models.RefreshToken{
+					ID:        1,
+					UserID:    user.ID,
+					FamilyID:  "family",
+					ExpiresAt: time.Now().UTC().Add(time.Hour),
+				}, nil)
+				f.refreshRepo.On("MarkUsed", mock.Anything, int64(1)).Return(true, nil)
+				f.userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+				f.conf.On("AppConfig").Return(newTestAppConfig())
+				f.refreshRepo.On("Create", mock.Anything, mock.MatchedBy(func(rt *models.RefreshToken) bool {
+					return rt.FamilyID == "family" && rt.UserID == user.ID && rt.TokenHash != utils.HashToken(presented)
+				})).Return(nil).Once()
+				f.sessionRepo.On("Touch", mock.Anything, "family", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fields{
+				userRepo:    mocks.NewUserRepository(t),
+				refreshRepo: mocks.NewRefreshTokenRepository(t),
+				sessionRepo: mocks.NewSessionRepository(t),
+				conf:        configmocks.NewConfiguration(t),
+			}
+			tt.prepare(&f)
+
+			revocations := NewRevocationStore(nil, f.sessionRepo, f.conf)
+			svc := NewTokenService(f.userRepo, f.refreshRepo, newTestRoleRepository(t, nil, nil), f.sessionRepo, nil, revocations,
+				newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{}, f.conf)
+			status, got, err := svc.Refresh(context.Background(), tt.token, "")
+
+			assert.Equal(t, tt.wantStatus, status)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Equal(t, models.LoginResponse{}, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, got.AccessToken)
+			assert.NotEmpty(t, got.RefreshToken)
+			assert.NotEqual(t, tt.token, got.RefreshToken)
+			assert.Equal(t, user.Email, got.Email)
+		})
+	}
+}
+
+func Test_tokenService_Introspect(t *testing.T) {
+	const presented = "presented-refresh-token"
+	usedAt := time.Now().UTC().Add(-time.Minute)
+	disabledAt := time.Now().UTC().Add(-time.Minute)
+	owner := models.User{ID: 7, Email: "asif@example.com"}
+
+	tests := []struct {
+		name       string
+		hint       string
+		stored     models.RefreshToken
+		owner      models.User
+		wantActive bool
+	}{
+		{
+			name:       "unknown token is inactive",
+			stored:     models.RefreshToken{},
+			wantActive: false,
+		},
+		{
+			name:       "consumed refresh token is inactive",
+			hint:       constants.TokenTypeHintRefreshToken,
+			stored:     models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().UTC().Add(time.Hour), UsedAt: &usedAt},
+			wantActive: false,
+		},
+		{
+			name:       "expired refresh token is inactive",
+			hint:       constants.TokenTypeHintRefreshToken,
+			stored:     models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().UTC().Add(-time.Hour)},
+			wantActive: false,
+		},
+		{
+			name:       "live refresh token is active",
+			hint:       constants.TokenTypeHintRefreshToken,
+			stored:     models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().UTC().Add(time.Hour)},
+			owner:      owner,
+			wantActive: true,
+		},
+		{
+			name:       "live refresh token of a disabled user is inactive",
+			hint:       constants.TokenTypeHintRefreshToken,
+			stored:     models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().UTC().Add(time.Hour)},
+			owner:      models.User{ID: 7, Email: "asif@example.com", DisabledAt: &disabledAt},
+			wantActive: false,
+		},
+		{
+			name:       "live refresh token of a deleted user is inactive",
+			hint:       constants.TokenTypeHintRefreshToken,
+			stored:     models.RefreshToken{ID: 1, UserID: 7, ExpiresAt: time.Now().UTC().Add(time.Hour)},
+			owner:      models.User{},
+			wantActive: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refreshRepo := mocks.NewRefreshTokenRepository(t)
+			conf := configmocks.NewConfiguration(t)
+			conf.On("AppConfig").Return(newTestAppConfig()).Maybe()
+			refreshRepo.On("GetByHash", mock.Anything, utils.HashToken(presented)).Return(tt.stored, nil)
+			userRepo := mocks.NewUserRepository(t)
+			userRepo.On("GetByID", mock.Anything, tt.stored.UserID).Return(tt.owner, nil).Maybe()
+
+			svc := NewTokenService(userRepo, refreshRepo, nil, nil, nil, nil, newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{}, conf)
+			got, err := svc.Introspect(context.Background(), presented, tt.hint)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantActive, got.Active)
+			if tt.wantActive {
+				assert.Equal(t, "7", got.Subject)
+				assert.Equal(t, constants.TokenTypeHintRefreshToken, got.TokenType)
+			} else {
+				assert.Equal(t, models.IntrospectionResponse{}, got)
+			}
+		})
+	}
+}
+
+func Test_tokenService_IntrospectIDToken(t *testing.T) {
+	refreshRepo := mocks.NewRefreshTokenRepository(t)
+	refreshRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	refreshRepo.On("GetByHash", mock.Anything, mock.Anything).Return(models.RefreshToken{}, nil)
+
+	svc := NewTokenService(mocks.NewUserRepository(t), refreshRepo, newTestRoleRepository(t, nil, nil), newTestSessionRepository(t), nil, nil,
+		newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{}, newTestConfiguration())
+	resp, err := svc.IssueTokens(context.Background(), models.User{ID: 7, Email: "asif@example.com"}, models.TokenGrant{ClientID: testClientID, Scope: "openid"})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.IDToken)
+
+	_, err = svc.ValidateAccessToken(context.Background(), resp.IDToken)
+	assert.EqualError(t, err, constants.TokenInvalidType)
+
+	got, err := svc.Introspect(context.Background(), resp.IDToken, "")
+	require.NoError(t, err)
+	assert.False(t, got.Active)
+}
+
+func Test_tokenService_ValidateLegacyAccessToken(t *testing.T) {
+	claims := utils.NewTokenClaims("asif@example.com", time.Now().UTC().Unix())
+	claims.UserID = 7
+	token, err := utils.GenerateTokenWithCustomClaims(claims, "test-secret", time.Now().UTC().Add(time.Hour).Unix())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		until   string
+		wantErr bool
+	}{
+		{name: "accepted until the deadline", until: time.Now().UTC().Add(time.Hour).Format(time.RFC3339)},
+		{name: "refused after the deadline", until: time.Now().UTC().Add(-time.Hour).Format(time.RFC3339), wantErr: true},
+		{name: "refused without a deadline", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := viper.New()
+			env.Set(constants.SecretKey, "test-secret")
+			env.Set(constants.LegacyTokensUntil, tt.until)
+
+			svc := NewTokenService(nil, nil, nil, nil, nil, nil, newTestKeyManager(t, constants.SigningAlgEdDSA), &recordingAuditService{},
+				config.NewConfiguration(config.NewAppConfig(env)))
+			got, err := svc.ValidateAccessToken(context.Background(), token)
+			if tt.wantErr {
+				assert.EqualError(t, err, constants.TokenUnExpectedSigningMethod)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), got.UserID)
+		})
+	}
+}
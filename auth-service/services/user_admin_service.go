@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories"
+)
+
+// UserAdminService lets support staff manage user accounts. Every change is written to the audit
+// log with the admin who made it.
+type UserAdminService interface {
+	ListUsers(ctx context.Context, req models.UserListRequest) (int, models.UserListResponse, error)
+	GetUser(ctx context.Context, userID int64) (int, models.AdminUser, error)
+	DisableUser(ctx context.Context, actorID, userID int64, reason string) (int, error)
+	EnableUser(ctx context.Context, actorID, userID int64) (int, error)
+	ForcePasswordReset(ctx context.Context, actorID, userID int64) (int, error)
+	DeleteUser(ctx context.Context, actorID, userID int64) (int, error)
+	UnlockUser(ctx context.Context, actorID, userID int64) (int, error)
+	ImpersonateUser(ctx context.Context, actorID, userID int64, reason string) (int, models.LoginResponse, error)
+}
+
+// userAdminService is an implementation of UserAdminService
+type userAdminService struct {
+	userRepo    repositories.UserRepository
+	roleRepo    repositories.RoleRepository
+	mfaSvc      MFAService
+	tokenSvc    TokenService
+	passwordSvc PasswordService
+	throttle    LoginThrottle
+	audit       AuditService
+}
+
+// NewUserAdminService returns a new instance of the user admin service
+func NewUserAdminService(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	mfaSvc MFAService,
+	tokenSvc TokenService,
+	passwordSvc PasswordService,
+	throttle LoginThrottle,
+	audit AuditService,
+) UserAdminService {
+	return &userAdminService{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		mfaSvc:      mfaSvc,
+		tokenSvc:    tokenSvc,
+		passwordSvc: passwordSvc,
+		throttle:    throttle,
+		audit:       audit,
+	}
+}
+
+// ListUsers returns a page of users, optionally only those matching a search query
+func (s userAdminService) ListUsers(ctx context.Context, req models.UserListRequest) (int, models.UserListResponse, error) {
+	users, total, err := s.userRepo.List(ctx, req.Query, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		log.Println("error while listing users", err.Error())
+		return http.StatusInternalServerError, models.UserListResponse{}, err
+	}
+
+	items := make([]models.AdminUser, 0, len(users))
+	for _, user := range users {
+		items = append(items, adminUserOf(user))
+	}
+	return http.StatusOK, models.UserListResponse{
+		Items:      items,
+		Pagination: models.NewPagination(req.Page, req.PageSize, total),
+	}, nil
+}
+
+// GetUser returns a user account along with its roles and whether it has two-factor
+// authentication enabled
+func (s userAdminService) GetUser(ctx context.Context, userID int64) (int, models.AdminUser, error) {
+	status, user, err := s.fetchUser(ctx, userID)
+	if err != nil {
+		return status, models.AdminUser{}, err
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user roles", err.Error())
+		return http.StatusInternalServerError, models.AdminUser{}, err
+	}
+	mfaEnabled, err := s.mfaSvc.Enabled(ctx, userID)
+	if err != nil {
+		log.Println("error while checking mfa", err.Error())
+		return http.StatusInternalServerError, models.AdminUser{}, err
+	}
+
+	view := adminUserOf(user)
+	view.Roles = roles
+	view.MFAEnabled = mfaEnabled
+	return http.StatusOK, view, nil
+}
+
+// DisableUser stops a user from logging in and revokes all of their tokens. Admins cannot disable
+// themselves, so they cannot lock everyone out by accident.
+func (s userAdminService) DisableUser(ctx context.Context, actorID, userID int64, reason string) (int, error) {
+	if actorID == userID {
+		return http.StatusBadRequest, errors.New(constants.ErrCannotDisableSelf)
+	}
+	if status, _, err := s.fetchUser(ctx, userID); err != nil {
+		return status, err
+	}
+
+	if err := s.userRepo.SetDisabled(ctx, userID, true); err != nil {
+		log.Println("error while disabling user", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if status, err := s.tokenSvc.LogoutEverywhere(ctx, userID); err != nil {
+		return status, err
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditUserDisabled, userID, reason)
+	log.Printf("user %d disabled user %d", actorID, userID)
+	return http.StatusOK, nil
+}
+
+// EnableUser lets a disabled user log in again
+func (s userAdminService) EnableUser(ctx context.Context, actorID, userID int64) (int, error) {
+	if status, _, err := s.fetchUser(ctx, userID); err != nil {
+		return status, err
+	}
+
+	if err := s.userRepo.SetDisabled(ctx, userID, false); err != nil {
+		log.Println("error while enabling user", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditUserEnabled, userID, "")
+	log.Printf("user %d enabled user %d", actorID, userID)
+	return http.StatusOK, nil
+}
+
+// ForcePasswordReset logs a user out everywhere and refuses their password until they set a new
+// one through the reset link mailed to them
+func (s userAdminService) ForcePasswordReset(ctx context.Context, actorID, userID int64) (int, error) {
+	status, user, err := s.fetchUser(ctx, userID)
+	if err != nil {
+		return status, err
+	}
+
+	if err := s.userRepo.SetPasswordResetRequired(ctx, userID); err != nil {
+		log.Println("error while requiring password reset", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if status, err := s.tokenSvc.LogoutEverywhere(ctx, userID); err != nil {
+		return status, err
+	}
+	if status, err := s.passwordSvc.ForgotPassword(ctx, user.Email); err != nil {
+		return status, err
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditUserPasswordResetForced, userID, "")
+	log.Printf("user %d forced a password reset of user %d", actorID, userID)
+	return http.StatusOK, nil
+}
+
+// DeleteUser removes a user and everything that belongs to them. The audit entry keeps their
+// email, since the account cannot be looked up afterwards.
+func (s userAdminService) DeleteUser(ctx context.Context, actorID, userID int64) (int, error) {
+	if actorID == userID {
+		return http.StatusBadRequest, errors.New(constants.ErrCannotDeleteSelf)
+	}
+	status, user, err := s.fetchUser(ctx, userID)
+	if err != nil {
+		return status, err
+	}
+
+	deleted, err := s.userRepo.Delete(ctx, userID)
+	if err != nil {
+		log.Println("error while deleting user", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	// a concurrent request deleted the user first and recorded it
+	if !deleted {
+		return http.StatusNotFound, errors.New(constants.ErrUserNotFound)
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditUserDeleted, userID, user.Email)
+	log.Printf("user %d deleted user %d", actorID, userID)
+	return http.StatusOK, nil
+}
+
+// UnlockUser lifts the lockout of a user's account after failed logins
+func (s userAdminService) UnlockUser(ctx context.Context, actorID, userID int64) (int, error) {
+	status, user, err := s.fetchUser(ctx, userID)
+	if err != nil {
+		return status, err
+	}
+
+	if err := s.throttle.Unlock(ctx, user.Email); err != nil {
+		log.Println("error while unlocking user", err.Error())
+		return http.StatusInternalServerError, err
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditUserUnlocked, userID, "")
+	log.Printf("user %d unlocked user %d", actorID, userID)
+	return http.StatusOK, nil
+}
+
+// ImpersonateUser issues an access token that lets an admin act as a user, e.g. to reproduce a bug
+// they reported. The reason is required, since it is all the audit log says about what the admin
+// did as the user. Disabled users cannot be impersonated, as they could not log in themselves.
+func (s userAdminService) ImpersonateUser(ctx context.Context, actorID, userID int64, reason string) (int, models.LoginResponse, error) {
+	if actorID == userID {
+		return http.StatusBadRequest, models.LoginResponse{}, errors.New(constants.ErrCannotImpersonateSelf)
+	}
+	if reason == "" {
+		return http.StatusBadRequest, models.LoginResponse{}, errors.New(constants.ErrImpersonationReason)
+	}
+	status, user, err := s.fetchUser(ctx, userID)
+	if err != nil {
+		return status, models.LoginResponse{}, err
+	}
+	if user.Disabled() {
+		return http.StatusConflict, models.LoginResponse{}, errors.New(constants.ErrAccountDisabled)
+	}
+
+	resp, err := s.tokenSvc.IssueImpersonationToken(ctx, actorID, user)
+	if err != nil {
+		return http.StatusInternalServerError, models.LoginResponse{}, err
+	}
+
+	s.audit.Record(ctx, actorID, constants.AuditUserImpersonated, userID, reason)
+	log.Printf("user %d impersonated user %d", actorID, userID)
+	return http.StatusOK, resp, nil
+}
+
+// fetchUser returns 404 when there is no user with the given id
+func (s userAdminService) fetchUser(ctx context.Context, userID int64) (int, models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, models.User{}, err
+	}
+	if user.ID == 0 {
+		return http.StatusNotFound, models.User{}, errors.New(constants.ErrUserNotFound)
+	}
+	return http.StatusOK, user, nil
+}
+
+// adminUserOf returns the admin view of a user
+func adminUserOf(user models.User) models.AdminUser {
+	return models.AdminUser{
+		ID:                    user.ID,
+		Email:                 user.Email,
+		FirstName:             user.FirstName,
+		LastName:              user.LastName,
+		DisplayName:           user.DisplayName,
+		Username:              user.Username,
+		EmailVerified:         user.EmailVerified(),
+		Disabled:              user.Disabled(),
+		DisabledAt:            user.DisabledAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+	"auth-service/repositories/mocks"
+	"auth-service/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectAudit expects a single audit entry of the action by actorID about targetUserID
+func expectAudit(auditRepo *mocks.AuditRepository, actorID int64, action string, targetUserID int64) *mock.Call {
+	return auditRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.ActorUserID == actorID && entry.Action == action && entry.TargetUserID == targetUserID
+	})).Return(nil).Once()
+}
+
+// newTestLogoutTokenService returns a token service whose LogoutEverywhere succeeds for userID
+func newTestLogoutTokenService(t *testing.T, userID int64) TokenService {
+	refreshRepo := mocks.NewRefreshTokenRepository(t)
+	refreshRepo.On("RevokeAllForUser", mock.Anything, userID).Return(nil)
+	revokedRepo := mocks.NewRevokedTokenRepository(t)
+	revokedRepo.On("SetUserCutoff", mock.Anything, mock.Anything).Return(nil)
+	sessionRepo := mocks.NewSessionRepository(t)
+	sessionRepo.On("RevokeAllForUser", mock.Anything, userID).Return(nil)
+	conf := newTestConfiguration()
+	return NewTokenService(nil, refreshRepo, nil, sessionRepo, nil, NewRevocationStore(revokedRepo, sessionRepo, conf), nil, &recordingAuditService{}, conf)
+}
+
+func Test_userAdminService_DisableUser(t *testing.T) {
+	const adminID, userID = 1, 7
+
+	t.Run("admins cannot disable themselves", func(t *testing.T) {
+		svc := NewUserAdminService(nil, nil, nil, nil, nil, nil, nil)
+
+		status, err := svc.DisableUser(context.Background(), adminID, adminID, "")
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, constants.ErrCannotDisableSelf)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, int64(99)).Return(models.User{}, nil)
+		svc := NewUserAdminService(userRepo, nil, nil, nil, nil, nil, nil)
+
+		status, err := svc.DisableUser(context.Background(), adminID, 99, "")
+
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.EqualError(t, err, constants.ErrUserNotFound)
+	})
+
+	t.Run("user is disabled, logged out and the action audited", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, int64(userID)).Return(models.User{ID: userID}, nil)
+		userRepo.On("SetDisabled", mock.Anything, int64(userID), true).Return(nil)
+		auditRepo := mocks.NewAuditRepository(t)
+		expectAudit(auditRepo, adminID, constants.AuditUserDisabled, userID).Run(func(args mock.Arguments) {
+			entry := args.Get(1).(*models.AuditEntry)
+			assert.Equal(t, "spam", entry.Details)
+			assert.Equal(t, "203.0.113.9", entry.IP)
+		})
+		svc := NewUserAdminService(userRepo, nil, nil, newTestLogoutTokenService(t, userID), nil, nil, NewAuditService(auditRepo))
+		ctx := utils.ContextWithClientIP(context.Background(), "203.0.113.9")
+
+		status, err := svc.DisableUser(ctx, adminID, userID, "spam")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	})
+}
+
+func Test_userAdminService_ForcePasswordReset(t *testing.T) {
+	const adminID = 1
+	user := models.User{ID: 7, Email: "asif@example.com"}
+
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	userRepo.On("GetByUserEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepo.On("SetPasswordResetRequired", mock.Anything, user.ID).Return(nil)
+	resetRepo := mocks.NewPasswordResetRepository(t)
+	resetRepo.On("InvalidateForUser", mock.Anything, user.ID).Return(nil)
+	resetRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	auditRepo := mocks.NewAuditRepository(t)
+	expectAudit(auditRepo, adminID, constants.AuditUserPasswordResetForced, user.ID)
+	mailer := &recordingMailer{}
+	conf := newTestConfiguration()
+	passwordSvc := NewPasswordService(userRepo, resetRepo, nil, nil, nil, mailer, conf)
+	svc := NewUserAdminService(userRepo, nil, nil, newTestLogoutTokenService(t, user.ID), passwordSvc, nil, NewAuditService(auditRepo))
+
+	status, err := svc.ForcePasswordReset(context.Background(), adminID, user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, user.Email, mailer.sent[0].To)
+}
+
+func Test_userAdminService_DeleteUser(t *testing.T) {
+	const adminID = 1
+	user := models.User{ID: 7, Email: "asif@example.com"}
+
+	t.Run("admins cannot delete themselves", func(t *testing.T) {
+		svc := NewUserAdminService(nil, nil, nil, nil, nil, nil, nil)
+
+		status, err := svc.DeleteUser(context.Background(), adminID, adminID)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, constants.ErrCannotDeleteSelf)
+	})
+
+	t.Run("user is deleted and their email kept in the audit log", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		userRepo.On("Delete", mock.Anything, user.ID).Return(true, nil)
+		auditRepo := mocks.NewAuditRepository(t)
+		expectAudit(auditRepo, adminID, constants.AuditUserDeleted, user.ID).Run(func(args mock.Arguments) {
+			assert.Equal(t, user.Email, args.Get(1).(*models.AuditEntry).Details)
+		})
+		svc := NewUserAdminService(userRepo, nil, nil, nil, nil, nil, NewAuditService(auditRepo))
+
+		status, err := svc.DeleteUser(context.Background(), adminID, user.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	})
+}
+
+func Test_userAdminService_ListUsers(t *testing.T) {
+	disabledAt := time.Now().UTC()
+	userRepo := mocks.NewUserRepository(t)
+	userRepo.On("List", mock.Anything, "asif", 2, 2).Return([]models.User{
+		{ID: 3, Email: "asif@example.com", DisabledAt: &disabledAt},
+	}, int64(3), nil)
+	svc := NewUserAdminService(userRepo, nil, nil, nil, nil, nil, nil)
+
+	status, got, err := svc.ListUsers(context.Background(), models.UserListRequest{Query: "asif", Page: 2, PageSize: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	require.Len(t, got.Items, 1)
+	assert.True(t, got.Items[0].Disabled)
+	assert.Equal(t, models.Pagination{
+		CurrentPage:    2,
+		PageSize:       2,
+		TotalItemCount: 3,
+		TotalPages:     2,
+		IsLastPage:     true,
+	}, got.Pagination)
+}
+
+func Test_userAdminService_ImpersonateUser(t *testing.T) {
+	const adminID = 1
+	user := models.User{ID: 7, Email: "asif@example.com"}
+
+	t.Run("admins cannot impersonate themselves", func(t *testing.T) {
+		svc := NewUserAdminService(nil, nil, nil, nil, nil, nil, nil)
+
+		status, _, err := svc.ImpersonateUser(context.Background(), adminID, adminID, "ticket 42")
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, constants.ErrCannotImpersonateSelf)
+	})
+
+	t.Run("a reason is required", func(t *testing.T) {
+		svc := NewUserAdminService(nil, nil, nil, nil, nil, nil, nil)
+
+		status, _, err := svc.ImpersonateUser(context.Background(), adminID, user.ID, "")
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, constants.ErrImpersonationReason)
+	})
+
+	t.Run("disabled users cannot be impersonated", func(t *testing.T) {
+		disabledAt := time.Now().UTC()
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(models.User{ID: user.ID, DisabledAt: &disabledAt}, nil)
+		svc := NewUserAdminService(userRepo, nil, nil, nil, nil, nil, nil)
+
+		status, _, err := svc.ImpersonateUser(context.Background(), adminID, user.ID, "ticket 42")
+
+		assert.Equal(t, http.StatusConflict, status)
+		assert.EqualError(t, err, constants.ErrAccountDisabled)
+	})
+
+	t.Run("token acts as the user on behalf of the admin and is audited", func(t *testing.T) {
+		userRepo := mocks.NewUserRepository(t)
+		userRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+		auditRepo := mocks.NewAuditRepository(t)
+		expectAudit(auditRepo, adminID, constants.AuditUserImpersonated, user.ID).Run(func(args mock.Arguments) {
+			assert.Equal(t, "ticket 42", args.Get(1).(*models.AuditEntry).Details)
+		})
+		events := &recordingAuditService{}
+		tokenSvc := NewTokenService(nil, nil, newTestRoleRepository(t, nil, nil), nil, nil, nil,
+			newTestKeyManager(t, constants.SigningAlgEdDSA), events, newTestConfiguration())
+		svc := NewUserAdminService(userRepo, nil, nil, tokenSvc, nil, nil, NewAuditService(auditRepo))
+
+		status, resp, err := svc.ImpersonateUser(context.Background(), adminID, user.ID, "ticket 42")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, resp.RefreshToken)
+		assert.WithinDuration(t, time.Now().Add(constants.DefaultImpersonationTokenTTL), time.Unix(resp.ExpiresAt, 0), time.Minute)
+
+		claims, err := tokenSvc.ValidateAccessToken(context.Background(), resp.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.Empty(t, claims.SessionID)
+		require.True(t, claims.Impersonated())
+		assert.Equal(t, &models.Actor{Subject: "1", UserID: adminID}, claims.Actor)
+
+		assert.Empty(t, events.events, "impersonation is audited once, as the admin action")
+	})
+}
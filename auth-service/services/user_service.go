@@ -6,33 +6,53 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
 	"auth-service/config"
 	"auth-service/constants"
 	"auth-service/models"
 	"auth-service/repositories"
 	"auth-service/utils"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 // UserService is an  interface for user service
 type UserService interface {
 	Register(ctx context.Context, user *models.User) (int, error)
 	Login(ctx context.Context, loginReq models.LoginRequest) (int, models.LoginResponse, error)
-	VerifyToken(ctx context.Context, token string) (int, error)
+	LoginMFA(ctx context.Context, req models.MFALoginRequest) (int, models.LoginResponse, error)
+	Authenticate(ctx context.Context, email, password string) (int, models.User, error)
+	VerifySecondFactor(ctx context.Context, user models.User, code string) (int, error)
+	VerifyToken(ctx context.Context, token string) (int, *utils.TokenClaims, error)
 	RefreshToken(ctx context.Context, token string) (int, models.LoginResponse, error)
+	UserInfo(ctx context.Context, userID int64) (int, models.UserInfo, error)
+	GetUser(ctx context.Context, userID int64) (int, models.User, error)
+	GetUsers(ctx context.Context, userIDs []int64) (int, []models.User, error)
 }
 
 // userService is an implementation of UserService
 type userService struct {
-	repo repositories.UserRepository
-	conf config.Configuration
+	repo      repositories.UserRepository
+	tokenSvc  TokenService
+	verifySvc EmailVerificationService
+	throttle  LoginThrottle
+	hasher    PasswordHasher
+	policy    PasswordPolicy
+	mfaSvc    MFAService
+	invites   InviteService
+	audit     AuditService
+	conf      config.Configuration
 }
 
-// Register creates a new user after hashing the password
+// Register creates a new user after hashing the password and mails them a verification link.
+// A failed mail does not fail the registration since the user can ask for a new link. The
+// registration mode decides who may register at all; an invite code given with the user is used
+// up and grants the role of the invite.
 func (u userService) Register(ctx context.Context, user *models.User) (int, error) {
+	status, invite, err := u.invites.CheckRegistration(ctx, user.Email, user.InviteCode)
+	if err != nil {
+		return status, err
+	}
 
 	existingUser, err := u.repo.GetByUserEmail(ctx, user.Email)
 
@@ -40,99 +60,403 @@ func (u userService) Register(ctx context.Context, user *models.User) (int, erro
 		log.Printf("error while getting user by email: %v", err)
 		return http.StatusBadRequest, err
 	}
-	log.Printf("existing user %v", existingUser)
 	if existingUser.ID != 0 {
 		log.Printf("user with email %s already exists", user.Email)
 		return http.StatusBadRequest, fmt.Errorf("user with email %s already exists", user.Email)
 	}
 
+	if err := u.policy.Validate(user.Password); err != nil {
+		return http.StatusBadRequest, err
+	}
+
 	// hash the password, because we don't want to store plain text password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := u.hasher.Hash(user.Password)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
 
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
 
 	// save the user
 	err = u.repo.Create(ctx, user)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
+
+	event := models.AuthEvent{Type: constants.EventUserRegistered, UserID: user.ID, Email: user.Email}
+	if invite.ID != 0 {
+		// another registration used the invite first; take this one back
+		if status, err := u.invites.Redeem(ctx, invite, user.ID); err != nil {
+			if _, deleteErr := u.repo.Delete(ctx, user.ID); deleteErr != nil {
+				log.Printf("error while deleting user %d after failed invite: %v", user.ID, deleteErr)
+			}
+			return status, err
+		}
+		event.Details = map[string]string{constants.EventDetailInviteID: strconv.FormatInt(invite.ID, 10)}
+	}
+	u.audit.Log(ctx, event)
+
+	if u.conf.AppConfig().EmailVerificationPolicy() != constants.EmailVerificationOff {
+		if err := u.verifySvc.SendVerification(ctx, *user); err != nil {
+			log.Printf("error while sending verification email to user %d: %v", user.ID, err)
+		}
+	}
 	return http.StatusOK, nil
 }
 
-// Login service handles business logic  for login
+// Login service handles business logic  for login. Users with two-factor authentication get an
+// mfa_token instead of tokens, which LoginMFA exchanges together with a code.
 func (u userService) Login(ctx context.Context, loginReq models.LoginRequest) (int, models.LoginResponse, error) {
-	user, err := u.repo.GetByUserEmail(ctx, loginReq.Email)
+	status, user, mfaEnabled, err := u.authenticate(ctx, loginReq.Email, loginReq.Password)
 	if err != nil {
-		log.Println("user fetch error")
-		return http.StatusUnauthorized, models.LoginResponse{}, errors.New(constants.ErrInvalidEmailOrPass)
+		return status, models.LoginResponse{}, err
 	}
 
-	log.Printf("db password %s form password %s", user.Password, loginReq.Password)
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password)); err != nil {
-		log.Println("user bcrypt error ", err.Error())
-		return http.StatusInternalServerError, models.LoginResponse{}, errors.New(constants.ErrInvalidEmailOrPass)
+	if mfaEnabled {
+		mfaToken, expiresAt, err := u.mfaSvc.StartChallenge(ctx, user.ID)
+		if err != nil {
+			log.Println("error while starting mfa challenge", err.Error())
+			return http.StatusInternalServerError, models.LoginResponse{}, err
+		}
+		return http.StatusOK, models.LoginResponse{
+			Email:       user.Email,
+			ExpiresAt:   expiresAt.Unix(),
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
 	}
 
-	// generate token
-	claims := utils.NewTokenClaims(user.Email, time.Now().UTC().Unix())
-
-	secretKey := u.conf.AppConfig().SecretKey()
-	expiresAt := time.Now().UTC().Add(time.Hour * 24 * 7).Unix()
-
-	tokenStr, err := utils.GenerateTokenWithCustomClaims(claims, secretKey, expiresAt)
+	loginResp, err := u.tokenSvc.IssueTokens(ctx, user, models.TokenGrant{})
 	if err != nil {
-		log.Println("error while generating token", err.Error())
 		return http.StatusInternalServerError, models.LoginResponse{}, err
 	}
 
-	// prepare response
-	loginResp := models.LoginResponse{
-		AccessToken: tokenStr,
-		Email:       user.Email,
-		ExpiresAt:   expiresAt,
-	}
+	u.loginSucceeded(ctx, user, constants.LoginMethodPassword)
 	log.Printf("user logged in successfully: %s", loginReq.Email)
 
 	return http.StatusOK, loginResp, nil
 }
 
-// VerifyToken provides the business logic to verify JWT tokens
-func (u userService) VerifyToken(_ context.Context, token string) (int, error) {
-	secretKey := u.conf.AppConfig().SecretKey()
+// LoginMFA completes the login of a user with two-factor authentication. Wrong codes count as
+// failed logins of the account, so they are throttled like wrong passwords.
+func (u userService) LoginMFA(ctx context.Context, req models.MFALoginRequest) (int, models.LoginResponse, error) {
+	status, userID, err := u.mfaSvc.CompleteChallenge(ctx, req.MFAToken, req.Code)
+	if userID == 0 {
+		return status, models.LoginResponse{}, err
+	}
 
-	const (
-		statusUnauthorized = http.StatusUnauthorized
-		errInvalidToken    = "invalid token"
-	)
+	user, fetchErr := u.repo.GetByID(ctx, userID)
+	if fetchErr != nil {
+		log.Println("error while fetching user", fetchErr.Error())
+		return http.StatusInternalServerError, models.LoginResponse{}, fetchErr
+	}
+	if user.ID == 0 {
+		return http.StatusUnauthorized, models.LoginResponse{}, errors.New(constants.ErrInvalidMFAToken)
+	}
+	// an admin disabled the account after the password was accepted
+	if user.Disabled() {
+		u.loginFailed(ctx, user.ID, user.Email, constants.LoginFailureDisabled)
+		return http.StatusForbidden, models.LoginResponse{}, errors.New(constants.ErrAccountDisabled)
+	}
+	if err != nil {
+		u.recordFailure(ctx, user.Email)
+		u.loginFailed(ctx, user.ID, user.Email, constants.LoginFailureInvalidMFACode)
+		return status, models.LoginResponse{}, err
+	}
+	u.recordSuccess(ctx, user.Email)
 
-	if err := u.validateToken(token, secretKey); err != nil {
-		log.Println("error validating token:", err)
-		return statusUnauthorized, err
+	loginResp, err := u.tokenSvc.IssueTokens(ctx, user, models.TokenGrant{})
+	if err != nil {
+		return http.StatusInternalServerError, models.LoginResponse{}, err
 	}
 
+	u.loginSucceeded(ctx, user, constants.LoginMethodMFA)
+	log.Printf("user logged in successfully: %s", user.Email)
+
+	return http.StatusOK, loginResp, nil
+}
+
+// Authenticate checks the user's password. It backs both the JSON login and the interactive
+// OAuth authorization step. A stored hash made with outdated parameters is replaced by a new one
+// while the plain password is at hand. Failed attempts are throttled per account and client IP; while
+// throttled, a *models.LoginThrottledError is returned without checking the password. Under the
+// required email verification policy unverified users are refused once their password checked out,
+// as are disabled users and users an admin made reset their password. Users with two-factor
+// authentication must pass VerifySecondFactor as well.
+func (u userService) Authenticate(ctx context.Context, email, password string) (int, models.User, error) {
+	status, user, _, err := u.authenticate(ctx, email, password)
+	return status, user, err
+}
+
+// VerifySecondFactor checks the authentication code of a user whose password was accepted by
+// Authenticate. Users without two-factor authentication pass without a code. Passing completes the
+// login of the interactive OAuth authorization step.
+func (u userService) VerifySecondFactor(ctx context.Context, user models.User, code string) (int, error) {
+	enabled, err := u.mfaSvc.Enabled(ctx, user.ID)
+	if err != nil {
+		log.Println("error while checking mfa", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !enabled {
+		u.loginSucceeded(ctx, user, constants.LoginMethodPassword)
+		return http.StatusOK, nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return http.StatusUnauthorized, errors.New(constants.ErrMFACodeRequired)
+	}
+
+	ok, err := u.mfaSvc.VerifyCode(ctx, user.ID, code)
+	if err != nil {
+		log.Println("error while verifying mfa code", err.Error())
+		return http.StatusInternalServerError, err
+	}
+	if !ok {
+		u.recordFailure(ctx, user.Email)
+		u.loginFailed(ctx, user.ID, user.Email, constants.LoginFailureInvalidMFACode)
+		return http.StatusUnauthorized, errors.New(constants.ErrInvalidMFACode)
+	}
+	u.recordSuccess(ctx, user.Email)
+	u.loginSucceeded(ctx, user, constants.LoginMethodMFA)
 	return http.StatusOK, nil
 }
 
-func (u userService) RefreshToken(_ context.Context, token string) (int, models.LoginResponse, error) {
-	// TODO: implement refresh token logic
-	panic("")
+// authenticate implements Authenticate and also reports whether the user has two-factor
+// authentication enabled. Failed logins of such users are only forgotten once their code checked
+// out as well, so that codes cannot be guessed endlessly with a known password.
+func (u userService) authenticate(ctx context.Context, email, password string) (int, models.User, bool, error) {
+	ip := utils.ClientIPFromContext(ctx)
+	if err := u.throttle.Check(ctx, email, ip); err != nil {
+		var throttled *models.LoginThrottledError
+		if errors.As(err, &throttled) {
+			log.Printf("login throttled for %s from %q", email, ip)
+			u.loginFailed(ctx, 0, email, constants.LoginFailureThrottled)
+			return throttled.Status, models.User{}, false, throttled
+		}
+		log.Println("error while checking login attempts", err.Error())
+		return http.StatusInternalServerError, models.User{}, false, err
+	}
+
+	user, err := u.repo.GetByUserEmail(ctx, email)
+	if err != nil {
+		log.Println("user fetch error")
+		return http.StatusUnauthorized, models.User{}, false, errors.New(constants.ErrInvalidEmailOrPass)
+	}
+
+	// unknown emails fail the hash comparison like wrong passwords do, and take as long
+	matched, err := u.hasher.Verify(password, user.Password)
+	if err != nil && user.ID != 0 {
+		log.Printf("error while verifying password of user %d: %v", user.ID, err)
+	}
+	if !matched || user.ID == 0 {
+		u.recordFailure(ctx, email)
+		u.loginFailed(ctx, user.ID, email, constants.LoginFailureInvalidCredentials)
+		return http.StatusUnauthorized, models.User{}, false, errors.New(constants.ErrInvalidEmailOrPass)
+	}
+	// only tell who knows the password that the account is disabled
+	if user.Disabled() {
+		u.loginFailed(ctx, user.ID, email, constants.LoginFailureDisabled)
+		return http.StatusForbidden, models.User{}, false, errors.New(constants.ErrAccountDisabled)
+	}
+	if user.PasswordResetRequired {
+		u.loginFailed(ctx, user.ID, email, constants.LoginFailureResetRequired)
+		return http.StatusForbidden, models.User{}, false, errors.New(constants.ErrPasswordResetRequired)
+	}
+	u.rehashPassword(ctx, user.ID, user.Password, password)
+
+	mfaEnabled, err := u.mfaSvc.Enabled(ctx, user.ID)
+	if err != nil {
+		log.Println("error while checking mfa", err.Error())
+		return http.StatusInternalServerError, models.User{}, false, err
+	}
+	if !mfaEnabled {
+		u.recordSuccess(ctx, email)
+	}
+
+	if !user.EmailVerified() && u.conf.AppConfig().EmailVerificationPolicy() == constants.EmailVerificationRequired {
+		u.loginFailed(ctx, user.ID, email, constants.LoginFailureEmailNotVerified)
+		return http.StatusForbidden, models.User{}, false, errors.New(constants.ErrEmailNotVerified)
+	}
+
+	return http.StatusOK, user, mfaEnabled, nil
 }
 
-// validateToken checks if the provided token is valid.
-func (u userService) validateToken(token, secretKey string) error {
-	if _, err := utils.ValidateToken(token, secretKey); err != nil {
-		return errors.New("invalid token")
+// VerifyToken provides the business logic to verify JWT tokens and returns their claims. Tokens of
+// users who were disabled or deleted since they were issued are rejected.
+func (u userService) VerifyToken(ctx context.Context, token string) (int, *utils.TokenClaims, error) {
+	claims, err := u.tokenSvc.ValidateAccessToken(ctx, token)
+	if err != nil {
+		log.Println("error validating token:", err)
+		return http.StatusUnauthorized, nil, err
 	}
-	return nil
+
+	if claims.UserID != 0 {
+		user, err := u.repo.GetByID(ctx, claims.UserID)
+		if err != nil {
+			log.Println("error while fetching user", err.Error())
+			return http.StatusInternalServerError, nil, err
+		}
+		if user.ID == 0 {
+			return http.StatusUnauthorized, nil, errors.New(constants.TokenInvalid)
+		}
+		if user.Disabled() {
+			return http.StatusUnauthorized, nil, errors.New(constants.ErrAccountDisabled)
+		}
+	}
+
+	return http.StatusOK, claims, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access/refresh token pair
+func (u userService) RefreshToken(ctx context.Context, token string) (int, models.LoginResponse, error) {
+	return u.tokenSvc.Refresh(ctx, token, "")
 }
 
 // NewUserService returns a new instance of the service
-func NewUserService(repo repositories.UserRepository, conf config.Configuration) UserService {
+func NewUserService(
+	repo repositories.UserRepository,
+	tokenSvc TokenService,
+	verifySvc EmailVerificationService,
+	throttle LoginThrottle,
+	hasher PasswordHasher,
+	policy PasswordPolicy,
+	mfaSvc MFAService,
+	invites InviteService,
+	audit AuditService,
+	conf config.Configuration,
+) UserService {
 	return &userService{
-		repo: repo,
-		conf: conf,
+		repo:      repo,
+		tokenSvc:  tokenSvc,
+		verifySvc: verifySvc,
+		throttle:  throttle,
+		hasher:    hasher,
+		policy:    policy,
+		mfaSvc:    mfaSvc,
+		invites:   invites,
+		audit:     audit,
+		conf:      conf,
+	}
+}
+
+// UserInfo returns the OpenID Connect claims of the user an access token was issued to
+func (u userService) UserInfo(ctx context.Context, userID int64) (int, models.UserInfo, error) {
+	user, err := u.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, models.UserInfo{}, err
+	}
+	// the token outlived its user
+	if user.ID == 0 {
+		return http.StatusUnauthorized, models.UserInfo{}, errors.New(constants.TokenInvalid)
+	}
+
+	name := user.DisplayName
+	if name == "" {
+		name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+
+	return http.StatusOK, models.UserInfo{
+		Subject:           strconv.FormatInt(user.ID, 10),
+		Email:             user.Email,
+		Name:              name,
+		GivenName:         user.FirstName,
+		FamilyName:        user.LastName,
+		PreferredUsername: user.Username,
+		Picture:           user.AvatarURL,
+	}, nil
+}
+
+// GetUser returns the user with the given id, or 404 if there is none
+func (u userService) GetUser(ctx context.Context, userID int64) (int, models.User, error) {
+	user, err := u.repo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("error while fetching user", err.Error())
+		return http.StatusInternalServerError, models.User{}, err
+	}
+	if user.ID == 0 {
+		return http.StatusNotFound, models.User{}, errors.New(constants.ErrUserNotFound)
+	}
+	return http.StatusOK, user, nil
+}
+
+// GetUsers returns the users with the given ids in the order they were asked for. Unknown ids are
+// left out and repeated ones returned once. At most MaxBatchGetUsers ids can be asked for at once.
+func (u userService) GetUsers(ctx context.Context, userIDs []int64) (int, []models.User, error) {
+	if len(userIDs) > constants.MaxBatchGetUsers {
+		return http.StatusBadRequest, nil, fmt.Errorf(constants.ErrTooManyUserIDs, constants.MaxBatchGetUsers)
+	}
+	if len(userIDs) == 0 {
+		return http.StatusOK, []models.User{}, nil
+	}
+
+	found, err := u.repo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		log.Println("error while fetching users", err.Error())
+		return http.StatusInternalServerError, nil, err
+	}
+
+	byID := make(map[int64]models.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+	users := make([]models.User, 0, len(found))
+	for _, id := range userIDs {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+			delete(byID, id)
+		}
+	}
+	return http.StatusOK, users, nil
+}
+
+// recordFailure counts a failed login of the account and the client IP of the request
+func (u userService) recordFailure(ctx context.Context, email string) {
+	if err := u.throttle.RecordFailure(ctx, email, utils.ClientIPFromContext(ctx)); err != nil {
+		log.Println("error while recording failed login", err.Error())
+	}
+}
+
+// recordSuccess forgets the failed logins of the account
+func (u userService) recordSuccess(ctx context.Context, email string) {
+	if err := u.throttle.RecordSuccess(ctx, email); err != nil {
+		log.Println("error while resetting failed logins", err.Error())
+	}
+}
+
+// loginSucceeded records a completed login of the user
+func (u userService) loginSucceeded(ctx context.Context, user models.User, method string) {
+	u.audit.Log(ctx, models.AuthEvent{
+		Type:    constants.EventLoginSucceeded,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Details: map[string]string{constants.EventDetailMethod: method},
+	})
+}
+
+// loginFailed records a refused login. userID is zero when the email does not belong to an account.
+func (u userService) loginFailed(ctx context.Context, userID int64, email, reason string) {
+	u.audit.Log(ctx, models.AuthEvent{
+		Type:    constants.EventLoginFailed,
+		UserID:  userID,
+		Email:   email,
+		Details: map[string]string{constants.EventDetailReason: reason},
+	})
+}
+
+// rehashPassword replaces the stored hash of a user when it was made with another algorithm or
+// other parameters than new hashes are. Failures are logged; the old hash keeps working.
+func (u userService) rehashPassword(ctx context.Context, userID int64, stored, password string) {
+	if !u.hasher.NeedsRehash(stored) {
+		return
+	}
+	hash, err := u.hasher.Hash(password)
+	if err != nil {
+		log.Printf("error while rehashing password of user %d: %v", userID, err)
+		return
+	}
+	if err := u.repo.UpdatePassword(ctx, userID, hash); err != nil {
+		log.Printf("error while storing rehashed password of user %d: %v", userID, err)
+		return
 	}
+	log.Printf("rehashed password of user %d", userID)
 }
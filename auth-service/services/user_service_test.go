@@ -3,22 +3,36 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"auth-service/config"
 	configmocks "auth-service/config/mocks"
+	"auth-service/constants"
 	"auth-service/models"
 	"auth-service/repositories"
 	"auth-service/repositories/mocks"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewUserService(t *testing.T) {
 	type args struct {
-		repo repositories.UserRepository
-		conf config.Configuration
+		repo      repositories.UserRepository
+		tokenSvc  TokenService
+		verifySvc EmailVerificationService
+		throttle  LoginThrottle
+		hasher    PasswordHasher
+		policy    PasswordPolicy
+		mfaSvc    MFAService
+		invites   InviteService
+		events    AuditService
+		conf      config.Configuration
 	}
 	tests := []struct {
 		name string
@@ -36,6 +50,99 @@ func TestNewUserService(t *testing.T) {
 				conf: configmocks.NewConfiguration(t),
 			},
 		},
+		{
+			name: "create new UserService with token service",
+			args: args{
+				repo:     mocks.NewUserRepository(t),
+				tokenSvc: &tokenService{},
+				conf:     configmocks.NewConfiguration(t),
+			},
+			want: &userService{
+				repo:     mocks.NewUserRepository(t),
+				tokenSvc: &tokenService{},
+				conf:     configmocks.NewConfiguration(t),
+			},
+		},
+		{
+			name: "create new UserService with email verification service",
+			args: args{
+				repo:      mocks.NewUserRepository(t),
+				verifySvc: &emailVerificationService{},
+				conf:      configmocks.NewConfiguration(t),
+			},
+			want: &userService{
+				repo:      mocks.NewUserRepository(t),
+				verifySvc: &emailVerificationService{},
+				conf:      configmocks.NewConfiguration(t),
+			},
+		},
+		{
+			name: "create new UserService with login throttle",
+			args: args{
+				repo:     mocks.NewUserRepository(t),
+				throttle: &loginThrottle{},
+				conf:     configmocks.NewConfiguration(t),
+			},
+			want: &userService{
+				repo:     mocks.NewUserRepository(t),
+				throttle: &loginThrottle{},
+				conf:     configmocks.NewConfiguration(t),
+			},
+		},
+		{
+			name: "create new UserService with password hasher and policy",
+			args: args{
+				repo:   mocks.NewUserRepository(t),
+				hasher: &passwordHasher{},
+				policy: &passwordPolicy{},
+				conf:   configmocks.NewConfiguration(t),
+			},
+			want: &userService{
+				repo:   mocks.NewUserRepository(t),
+				hasher: &passwordHasher{},
+				policy: &passwordPolicy{},
+				conf:   configmocks.NewConfiguration(t),
+			},
+		},
+		{
+			name: "create new UserService with mfa service",
+			args: args{
+				repo:   mocks.NewUserRepository(t),
+				mfaSvc: &mfaService{},
+				conf:   configmocks.NewConfiguration(t),
+			},
+			want: &userService{
+				repo:   mocks.NewUserRepository(t),
+				mfaSvc: &mfaService{},
+				conf:   configmocks.NewConfiguration(t),
+			},
+		},
+		{
+			name: "create new UserService with invite service",
+			args: args{
+				repo:    mocks.NewUserRepository(t),
+				invites: &inviteService{},
+				conf:    configmocks.NewConfiguration(t),
+			},
+			want: &userService{
+				repo:    mocks.NewUserRepository(t),
+				invites: &inviteService{},
+				conf:    configmocks.NewConfiguration(t),
+			},
+		},
+		{
+			name: "create new UserService with audit service",
+			args: args{
+				repo:   mocks.NewUserRepository(t),
+				events: &auditService{},
+				conf:   configmocks.NewConfiguration(t),
+			},
+			want: &userService{
+				repo:  mocks.NewUserRepository(t),
+				audit: &auditService{},
+				conf:  configmocks.NewConfiguration(t),
+			},
+		},
 		{
 			name: "create new UserService with nil repository",
 			args: args{
@@ -55,7 +162,7 @@ func TestNewUserService(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewUserService(tt.args.repo, tt.args.conf)
+			got := NewUserService(tt.args.repo, tt.args.tokenSvc, tt.args.verifySvc, tt.args.throttle, tt.args.hasher, tt.args.policy, tt.args.mfaSvc, tt.args.invites, tt.args.events, tt.args.conf)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewUserService() = %v, want %v", got, tt.want)
 			}
@@ -132,8 +239,11 @@ func Test_userService_Login(t *testing.T) {
 				tt.prepare(&tt.fields)
 			}
 			u := userService{
-				repo: tt.fields.repo,
-				conf: tt.fields.conf,
+				repo:     tt.fields.repo,
+				throttle: NewLoginThrottle(repositories.NewMemoryLoginAttemptRepository(), newTestConfiguration()),
+				hasher:   NewPasswordHasher(newTestConfiguration()),
+				audit:    &recordingAuditService{},
+				conf:     tt.fields.conf,
 			}
 			_, got, err := u.Login(tt.args.ctx, tt.args.loginReq)
 			if (err != nil) != tt.wantErr {
@@ -165,8 +275,9 @@ func Test_userService_Register(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			u := userService{
-				repo: tt.fields.repo,
-				conf: tt.fields.conf,
+				repo:  tt.fields.repo,
+				audit: &recordingAuditService{},
+				conf:  tt.fields.conf,
 			}
 			if _, err := u.Register(tt.args.ctx, tt.args.user); (err != nil) != tt.wantErr {
 				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
@@ -196,9 +307,109 @@ func Test_userService_Verify(t *testing.T) {
 				repo: tt.fields.repo,
 				conf: tt.fields.conf,
 			}
-			if _, err := u.VerifyToken(tt.args.in0, tt.args.req.Token); (err != nil) != tt.wantErr {
+			if _, _, err := u.VerifyToken(tt.args.in0, tt.args.req.Token); (err != nil) != tt.wantErr {
 				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_userService_UserInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       models.User
+		wantStatus int
+		want       models.UserInfo
+		wantErr    bool
+	}{
+		{
+			name:       "known user",
+			user:       models.User{ID: 7, Email: "asif@example.com", FirstName: "Asif", LastName: "Khan"},
+			wantStatus: http.StatusOK,
+			want: models.UserInfo{
+				Subject:    "7",
+				Email:      "asif@example.com",
+				Name:       "Asif Khan",
+				GivenName:  "Asif",
+				FamilyName: "Khan",
+			},
+		},
+		{
+			name:       "deleted user",
+			user:       models.User{},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewUserRepository(t)
+			repo.On("GetByID", mock.Anything, int64(7)).Return(tt.user, nil)
+
+			u := userService{repo: repo}
+			status, got, err := u.UserInfo(context.Background(), 7)
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_userService_GetUsers(t *testing.T) {
+	t.Run("users come back in the order asked for, without unknown or repeated ids", func(t *testing.T) {
+		repo := mocks.NewUserRepository(t)
+		repo.On("GetByIDs", mock.Anything, []int64{3, 99, 1, 3}).Return([]models.User{{ID: 1}, {ID: 3}}, nil)
+
+		u := userService{repo: repo}
+		status, got, err := u.GetUsers(context.Background(), []int64{3, 99, 1, 3})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []models.User{{ID: 3}, {ID: 1}}, got)
+	})
+
+	t.Run("too many ids", func(t *testing.T) {
+		u := userService{}
+		status, _, err := u.GetUsers(context.Background(), make([]int64, constants.MaxBatchGetUsers+1))
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.EqualError(t, err, fmt.Sprintf(constants.ErrTooManyUserIDs, constants.MaxBatchGetUsers))
+	})
+}
+
+func Test_userService_AuthenticateRestrictedAccount(t *testing.T) {
+	hasher := newTestPasswordHasher(constants.PasswordHashBcrypt, 4, 1024)
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	disabledAt := time.Now().UTC()
+
+	tests := []struct {
+		name    string
+		user    models.User
+		wantErr string
+	}{
+		{
+			name:    "disabled account",
+			user:    models.User{ID: 7, Email: "asif@example.com", Password: hash, DisabledAt: &disabledAt},
+			wantErr: constants.ErrAccountDisabled,
+		},
+		{
+			name:    "password reset required",
+			user:    models.User{ID: 7, Email: "asif@example.com", Password: hash, PasswordResetRequired: true},
+			wantErr: constants.ErrPasswordResetRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewUserRepository(t)
+			repo.On("GetByUserEmail", mock.Anything, tt.user.Email).Return(tt.user, nil)
+			u := NewUserService(repo, nil, nil, newTestLoginThrottle(), hasher, nil, nil, nil, &recordingAuditService{}, newTestConfiguration())
+
+			status, _, err := u.Login(context.Background(), models.LoginRequest{Email: tt.user.Email, Password: "correct horse"})
+
+			assert.Equal(t, http.StatusForbidden, status)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
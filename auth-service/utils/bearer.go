@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// bearerScheme is the authorization scheme used for access tokens
+const bearerScheme = "Bearer"
+
+// ExtractBearerToken returns the token of an "Authorization: Bearer <token>" header
+func ExtractBearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, bearerScheme) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// ExtractClientCredentials returns the client id and secret of an HTTP Basic Authorization header.
+// Both are form-urlencoded before being put in the header (RFC 6749 section 2.3.1).
+func ExtractClientCredentials(r *http.Request) (string, string, bool) {
+	rawID, rawSecret, ok := r.BasicAuth()
+	if !ok {
+		return "", "", false
+	}
+	clientID, err := url.QueryUnescape(rawID)
+	if err != nil {
+		return "", "", false
+	}
+	secret, err := url.QueryUnescape(rawSecret)
+	if err != nil {
+		return "", "", false
+	}
+	return clientID, secret, clientID != ""
+}
+
+// ParseClientCredentials is ExtractClientCredentials for an Authorization header value that did
+// not come with an HTTP request, such as gRPC metadata
+func ParseClientCredentials(authorization string) (string, string, bool) {
+	r := http.Request{Header: http.Header{"Authorization": {authorization}}}
+	return ExtractClientCredentials(&r)
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"context"
+
+	"auth-service/constants"
+)
+
+// ContextWithClaims returns a copy of ctx carrying the authenticated token claims
+func ContextWithClaims(ctx context.Context, claims *TokenClaims) context.Context {
+	return context.WithValue(ctx, constants.ClaimsKey, claims)
+}
+
+// ClaimsFromContext returns the token claims stored by the authentication middleware
+func ClaimsFromContext(ctx context.Context) (*TokenClaims, bool) {
+	claims, ok := ctx.Value(constants.ClaimsKey).(*TokenClaims)
+	return claims, ok && claims != nil
+}
+
+// ContextWithClientIP returns a copy of ctx carrying the IP address of the caller
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, constants.ClientIPKey, ip)
+}
+
+// ClientIPFromContext returns the IP address stored by the client IP middleware, or an empty string
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(constants.ClientIPKey).(string)
+	return ip
+}
+
+// ContextWithUserAgent returns a copy of ctx carrying the user agent of the caller
+func ContextWithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, constants.UserAgentKey, userAgent)
+}
+
+// UserAgentFromContext returns the user agent stored by the user agent middleware, or an empty string
+func UserAgentFromContext(ctx context.Context) string {
+	userAgent, _ := ctx.Value(constants.UserAgentKey).(string)
+	return userAgent
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the ID of the request
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, constants.RequestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by the request ID middleware, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(constants.RequestIDKey).(string)
+	return requestID
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+
+	"auth-service/constants"
+	"auth-service/models"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// rsaKeyBits is the size of generated RSA signing keys
+const rsaKeyBits = 2048
+
+// SigningKey is a private key used to sign access tokens, identified in the token header by its kid
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// KeyResolver returns the public key and algorithm registered for a kid
+type KeyResolver interface {
+	PublicKey(kid string) (crypto.PublicKey, string, bool)
+}
+
+// GenerateSigningKey creates a new private key for the given algorithm
+func GenerateSigningKey(kid, algorithm string, createdAt time.Time) (SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case constants.SigningAlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case constants.SigningAlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return SigningKey{}, errors.New(constants.ErrUnsupportedSigningAlg)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return SigningKey{ID: kid, Algorithm: algorithm, Private: private, CreatedAt: createdAt}, nil
+}
+
+// ParseSigningKeyPEM decodes a PKCS#8 (or PKCS#1 RSA) private key and derives its algorithm from the key type
+func ParseSigningKeyPEM(kid string, data []byte, createdAt time.Time) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, errors.New(constants.ErrInvalidKeyPEM)
+	}
+
+	var parsed interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return SigningKey{ID: kid, Algorithm: constants.SigningAlgRS256, Private: key, CreatedAt: createdAt}, nil
+	case ed25519.PrivateKey:
+		return SigningKey{ID: kid, Algorithm: constants.SigningAlgEdDSA, Private: key, CreatedAt: createdAt}, nil
+	default:
+		return SigningKey{}, errors.New(constants.ErrUnsupportedKeyType)
+	}
+}
+
+// EncodePEM encodes the private key as a PKCS#8 PEM block
+func (k SigningKey) EncodePEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicKey returns the public half of the key
+func (k SigningKey) PublicKey() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// JWK returns the public key in JSON Web Key form
+func (k SigningKey) JWK() models.JWK {
+	jwk := models.JWK{KeyID: k.ID, Algorithm: k.Algorithm, Use: "sig"}
+
+	switch pub := k.PublicKey().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// signingMethod returns the jwt signing method matching an algorithm name
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case constants.SigningAlgRS256:
+		return jwt.SigningMethodRS256, nil
+	case constants.SigningAlgEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, errors.New(constants.ErrUnsupportedSigningAlg)
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// opaqueTokenBytes is the amount of entropy in opaque tokens such as refresh tokens
+const opaqueTokenBytes = 32
+
+var ErrGeneratingToken = errors.New("generating random token failed")
+
+// GenerateOpaqueToken returns a URL-safe random token that carries no claims
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", ErrGeneratingToken
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateRandomID returns a random hex identifier, e.g. for refresh-token families
+func GenerateRandomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", ErrGeneratingToken
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the hex encoded SHA-256 of an opaque token; only hashes are stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
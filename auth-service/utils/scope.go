@@ -0,0 +1,29 @@
+package utils
+
+import "strings"
+
+// ParseScope splits a space separated scope string (RFC 6749 section 3.3)
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// HasScope reports whether the space separated scope string contains want
+func HasScope(scope, want string) bool {
+	for _, s := range ParseScope(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveScope returns the scope string without drop
+func RemoveScope(scope, drop string) string {
+	var kept []string
+	for _, s := range ParseScope(scope) {
+		if s != drop {
+			kept = append(kept, s)
+		}
+	}
+	return strings.Join(kept, " ")
+}
@@ -2,15 +2,45 @@ package utils
 
 import (
 	"errors"
+	"time"
 
 	"auth-service/constants"
+	"auth-service/models"
 
 	"github.com/golang-jwt/jwt"
 )
 
+// TokenClaims are the claims carried by access tokens. The jti claim is StandardClaims.Id and
+// sub (StandardClaims.Subject) is the user id as a string; user_id repeats it as a number.
+// Tokens of the client_credentials grant have no user: sub and client_id are the client id.
+// Roles and Permissions are those of the user when the token was issued. SessionID (sid) names the
+// session of user tokens, so that revoking the session revokes them too. Actor (act) is set on
+// tokens of an admin impersonating the user in sub. IssuedAtMicros (iat_us) repeats iat in
+// microseconds, so that revoking every token of a user tells apart the tokens issued in the same
+// second right before and right after.
 type TokenClaims struct {
 	jwt.StandardClaims
-	Email string `json:"email"`
+	IssuedAtMicros int64         `json:"iat_us,omitempty"`
+	UserID         int64         `json:"user_id,omitempty"`
+	Email          string        `json:"email,omitempty"`
+	ClientID       string        `json:"client_id,omitempty"`
+	Scope          string        `json:"scope,omitempty"`
+	Roles          []string      `json:"roles,omitempty"`
+	Permissions    []string      `json:"permissions,omitempty"`
+	SessionID      string        `json:"sid,omitempty"`
+	Actor          *models.Actor `json:"act,omitempty"`
+}
+
+// Impersonated reports whether the token was issued to an admin acting as its subject
+func (c TokenClaims) Impersonated() bool {
+	return c.Actor != nil
+}
+
+// IDTokenClaims are the claims of an OpenID Connect ID token. The audience is the client.
+type IDTokenClaims struct {
+	jwt.StandardClaims
+	Email string `json:"email,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
 }
 
 func NewTokenClaims(email string, issuedAt int64) TokenClaims {
@@ -22,6 +52,104 @@ func NewTokenClaims(email string, issuedAt int64) TokenClaims {
 	}
 }
 
+// RevocationChecker reports whether an otherwise valid token has been revoked
+type RevocationChecker interface {
+	IsRevoked(jti, sessionID string, userID int64, issuedAt time.Time) bool
+}
+
+// ValidateOption adds checks on top of the signature and time based validation
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	revocations RevocationChecker
+	keys        KeyResolver
+	issuer      string
+	audience    string
+	tokenType   string
+}
+
+// verifiedClaims are the claims the optional checks look at
+type verifiedClaims struct {
+	jti       string
+	sessionID string
+	userID    int64
+	issuedAt  time.Time
+	issuer    string
+	audience  func(aud string) bool
+	// legacy is set for HS256 tokens, which were issued before tokens carried an iss claim
+	legacy bool
+}
+
+// WithRevocationChecker rejects tokens reported as revoked by rc
+func WithRevocationChecker(rc RevocationChecker) ValidateOption {
+	return func(o *validateOptions) {
+		o.revocations = rc
+	}
+}
+
+// WithKeyResolver accepts RS256 and EdDSA tokens, verified with the public key named by their kid header
+func WithKeyResolver(kr KeyResolver) ValidateOption {
+	return func(o *validateOptions) {
+		o.keys = kr
+	}
+}
+
+// WithIssuer rejects tokens whose iss claim is not issuer
+func WithIssuer(issuer string) ValidateOption {
+	return func(o *validateOptions) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience rejects tokens whose aud claim does not contain audience
+func WithAudience(audience string) ValidateOption {
+	return func(o *validateOptions) {
+		o.audience = audience
+	}
+}
+
+// WithTokenType rejects asymmetric tokens whose typ header is not tokenType. HS256 tokens
+// predate the header and are exempt; no other kind of token was ever signed with SECRET_KEY.
+func WithTokenType(tokenType string) ValidateOption {
+	return func(o *validateOptions) {
+		o.tokenType = tokenType
+	}
+}
+
+// GenerateTokenWithSigningKey signs the claims with an asymmetric key, names the key in the kid
+// header and types the token as an access token
+func GenerateTokenWithSigningKey(claims TokenClaims, key SigningKey, expiresAt int64) (string, error) {
+	claims.ExpiresAt = expiresAt
+	return signWithKey(claims, key, constants.AccessTokenJWTType)
+}
+
+// GenerateIDToken signs OpenID Connect ID token claims with an asymmetric key
+func GenerateIDToken(claims IDTokenClaims, key SigningKey, expiresAt int64) (string, error) {
+	claims.ExpiresAt = expiresAt
+	return signWithKey(claims, key, "")
+}
+
+// signWithKey signs claims with key, names the key in the kid header and sets the typ header
+// to tokenType unless it is empty
+func signWithKey(claims jwt.Claims, key SigningKey, tokenType string) (string, error) {
+	method, err := signingMethod(key.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header[constants.JWTHeaderKeyID] = key.ID
+	if tokenType != "" {
+		token.Header[constants.JWTHeaderType] = tokenType
+	}
+
+	tokenString, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", ErrSigningToken
+	}
+	return tokenString, nil
+}
+
 // GenerateTokenWithCustomClaims  generates a token with custom claims
 func GenerateTokenWithCustomClaims(claims TokenClaims, secret string, expiresAt int64) (string, error) {
 	// set  expiration time
@@ -39,22 +167,96 @@ func GenerateTokenWithCustomClaims(claims TokenClaims, secret string, expiresAt
 
 }
 
-// ValidateToken checks the validity of the JWT token and returns the claims if valid
-func ValidateToken(tokenString, secret string) (jwt.MapClaims, error) {
+// ValidateToken checks the validity of the JWT token and returns the claims if valid.
+// HS256 tokens are verified with secret, which may be empty to reject them; RS256 and EdDSA
+// tokens are only accepted when a KeyResolver is passed with WithKeyResolver.
+func ValidateToken(tokenString, secret string, opts ...ValidateOption) (jwt.MapClaims, error) {
+	o := newValidateOptions(opts)
+	claims := jwt.MapClaims{}
+	token, err := parseToken(tokenString, secret, o, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	jti, _ := claims["jti"].(string)
+	sessionID, _ := claims["sid"].(string)
+	userID, _ := claims["user_id"].(float64)
+	issuedAt, _ := claims["iat"].(float64)
+	issuedAtMicros, _ := claims["iat_us"].(float64)
+	issuer, _ := claims["iss"].(string)
+	verified := verifiedClaims{
+		jti:       jti,
+		sessionID: sessionID,
+		userID:    int64(userID),
+		issuedAt:  issueTime(int64(issuedAt), int64(issuedAtMicros)),
+		issuer:    issuer,
+		audience:  func(aud string) bool { return claims.VerifyAudience(aud, true) },
+		legacy:    isLegacyToken(token),
+	}
+	if err := o.check(verified); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// ValidateTokenClaims is ValidateToken for callers that want the typed TokenClaims
+func ValidateTokenClaims(tokenString, secret string, opts ...ValidateOption) (*TokenClaims, error) {
+	o := newValidateOptions(opts)
+	claims := &TokenClaims{}
+	token, err := parseToken(tokenString, secret, o, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := verifiedClaims{
+		jti:       claims.Id,
+		sessionID: claims.SessionID,
+		userID:    claims.UserID,
+		issuedAt:  issueTime(claims.IssuedAt, claims.IssuedAtMicros),
+		issuer:    claims.Issuer,
+		audience:  func(aud string) bool { return claims.VerifyAudience(aud, true) },
+		legacy:    isLegacyToken(token),
+	}
+	if err := o.check(verified); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// parseToken verifies the signature, time based claims and type of the token and decodes it into claims
+func parseToken(tokenString, secret string, o validateOptions, claims jwt.Claims) (*jwt.Token, error) {
 	// define key function
 	validateSigningMethod := func(token *jwt.Token) (interface{}, error) {
-		//  ensuring that the signing method is the  one we expect (e.g.,  HS256)
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok {
-			return nil, jwt.NewValidationError(constants.TokenUnExpectedSigningMethod, jwt.ValidationErrorUnverifiable)
+		//  ensuring that the signing method is one we expect and picking the matching key
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if secret == "" {
+				break
+			}
+			return []byte(secret), nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+			if o.keys == nil {
+				break
+			}
+			if tokenType, _ := token.Header[constants.JWTHeaderType].(string); o.tokenType != "" && tokenType != o.tokenType {
+				return nil, jwt.NewValidationError(constants.TokenInvalidType, jwt.ValidationErrorClaimsInvalid)
+			}
+			kid, _ := token.Header[constants.JWTHeaderKeyID].(string)
+			publicKey, algorithm, ok := o.keys.PublicKey(kid)
+			if !ok {
+				return nil, jwt.NewValidationError(constants.TokenUnknownKey, jwt.ValidationErrorUnverifiable)
+			}
+			// a kid must never be usable with an algorithm other than the one it was issued for
+			if algorithm != token.Method.Alg() {
+				break
+			}
+			return publicKey, nil
 		}
-		//
-		return []byte(secret), nil
+		return nil, jwt.NewValidationError(constants.TokenUnExpectedSigningMethod, jwt.ValidationErrorUnverifiable)
 	}
 
 	//  parse the token using the defined keyF function validateSigningMethod
-	// TODO: check the ParseWithClaims  function
-	token, err := jwt.Parse(tokenString, validateSigningMethod)
+	token, err := jwt.ParseWithClaims(tokenString, claims, validateSigningMethod)
 	if err != nil {
 
 		// check for specific validation errors
@@ -73,10 +275,46 @@ func ValidateToken(tokenString, secret string) (jwt.MapClaims, error) {
 		return nil, err // return original error if it's not a validation
 	}
 
-	// check if the token is valid and extract claims
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	if !token.Valid {
+		return nil, jwt.NewValidationError(constants.TokenInvalid, jwt.ValidationErrorMalformed)
+	}
+	return token, nil
+}
+
+// issueTime returns when a token was issued, to the microsecond if it carries iat_us
+func issueTime(issuedAt, issuedAtMicros int64) time.Time {
+	if issuedAtMicros != 0 {
+		return time.UnixMicro(issuedAtMicros)
+	}
+	return time.Unix(issuedAt, 0)
+}
+
+// isLegacyToken reports whether the token was signed with SECRET_KEY
+func isLegacyToken(token *jwt.Token) bool {
+	_, ok := token.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// newValidateOptions applies opts on top of the defaults
+func newValidateOptions(opts []ValidateOption) validateOptions {
+	o := validateOptions{}
+	for _, opt := range opts {
+		opt(&o)
 	}
+	return o
+}
 
-	return nil, jwt.NewValidationError(constants.TokenInvalid, jwt.ValidationErrorMalformed)
+// check runs the optional checks against an already verified token
+func (o validateOptions) check(c verifiedClaims) error {
+	// legacy tokens without an iss claim are accepted until SECRET_KEY is unset
+	if o.issuer != "" && c.issuer != o.issuer && !(c.legacy && c.issuer == "") {
+		return errors.New(constants.TokenInvalidIssuer)
+	}
+	if o.audience != "" && !c.audience(o.audience) {
+		return errors.New(constants.TokenInvalidAudience)
+	}
+	if o.revocations != nil && o.revocations.IsRevoked(c.jti, c.sessionID, c.userID, c.issuedAt) {
+		return errors.New(constants.TokenRevoked)
+	}
+	return nil
 }
@@ -180,3 +180,91 @@ func BenchmarkValidateToken(b *testing.B) {
 		}
 	}
 }
+
+// revokedJTIs is a RevocationChecker stub that revokes the listed token ids
+type revokedJTIs map[string]bool
+
+func (r revokedJTIs) IsRevoked(jti, _ string, _ int64, _ time.Time) bool {
+	return r[jti]
+}
+
+// TestValidateToken_Revoked tests that revoked tokens are rejected when a checker is supplied
+func TestValidateToken_Revoked(t *testing.T) {
+	claims := utils.NewTokenClaims(h.generateRandomEmail(10), time.Now().UTC().Unix())
+	claims.Id = "revoked-jti"
+	token, _ := utils.GenerateTokenWithCustomClaims(claims, h.defaultSecret, time.Now().UTC().Add(time.Hour).Unix())
+
+	if _, err := utils.ValidateToken(token, h.defaultSecret); err != nil {
+		t.Fatalf("Expected token to be valid without a revocation checker, got %v", err)
+	}
+
+	_, err := utils.ValidateToken(token, h.defaultSecret, utils.WithRevocationChecker(revokedJTIs{"revoked-jti": true}))
+	if err == nil || err.Error() != constants.TokenRevoked {
+		t.Fatalf("Expected token revoked error, got %v", err)
+	}
+
+	if _, err := utils.ValidateTokenClaims(token, h.defaultSecret, utils.WithRevocationChecker(revokedJTIs{})); err != nil {
+		t.Fatalf("Expected token not in the revocation list to be valid, got %v", err)
+	}
+}
+
+// TestValidateToken_EmptySecret tests that HS256 tokens are refused when no secret is configured
+func TestValidateToken_EmptySecret(t *testing.T) {
+	h := newTestHelpers()
+	claims := utils.NewTokenClaims(h.generateRandomEmail(10), time.Now().UTC().Unix())
+	token, _ := utils.GenerateTokenWithCustomClaims(claims, h.defaultSecret, time.Now().UTC().Add(time.Hour).Unix())
+
+	_, err := utils.ValidateToken(token, "")
+	if err == nil || err.Error() != constants.TokenUnExpectedSigningMethod {
+		t.Errorf("expected %q, got %v", constants.TokenUnExpectedSigningMethod, err)
+	}
+}
+
+// TestValidateToken_IssuerAndAudience tests the iss and aud checks
+func TestValidateToken_IssuerAndAudience(t *testing.T) {
+	h := newTestHelpers()
+	claims := utils.NewTokenClaims(h.generateRandomEmail(10), time.Now().UTC().Unix())
+	claims.Issuer = "https://auth.example.com"
+	claims.Audience = "blog-service"
+	token, _ := utils.GenerateTokenWithCustomClaims(claims, h.defaultSecret, time.Now().UTC().Add(time.Hour).Unix())
+
+	tests := []struct {
+		name    string
+		opts    []utils.ValidateOption
+		wantErr string
+	}{
+		{name: "matching issuer and audience", opts: []utils.ValidateOption{utils.WithIssuer("https://auth.example.com"), utils.WithAudience("blog-service")}},
+		{name: "foreign issuer", opts: []utils.ValidateOption{utils.WithIssuer("https://evil.example.com")}, wantErr: constants.TokenInvalidIssuer},
+		{name: "foreign audience", opts: []utils.ValidateOption{utils.WithAudience("billing-service")}, wantErr: constants.TokenInvalidAudience},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := utils.ValidateTokenClaims(token, h.defaultSecret, tt.opts...)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ValidateTokenClaims returned error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("expected %q, got %v", tt.wantErr, err)
+			}
+
+			_, err = utils.ValidateToken(token, h.defaultSecret, tt.opts...)
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("ValidateToken: expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestValidateToken_LegacyIssuer tests that HS256 tokens issued before the iss claim pass the issuer check
+func TestValidateToken_LegacyIssuer(t *testing.T) {
+	h := newTestHelpers()
+	claims := utils.NewTokenClaims(h.generateRandomEmail(10), time.Now().UTC().Unix())
+	token, _ := utils.GenerateTokenWithCustomClaims(claims, h.defaultSecret, time.Now().UTC().Add(time.Hour).Unix())
+
+	if _, err := utils.ValidateTokenClaims(token, h.defaultSecret, utils.WithIssuer("https://auth.example.com")); err != nil {
+		t.Errorf("ValidateTokenClaims returned error: %v", err)
+	}
+	if _, err := utils.ValidateToken(token, h.defaultSecret, utils.WithIssuer("https://auth.example.com")); err != nil {
+		t.Errorf("ValidateToken returned error: %v", err)
+	}
+}
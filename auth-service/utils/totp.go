@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"auth-service/constants"
+)
+
+// totpSecretBytes is the size of TOTP secrets, the 160 bits RFC 4226 recommends for HMAC-SHA1
+const totpSecretBytes = 20
+
+// totpEncoding encodes TOTP secrets the way authenticator apps expect them: base32 without padding
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random base32 encoded TOTP secret
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", ErrGeneratingToken
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPStep returns the RFC 6238 time step t falls into
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(constants.TOTPPeriod/time.Second)
+}
+
+// TOTPCode returns the code of a base32 encoded secret for the given time step
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return "", err
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation as described in RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for range constants.TOTPDigits {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", constants.TOTPDigits, value%modulo), nil
+}
+
+// ValidateTOTP checks a code against the steps around now, allowing constants.TOTPSkew steps of
+// clock drift in either direction. It returns the matched step so callers can refuse to accept
+// the same step twice.
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != constants.TOTPDigits {
+		return 0, false
+	}
+
+	current := TOTPStep(now)
+	for step := current - constants.TOTPSkew; step <= current+constants.TOTPSkew; step++ {
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPURI returns the otpauth:// URI authenticator apps import secrets from
+func TOTPURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(constants.TOTPDigits))
+	query.Set("period", fmt.Sprint(int(constants.TOTPPeriod/time.Second)))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,77 @@
+package utils_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"auth-service/utils"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 appendix B test vectors, base32 encoded
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// TestTOTPCode checks the codes against the RFC 6238 test vectors, truncated to six digits
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		got, err := utils.TOTPCode(rfc6238Secret, utils.TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode returned error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+// TestValidateTOTP checks that codes of neighbouring steps are accepted and others are not
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := utils.TOTPStep(now)
+
+	for _, step := range []int64{current - 1, current, current + 1} {
+		code, _ := utils.TOTPCode(rfc6238Secret, step)
+		got, ok := utils.ValidateTOTP(rfc6238Secret, code, now)
+		if !ok || got != step {
+			t.Errorf("ValidateTOTP of step %d = %d, %v", step, got, ok)
+		}
+	}
+
+	stale, _ := utils.TOTPCode(rfc6238Secret, current-2)
+	if _, ok := utils.ValidateTOTP(rfc6238Secret, stale, now); ok {
+		t.Error("expected a code two steps old to be rejected")
+	}
+	if _, ok := utils.ValidateTOTP(rfc6238Secret, "12345", now); ok {
+		t.Error("expected a short code to be rejected")
+	}
+}
+
+// TestTOTPURI checks the otpauth URI carries the secret, issuer and label
+func TestTOTPURI(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret returned error: %v", err)
+	}
+
+	parsed, err := url.Parse(utils.TOTPURI("blog-services", "asif@example.com", secret))
+	if err != nil {
+		t.Fatalf("invalid otpauth URI: %v", err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/blog-services:asif@example.com" {
+		t.Errorf("unexpected otpauth URI %s", parsed)
+	}
+	if parsed.Query().Get("secret") != secret || parsed.Query().Get("issuer") != "blog-services" {
+		t.Errorf("unexpected otpauth query %s", parsed.RawQuery)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"blog-service/controllers"
 	"blog-service/db"
 	"blog-service/logger"
+	"blog-service/middleware"
 	"blog-service/repositories"
 	"blog-service/router"
 	"blog-service/services"
+	"blog-service/utils"
 	"context"
 	"fmt"
 	"log"
@@ -47,8 +49,39 @@ func main() {
 	blogService := services.NewBlogService(blogRepo, appLogger)
 	blogController := controllers.NewBlogController(blogService, appLogger)
 
+	// Protected routes verify tokens with the public keys published by auth-service
+	jwksURL, issuer := appConfig.GetJWKSURL(), appConfig.GetIssuer()
+	if jwksURL == "" || issuer == "" {
+		appLogger.Fatal("AUTH_JWKS_URL and AUTH_ISSUER must be set to authenticate requests")
+	}
+	keySet := utils.NewRemoteKeySet(jwksURL)
+	if err := keySet.Fetch(); err != nil {
+		// keys are fetched again on the first request, so auth-service may start later
+		appLogger.Warnf("Unable to fetch JWKS from %s: %v", jwksURL, err)
+	}
+
+	// Personal access tokens are opaque and checked at the introspection endpoint of auth-service,
+	// which also tells whether access tokens were revoked
+	var introspector utils.TokenIntrospector
+	introspectionURL, clientID := appConfig.GetIntrospectionURL(), appConfig.GetClientID()
+	if introspectionURL != "" && clientID != "" {
+		introspector = utils.NewRemoteIntrospector(introspectionURL, clientID, appConfig.GetClientSecret(), issuer)
+	} else {
+		appLogger.Warnf("AUTH_INTROSPECTION_URL or AUTH_CLIENT_ID not set, personal access tokens are rejected and revoked access tokens accepted until they expire")
+	}
+	authMiddleware := middleware.AuthMiddleware(keySet, introspector, issuer, appConfig.GetAudience())
+
+	// Blogs of users deleted in auth-service are removed once its outbox reports the deletion
+	if eventsURL := appConfig.GetEventsURL(); eventsURL != "" && clientID != "" {
+		eventSource := utils.NewRemoteEventSource(eventsURL, clientID, appConfig.GetClientSecret())
+		eventRepo := repositories.NewEventRepository(dbConn, appLogger)
+		go services.NewUserEventConsumer(eventSource, eventRepo, appLogger).Run(ctx)
+	} else {
+		appLogger.Warnf("AUTH_EVENTS_URL or AUTH_CLIENT_ID not set, blogs of deleted users are kept")
+	}
+
 	// Initialize router
-	r := router.Init(blogController)
+	r := router.Init(blogController, authMiddleware)
 	appLogger.Infof("Starting server on port :%s", appConfig.GetPort())
 
 	// Start server
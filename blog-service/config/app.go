@@ -1,6 +1,8 @@
 package config
 
 import (
+	"strings"
+
 	"blog-service/constants"
 
 	"github.com/spf13/viper"
@@ -10,6 +12,13 @@ type AppConfig interface {
 	GetBuildEnv() string
 	GetSecretKey() string
 	GetPort() string
+	GetJWKSURL() string
+	GetIssuer() string
+	GetAudience() string
+	GetIntrospectionURL() string
+	GetClientID() string
+	GetClientSecret() string
+	GetEventsURL() string
 }
 
 // appConfig for app
@@ -33,6 +42,51 @@ func (ac *appConfig) GetPort() string {
 	return ac.env.GetString(constants.AppPort)
 }
 
+// GetJWKSURL returns the URL of the auth-service JWKS endpoint
+func (ac *appConfig) GetJWKSURL() string {
+	ac.env.AutomaticEnv()
+	return ac.env.GetString(constants.AuthJWKSURL)
+}
+
+// GetIssuer returns the expected iss claim of access tokens
+func (ac *appConfig) GetIssuer() string {
+	ac.env.AutomaticEnv()
+	return strings.TrimSuffix(ac.env.GetString(constants.AuthIssuer), "/")
+}
+
+// GetAudience returns the aud claim access tokens must contain
+func (ac *appConfig) GetAudience() string {
+	ac.env.AutomaticEnv()
+	if audience := ac.env.GetString(constants.TokenAudience); audience != "" {
+		return audience
+	}
+	return constants.DefaultTokenAudience
+}
+
+// GetIntrospectionURL returns the URL of the auth-service token introspection endpoint
+func (ac *appConfig) GetIntrospectionURL() string {
+	ac.env.AutomaticEnv()
+	return ac.env.GetString(constants.AuthIntrospectionURL)
+}
+
+// GetClientID returns the client id blog-service authenticates to auth-service with
+func (ac *appConfig) GetClientID() string {
+	ac.env.AutomaticEnv()
+	return ac.env.GetString(constants.AuthClientID)
+}
+
+// GetClientSecret returns the client secret blog-service authenticates to auth-service with
+func (ac *appConfig) GetClientSecret() string {
+	ac.env.AutomaticEnv()
+	return ac.env.GetString(constants.AuthClientSecret)
+}
+
+// GetEventsURL returns the URL of the auth-service outbox endpoint
+func (ac *appConfig) GetEventsURL() string {
+	ac.env.AutomaticEnv()
+	return ac.env.GetString(constants.AuthEventsURL)
+}
+
 func NewAppConfig(env *viper.Viper) AppConfig {
 	return &appConfig{env: env}
 }
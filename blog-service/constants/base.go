@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 // Context Keys
 type contextKey string
 
@@ -9,6 +11,9 @@ const RequestIDKey contextKey = "request_id"
 // SourceServiceKey is the key used to store the source service in the context.
 const SourceServiceKey contextKey = "source_service"
 
+// PrincipalKey is the key used to store the authenticated principal in the context.
+const PrincipalKey contextKey = "principal"
+
 // API Endpoints
 const (
 	// ApiV1 represents the base path for version 1 of the API.
@@ -17,6 +22,8 @@ const (
 	BlogsPath = "/blogs"
 	// BlogDetailPath is the path for accessing a specific blog by its ID.
 	BlogDetailPath = "/blogs/{id}"
+	// MyBlogsPath is the path for all blogs of the authenticated user.
+	MyBlogsPath = "/me/blogs"
 )
 
 // Pagination Defaults
@@ -64,6 +71,21 @@ const (
 	TokenNotValidYet             = "token is not valid yet"
 	TokenMalformed               = "token is malformed"
 	TokenInvalidIssuer           = "invalid issuer"
+	TokenInvalidAudience         = "invalid audience"
+	TokenMissing                 = "missing or malformed authorization header"
+	TokenUnknownKey              = "token signed with an unknown key"
+	TokenInactive                = "token is inactive"
+	// ErrPersonalAccessTokensDisabled is returned for personal access tokens when no introspection client is configured.
+	ErrPersonalAccessTokensDisabled = "personal access tokens are not accepted"
+
+	// ErrInsufficientScope is returned when a client token lacks the scope a route requires.
+	ErrInsufficientScope = "insufficient scope"
+	// ErrUserTokenRequired is returned when a client token is used where a user must act.
+	ErrUserTokenRequired = "this endpoint requires a user access token"
+	// ErrPermissionDenied is returned when the caller's roles lack the permission a route requires.
+	ErrPermissionDenied = "permission denied"
+	// ErrImpersonationNotAllowed is returned when an admin impersonating a user calls a destructive route.
+	ErrImpersonationNotAllowed = "this action cannot be taken while impersonating a user"
 
 	// ErrBlogNotFound Blog related errors.
 	ErrBlogNotFound = "blog not found"
@@ -76,6 +98,24 @@ const (
 	BuildEnv  = "BUILD_ENV"
 	AppPort   = "APP_PORT"
 
+	// AuthJWKSURL is where auth-service publishes the public keys that verify its access tokens.
+	AuthJWKSURL = "AUTH_JWKS_URL"
+	// AuthIssuer is the iss claim auth-service puts into its tokens.
+	AuthIssuer = "AUTH_ISSUER"
+	// TokenAudience is the aud claim a token must carry to be accepted by blog-service.
+	TokenAudience = "TOKEN_AUDIENCE"
+	// DefaultTokenAudience is used when TokenAudience is not set.
+	DefaultTokenAudience = "blog-service"
+	// AuthIntrospectionURL is the auth-service token introspection endpoint personal access tokens are checked at.
+	AuthIntrospectionURL = "AUTH_INTROSPECTION_URL"
+	// AuthClientID and AuthClientSecret are the credentials of the confidential client blog-service
+	// introspects tokens as; it must be allowed the tokens:introspect scope.
+	AuthClientID     = "AUTH_CLIENT_ID"
+	AuthClientSecret = "AUTH_CLIENT_SECRET"
+	// AuthEventsURL is the auth-service outbox endpoint blog-service learns about deleted users from;
+	// the AuthClientID client must be allowed the events:read scope.
+	AuthEventsURL = "AUTH_EVENTS_URL"
+
 	PostgresHost       = "POSTGRES_HOST"
 	PostgresPort       = "POSTGRES_PORT"
 	PostgresUser       = "POSTGRES_USER"
@@ -85,8 +125,63 @@ const (
 	DatabaseDefaultSSL = "disable"
 )
 
+// JWKS caching
+const (
+	// JWKSRefreshInterval is how long fetched keys are used before the JWKS is fetched again.
+	JWKSRefreshInterval = 5 * time.Minute
+	// JWKSMinRefreshInterval limits refetches triggered by tokens with an unknown kid.
+	JWKSMinRefreshInterval = 30 * time.Second
+	// JWKSFetchTimeout bounds a single JWKS request.
+	JWKSFetchTimeout = 5 * time.Second
+)
+
+// Token introspection
+const (
+	// PersonalAccessTokenPrefix starts every personal access token issued by auth-service.
+	PersonalAccessTokenPrefix = "pat_"
+	// IntrospectionCacheTTL is how long an introspection result is reused; revoking a personal
+	// access token takes effect in blog-service within this time.
+	IntrospectionCacheTTL = 30 * time.Second
+	// IntrospectionCacheSize bounds the number of cached introspection results.
+	IntrospectionCacheSize = 1000
+	// IntrospectionTimeout bounds a single introspection request.
+	IntrospectionTimeout = 5 * time.Second
+)
+
+// Auth-service events
+const (
+	// EventUserDeleted is published by auth-service when a user is deleted; their blogs are removed.
+	EventUserDeleted = "user.deleted"
+	// EventConsumerAuthService names the cursor of the auth-service outbox in the event_cursors table.
+	EventConsumerAuthService = "auth-service"
+	// EventPollInterval is how often the auth-service outbox is polled.
+	EventPollInterval = 30 * time.Second
+	// EventBatchSize is the maximum number of events fetched per request.
+	EventBatchSize = 100
+	// EventFetchTimeout bounds a single request to the outbox.
+	EventFetchTimeout = 10 * time.Second
+)
+
+// API scopes auth-service grants to client tokens.
+const (
+	// ScopeBlogRead allows reading blog posts.
+	ScopeBlogRead = "blog:read"
+	// ScopeBlogWrite allows creating, updating and deleting blog posts.
+	ScopeBlogWrite = "blog:write"
+)
+
+// Permissions granted through auth-service roles and carried in the permissions claim.
+const (
+	// PermissionBlogDeleteAny allows deleting blog posts of any author, e.g. for moderators.
+	PermissionBlogDeleteAny = "blog:delete:any"
+)
+
 // Header related
 
 const (
-	HeaderRequestID = "X-Request-ID"
+	HeaderRequestID     = "X-Request-ID"
+	HeaderAuthorization = "Authorization"
+
+	// BearerScheme is the authorization scheme used for access tokens.
+	BearerScheme = "Bearer"
 )
@@ -3,11 +3,17 @@ package controllers
 import (
 	"blog-service/models/request"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"blog-service/constants"
 	"blog-service/logger"
+	"blog-service/middleware"
+	"blog-service/models"
+	"blog-service/models/resp"
 	"blog-service/services"
+	"blog-service/utils"
 )
 
 type BlogController interface {
@@ -16,6 +22,7 @@ type BlogController interface {
 	CreateBlog(w http.ResponseWriter, r *http.Request)
 	UpdateBlog(w http.ResponseWriter, r *http.Request)
 	DeleteBlog(w http.ResponseWriter, r *http.Request)
+	GetMyBlogs(w http.ResponseWriter, r *http.Request)
 }
 
 type blogController struct {
@@ -64,7 +71,7 @@ func (b blogController) GetBlogList(w http.ResponseWriter, r *http.Request) {
 	// Call the service to get the blog list
 	blogsResp, err := b.svc.GetAllBlogs(r.Context(), *pageReq)
 	if err != nil {
-		b.l.Error(ctx, "Error retrieving blog list: ", err)
+		b.l.Error(ctx, "Error retrieving blog list: %v", err)
 		http.Error(w, "Failed to get blog list", http.StatusInternalServerError)
 		return
 	}
@@ -89,31 +96,197 @@ func (b blogController) GetBlogList(w http.ResponseWriter, r *http.Request) {
 
 // GetBlogByID retrieves a single blog by its ID.
 func (b blogController) GetBlogByID(w http.ResponseWriter, r *http.Request) {
-	// Extract the blog ID from the URL path
-	path := r.URL.Path
-	b.l.Infof("Retrieving blog with ID: %s", path)
-	writeLen, err := w.Write([]byte(path))
+	ctx := r.Context()
+
+	blogID, err := blogIDFromPath(r)
+	if err != nil {
+		b.l.Warn(ctx, "Invalid blog ID provided: %s", r.PathValue("id"))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	b.l.Info(ctx, "Retrieving blog with ID: %d", blogID)
+	blog, err := b.svc.GetBlogById(ctx, blogID)
 	if err != nil {
-		b.l.Errorf("Error writing response: %s", err)
+		b.respondWithServiceError(w, r, err)
 		return
 	}
-	b.l.Infof("Successfully wrote %d bytes", writeLen)
 
+	utils.RespondWithJSON(w, http.StatusOK, blog.ToResponse(), "")
 }
 
+// CreateBlog handles HTTP POST requests to create a new blog.
 func (b blogController) CreateBlog(w http.ResponseWriter, r *http.Request) {
-	//TODO implement me
-	panic("implement me")
+	ctx := r.Context()
+
+	principal, ok := b.principal(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.BlogCreateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		b.l.Warn(ctx, "Failed to decode create blog request: %v", err)
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.AuthorID = principal.UserID
+
+	if err := req.Validate(); err != nil {
+		b.l.Warn(ctx, "Invalid create blog request: %v", err)
+		utils.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	blog := req.ToSchema()
+	if err := b.svc.CreateBlog(ctx, blog); err != nil {
+		b.respondWithServiceError(w, r, err)
+		return
+	}
+
+	b.l.Info(ctx, "Successfully created blog with ID: %d", blog.ID)
+	utils.RespondWithJSON(w, http.StatusCreated, blog.ToResponse(), "")
 }
 
+// UpdateBlog handles HTTP PUT requests to update an existing blog.
 func (b blogController) UpdateBlog(w http.ResponseWriter, r *http.Request) {
-	//TODO implement me
-	panic("implement me")
+	ctx := r.Context()
+
+	principal, ok := b.principal(w, r)
+	if !ok {
+		return
+	}
+
+	blogID, err := blogIDFromPath(r)
+	if err != nil {
+		b.l.Warn(ctx, "Invalid blog ID provided: %s", r.PathValue("id"))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req request.BlogUpdateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		b.l.Warn(ctx, "Failed to decode update blog request: %v", err)
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.AuthorID = principal.UserID
+
+	if err := req.Validate(); err != nil {
+		b.l.Warn(ctx, "Invalid update blog request: %v", err)
+		utils.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	blog := req.ToSchema(blogID)
+	if err := b.svc.UpdateBlog(ctx, blog); err != nil {
+		b.respondWithServiceError(w, r, err)
+		return
+	}
+
+	b.l.Info(ctx, "Successfully updated blog with ID: %d", blog.ID)
+	utils.RespondWithJSON(w, http.StatusOK, blog.ToResponse(), "")
 }
 
+// DeleteBlog handles HTTP DELETE requests to remove a blog.
 func (b blogController) DeleteBlog(w http.ResponseWriter, r *http.Request) {
-	//TODO implement me
-	panic("implement me")
+	ctx := r.Context()
+
+	principal, ok := b.principal(w, r)
+	if !ok {
+		return
+	}
+
+	blogID, err := blogIDFromPath(r)
+	if err != nil {
+		b.l.Warn(ctx, "Invalid blog ID provided: %s", r.PathValue("id"))
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	if err := b.svc.DeleteBlog(ctx, blogID, principal); err != nil {
+		b.respondWithServiceError(w, r, err)
+		return
+	}
+
+	b.l.Info(ctx, "Successfully deleted blog with ID: %d", blogID)
+	utils.RespondWithJSON(w, http.StatusOK, nil, "")
+}
+
+// GetMyBlogs returns every blog of the authenticated user with its content and timestamps.
+// auth-service calls it with the user's token to include their posts in a data export.
+func (b blogController) GetMyBlogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	principal, ok := b.principal(w, r)
+	if !ok {
+		return
+	}
+
+	blogs, err := b.svc.GetAllBlogsByAuthorID(ctx, int64(principal.UserID))
+	if err != nil {
+		b.respondWithServiceError(w, r, err)
+		return
+	}
+
+	blogResp := make([]*resp.BlogDetailResp, len(blogs))
+	for i := range blogs {
+		blogResp[i] = blogs[i].ToResponse()
+	}
+
+	b.l.Info(ctx, "Successfully retrieved %d blogs of user %d", len(blogs), principal.UserID)
+	utils.RespondWithJSON(w, http.StatusOK, blogResp, "")
+}
+
+// principal returns the authenticated user, responding with 401 when the request carries none.
+// Client tokens without a user are refused with 403 since posts always belong to a user.
+func (b blogController) principal(w http.ResponseWriter, r *http.Request) (*models.Principal, bool) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		b.l.Warn(r.Context(), "Missing authenticated principal for %s %s", r.Method, r.URL.Path)
+		utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+		return nil, false
+	}
+	if principal.UserID == 0 {
+		b.l.Warn(r.Context(), "Rejected client %s without a user for %s %s", principal.ClientID, r.Method, r.URL.Path)
+		utils.RespondWithError(w, http.StatusForbidden, constants.ErrUserTokenRequired)
+		return nil, false
+	}
+	return principal, true
+}
+
+// respondWithServiceError maps errors returned by the blog service to HTTP responses.
+func (b blogController) respondWithServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	ctx := r.Context()
+
+	switch {
+	case errors.Is(err, models.ErrBlogNotFound):
+		b.l.Warn(ctx, "Blog not found: %v", err)
+		utils.RespondWithError(w, http.StatusNotFound, models.ErrBlogNotFound.Error())
+	case errors.Is(err, models.ErrBlogForbidden):
+		b.l.Warn(ctx, "Forbidden blog operation: %v", err)
+		utils.RespondWithError(w, http.StatusForbidden, models.ErrBlogForbidden.Error())
+	case errors.Is(err, models.ErrInvalidTitle),
+		errors.Is(err, models.ErrInvalidContent),
+		errors.Is(err, models.ErrInvalidAuthorID):
+		b.l.Warn(ctx, "Blog validation failed: %v", err)
+		utils.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		b.l.Error(ctx, "Blog operation failed: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// blogIDFromPath extracts and validates the {id} path value.
+func blogIDFromPath(r *http.Request) (int64, error) {
+	blogID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if blogID < 1 {
+		return 0, models.ErrInvalidRequest
+	}
+	return blogID, nil
 }
 
 func NewBlogController(svc services.BlogService, l *logger.AppLogger) BlogController {
@@ -0,0 +1,6 @@
+-- id of the last auth-service outbox event each consumer handled
+CREATE TABLE IF NOT EXISTS event_cursors (
+    consumer VARCHAR(50) PRIMARY KEY,
+    last_event_id BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"blog-service/constants"
+	"blog-service/logger"
+	"blog-service/models"
+	"blog-service/utils"
+)
+
+// AuthMiddleware returns an HTTP middleware that validates the bearer token issued by auth-service
+// against its published keys, issuer and audience, and stores the authenticated principal in the
+// request context. Access tokens are then checked with introspector, so that tokens revoked in
+// auth-service or of disabled users stop working at once. Personal access tokens are not JWTs;
+// they are resolved by introspector, and rejected when it is nil.
+func AuthMiddleware(keys utils.KeyResolver, introspector utils.TokenIntrospector, issuer, audience string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			tokenString, ok := bearerToken(r)
+			if !ok {
+				logger.Log.Warn(ctx, "Rejected request without bearer token: %s %s", r.Method, r.URL.Path)
+				utils.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+				return
+			}
+
+			var claims *utils.TokenClaims
+			var err error
+			if strings.HasPrefix(tokenString, constants.PersonalAccessTokenPrefix) {
+				if introspector == nil {
+					utils.RespondWithError(w, http.StatusUnauthorized, constants.ErrPersonalAccessTokensDisabled)
+					return
+				}
+				claims, err = introspector.Introspect(ctx, tokenString)
+			} else {
+				claims, err = utils.ValidateToken(tokenString, keys, issuer, audience)
+				if err == nil && introspector != nil {
+					err = introspector.CheckActive(ctx, tokenString)
+				}
+			}
+			if err != nil {
+				logger.Log.Warn(ctx, "Rejected request with invalid token: %v", err)
+				utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			principal := &models.Principal{
+				UserID:      uint(claims.UserID),
+				Email:       claims.Email,
+				ClientID:    claims.ClientID,
+				Scopes:      strings.Fields(claims.Scope),
+				Roles:       claims.Roles,
+				Permissions: claims.Permissions,
+			}
+			if claims.Actor != nil {
+				principal.ActorID = uint(claims.Actor.UserID)
+				logger.Log.Info(ctx, "User %d is impersonating user %d: %s %s", principal.ActorID, principal.UserID, r.Method, r.URL.Path)
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, constants.PrincipalKey, principal)))
+		})
+	}
+}
+
+// PrincipalFromContext returns the authenticated principal stored by AuthMiddleware.
+func PrincipalFromContext(ctx context.Context) (*models.Principal, bool) {
+	principal, ok := ctx.Value(constants.PrincipalKey).(*models.Principal)
+	return principal, ok && principal != nil
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get(constants.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, constants.BearerScheme) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,280 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blog-service/constants"
+	"blog-service/middleware"
+	"blog-service/models"
+	"blog-service/utils"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "auth-service"
+	testAudience = "blog-service"
+	testKeyID    = "key-1"
+)
+
+// staticKeys resolves a single Ed25519 key
+type staticKeys struct {
+	key ed25519.PublicKey
+}
+
+// PublicKey returns the key for testKeyID
+func (k staticKeys) PublicKey(kid string) (crypto.PublicKey, string, bool) {
+	if kid != testKeyID {
+		return nil, "", false
+	}
+	return k.key, jwt.SigningMethodEdDSA.Alg(), true
+}
+
+// stubIntrospector resolves personal access tokens to claims and reports the tokens in inactive
+// as revoked
+type stubIntrospector struct {
+	claims   map[string]*utils.TokenClaims
+	inactive map[string]bool
+}
+
+// Introspect returns the claims of a known personal access token
+func (i stubIntrospector) Introspect(_ context.Context, token string) (*utils.TokenClaims, error) {
+	claims, ok := i.claims[token]
+	if !ok {
+		return nil, errors.New(constants.TokenInactive)
+	}
+	return claims, nil
+}
+
+// CheckActive fails for the tokens in inactive
+func (i stubIntrospector) CheckActive(_ context.Context, token string) error {
+	if i.inactive[token] {
+		return errors.New(constants.TokenInactive)
+	}
+	return nil
+}
+
+// signToken returns an access token of auth-service with the given claims
+func signToken(t *testing.T, key ed25519.PrivateKey, kid string, claims utils.TokenClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+// userClaims returns valid claims of user 7
+func userClaims() utils.TokenClaims {
+	return utils.TokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    testIssuer,
+			Audience:  testAudience,
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		UserID: 7,
+		Email:  "asif@example.com",
+	}
+}
+
+// serve runs handler for a request with the given bearer token and returns the response and
+// the principal the handler saw, if it was reached
+func serve(t *testing.T, handler func(http.Handler) http.Handler, token string) (*httptest.ResponseRecorder, *models.Principal) {
+	t.Helper()
+	var seen *models.Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = middleware.PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/blogs/1", nil)
+	if token != "" {
+		req.Header.Set(constants.HeaderAuthorization, constants.BearerScheme+" "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler(next).ServeHTTP(rec, req)
+	return rec, seen
+}
+
+// serveAs runs handler for a request authenticated as principal, as AuthMiddleware would have
+// stored it, and returns the response
+func serveAs(handler func(http.Handler) http.Handler, principal *models.Principal) *httptest.ResponseRecorder {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/blogs/1", nil)
+	if principal != nil {
+		req = req.WithContext(context.WithValue(req.Context(), constants.PrincipalKey, principal))
+	}
+	rec := httptest.NewRecorder()
+	handler(next).ServeHTTP(rec, req)
+	return rec
+}
+
+// errorOf returns the error message of a response
+func errorOf(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body utils.StandardResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	return body.Error
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys := staticKeys{key: public}
+
+	valid := signToken(t, private, testKeyID, userClaims())
+	revoked := signToken(t, private, testKeyID, func() utils.TokenClaims {
+		claims := userClaims()
+		claims.Id = "revoked"
+		return claims
+	}())
+	impersonated := signToken(t, private, testKeyID, func() utils.TokenClaims {
+		claims := userClaims()
+		claims.Actor = &utils.Actor{Subject: "1", UserID: 1}
+		return claims
+	}())
+	otherIssuer := signToken(t, private, testKeyID, func() utils.TokenClaims {
+		claims := userClaims()
+		claims.Issuer = "someone-else"
+		return claims
+	}())
+	otherAudience := signToken(t, private, testKeyID, func() utils.TokenClaims {
+		claims := userClaims()
+		claims.Audience = "another-service"
+		return claims
+	}())
+	expired := signToken(t, private, testKeyID, func() utils.TokenClaims {
+		claims := userClaims()
+		claims.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+		return claims
+	}())
+	forged := signToken(t, otherKey, testKeyID, userClaims())
+	unknownKey := signToken(t, private, "key-2", userClaims())
+	pat := constants.PersonalAccessTokenPrefix + "abc"
+
+	introspector := stubIntrospector{
+		claims: map[string]*utils.TokenClaims{
+			pat: {UserID: 9, Email: "pat@example.com", Scope: constants.ScopeBlogRead},
+		},
+		inactive: map[string]bool{revoked: true},
+	}
+
+	tests := []struct {
+		name          string
+		introspector  utils.TokenIntrospector
+		token         string
+		wantStatus    int
+		wantError     string
+		wantPrincipal *models.Principal
+	}{
+		{
+			name:       "missing token",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenMissing,
+		},
+		{
+			name:          "valid token without introspection",
+			token:         valid,
+			wantStatus:    http.StatusNoContent,
+			wantPrincipal: &models.Principal{UserID: 7, Email: "asif@example.com", Scopes: []string{}},
+		},
+		{
+			name:          "valid token still active at auth-service",
+			introspector:  introspector,
+			token:         valid,
+			wantStatus:    http.StatusNoContent,
+			wantPrincipal: &models.Principal{UserID: 7, Email: "asif@example.com", Scopes: []string{}},
+		},
+		{
+			name:         "valid token revoked at auth-service",
+			introspector: introspector,
+			token:        revoked,
+			wantStatus:   http.StatusUnauthorized,
+			wantError:    constants.TokenInactive,
+		},
+		{
+			name:          "impersonation token names the admin",
+			token:         impersonated,
+			wantStatus:    http.StatusNoContent,
+			wantPrincipal: &models.Principal{UserID: 7, Email: "asif@example.com", Scopes: []string{}, ActorID: 1},
+		},
+		{
+			name:       "other issuer",
+			token:      otherIssuer,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenInvalidIssuer,
+		},
+		{
+			name:       "other audience",
+			token:      otherAudience,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenInvalidAudience,
+		},
+		{
+			name:       "expired token",
+			token:      expired,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenExpired,
+		},
+		{
+			name:       "signed with another key",
+			token:      forged,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenInvalid,
+		},
+		{
+			name:       "signed with an unknown key",
+			token:      unknownKey,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenInvalid,
+		},
+		{
+			name:       "personal access token without introspection",
+			token:      pat,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.ErrPersonalAccessTokensDisabled,
+		},
+		{
+			name:          "personal access token resolved by introspection",
+			introspector:  introspector,
+			token:         pat,
+			wantStatus:    http.StatusNoContent,
+			wantPrincipal: &models.Principal{UserID: 9, Email: "pat@example.com", Scopes: []string{constants.ScopeBlogRead}},
+		},
+		{
+			name:         "unknown personal access token",
+			introspector: introspector,
+			token:        constants.PersonalAccessTokenPrefix + "unknown",
+			wantStatus:   http.StatusUnauthorized,
+			wantError:    constants.TokenInactive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, principal := serve(t, middleware.AuthMiddleware(keys, tt.introspector, testIssuer, testAudience), tt.token)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantError != "" {
+				assert.Equal(t, tt.wantError, errorOf(t, rec))
+			}
+			assert.Equal(t, tt.wantPrincipal, principal)
+		})
+	}
+}
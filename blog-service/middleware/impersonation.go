@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"blog-service/constants"
+	"blog-service/logger"
+	"blog-service/utils"
+)
+
+// RejectImpersonation is an HTTP middleware that refuses tokens of admins impersonating a user.
+// It must run after AuthMiddleware and guards destructive routes, which support staff reproducing
+// a bug must not take on the user's behalf.
+func RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		principal, ok := PrincipalFromContext(ctx)
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+			return
+		}
+		if principal.Impersonated() {
+			logger.Log.Warn(ctx, "Rejected user %d impersonating user %d: %s %s", principal.ActorID, principal.UserID, r.Method, r.URL.Path)
+			utils.RespondWithError(w, http.StatusForbidden, constants.ErrImpersonationNotAllowed)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
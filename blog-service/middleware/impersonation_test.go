@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+
+	"blog-service/constants"
+	"blog-service/middleware"
+	"blog-service/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectImpersonation(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *models.Principal
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "unauthenticated",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenMissing,
+		},
+		{
+			name:       "user",
+			principal:  &models.Principal{UserID: 7},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "admin impersonating the user",
+			principal:  &models.Principal{UserID: 7, ActorID: 1},
+			wantStatus: http.StatusForbidden,
+			wantError:  constants.ErrImpersonationNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAs(middleware.RejectImpersonation, tt.principal)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantError != "" {
+				assert.Equal(t, tt.wantError, errorOf(t, rec))
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"blog-service/constants"
+	"blog-service/logger"
+	"blog-service/utils"
+)
+
+// RequirePermission returns an HTTP middleware that rejects callers whose roles do not grant the
+// given permission, e.g. RequirePermission(constants.PermissionBlogDeleteAny) for moderation routes.
+// It must run after AuthMiddleware. Permissions come from the token, so a removed role keeps
+// working until the caller's access token expires.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			principal, ok := PrincipalFromContext(ctx)
+			if !ok {
+				utils.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+				return
+			}
+			if !principal.HasPermission(permission) {
+				logger.Log.Warn(ctx, "Rejected user %d without permission %s", principal.UserID, permission)
+				utils.RespondWithError(w, http.StatusForbidden, constants.ErrPermissionDenied)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
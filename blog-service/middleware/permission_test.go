@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+
+	"blog-service/constants"
+	"blog-service/middleware"
+	"blog-service/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *models.Principal
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "unauthenticated",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenMissing,
+		},
+		{
+			name:       "moderator",
+			principal:  &models.Principal{UserID: 7, Roles: []string{"moderator"}, Permissions: []string{constants.PermissionBlogDeleteAny}},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "user without the permission",
+			principal:  &models.Principal{UserID: 7, Roles: []string{"user"}},
+			wantStatus: http.StatusForbidden,
+			wantError:  constants.ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAs(middleware.RequirePermission(constants.PermissionBlogDeleteAny), tt.principal)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantError != "" {
+				assert.Equal(t, tt.wantError, errorOf(t, rec))
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"blog-service/constants"
+	"blog-service/logger"
+	"blog-service/utils"
+)
+
+// RequireScope returns an HTTP middleware that rejects scoped tokens without the given scope.
+// It must run after AuthMiddleware; unscoped first-party tokens pass.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			principal, ok := PrincipalFromContext(ctx)
+			if !ok {
+				utils.RespondWithError(w, http.StatusUnauthorized, constants.TokenMissing)
+				return
+			}
+			if !principal.HasScope(scope) {
+				logger.Log.Warn(ctx, "Rejected token of user %d client %q without scope %s", principal.UserID, principal.ClientID, scope)
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				utils.RespondWithError(w, http.StatusForbidden, constants.ErrInsufficientScope)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
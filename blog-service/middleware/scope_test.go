@@ -0,0 +1,68 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+
+	"blog-service/constants"
+	"blog-service/middleware"
+	"blog-service/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *models.Principal
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "unauthenticated",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  constants.TokenMissing,
+		},
+		{
+			name:       "unscoped first-party token",
+			principal:  &models.Principal{UserID: 7},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "first-party token scoped until the email is verified",
+			principal:  &models.Principal{UserID: 7, Scopes: []string{constants.ScopeBlogRead}},
+			wantStatus: http.StatusForbidden,
+			wantError:  constants.ErrInsufficientScope,
+		},
+		{
+			name:       "client token with the scope",
+			principal:  &models.Principal{UserID: 7, ClientID: "app", Scopes: []string{constants.ScopeBlogRead, constants.ScopeBlogWrite}},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "client token without the scope",
+			principal:  &models.Principal{UserID: 7, ClientID: "app", Scopes: []string{constants.ScopeBlogRead}},
+			wantStatus: http.StatusForbidden,
+			wantError:  constants.ErrInsufficientScope,
+		},
+		{
+			name:       "unscoped client token",
+			principal:  &models.Principal{ClientID: "app"},
+			wantStatus: http.StatusForbidden,
+			wantError:  constants.ErrInsufficientScope,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAs(middleware.RequireScope(constants.ScopeBlogWrite), tt.principal)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantError != "" {
+				assert.Equal(t, tt.wantError, errorOf(t, rec))
+			}
+			if tt.wantStatus == http.StatusForbidden {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `scope="`+constants.ScopeBlogWrite+`"`)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ var (
 	ErrInvalidSortField = errors.New("blog: invalid sort field")
 	ErrInvalidSortOrder = errors.New("blog: invalid sort order")
 	ErrBlogNotFound     = errors.New("blog: not found")
+	ErrBlogForbidden    = errors.New("blog: action not allowed for this user")
 	ErrDBOperation      = errors.New("blog: database operation failed")
 	ErrBlogCreateFailed = errors.New("blog: creation failed")
 )
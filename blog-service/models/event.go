@@ -0,0 +1,15 @@
+package models
+
+import "encoding/json"
+
+// Event is an event auth-service publishes through its outbox. Events are read in ID order.
+type Event struct {
+	ID      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// UserDeletedPayload is the payload of user.deleted events.
+type UserDeletedPayload struct {
+	UserID int64 `json:"user_id"`
+}
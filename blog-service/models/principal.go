@@ -0,0 +1,42 @@
+package models
+
+import "slices"
+
+// Principal is the authenticated caller resolved from an access token.
+// Tokens issued to an OAuth client carry its ClientID and the granted Scopes; tokens of the
+// client_credentials grant have a ClientID but no UserID. Roles and Permissions come from the
+// user's roles in auth-service. ActorID is set when an admin impersonates the user.
+type Principal struct {
+	UserID      uint     `json:"user_id"`
+	Email       string   `json:"email"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	ActorID     uint     `json:"actor_id,omitempty"`
+}
+
+// Impersonated reports whether an admin is acting as the user, e.g. to reproduce a bug.
+func (p Principal) Impersonated() bool {
+	return p.ActorID != 0
+}
+
+// IsClient reports whether the token was issued to an OAuth client rather than by a first-party login.
+func (p Principal) IsClient() bool {
+	return p.ClientID != ""
+}
+
+// HasScope reports whether the principal was granted scope. First-party tokens are normally not
+// scoped and may do anything the user may do; auth-service only scopes them when the user has not
+// verified their email yet, and then the scope applies like it does for clients.
+func (p Principal) HasScope(scope string) bool {
+	if !p.IsClient() && len(p.Scopes) == 0 {
+		return true
+	}
+	return slices.Contains(p.Scopes, scope)
+}
+
+// HasPermission reports whether the principal's roles grant permission.
+func (p Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
@@ -1,6 +1,12 @@
 package request
 
-import "blog-service/constants"
+import (
+	"strings"
+
+	"blog-service/constants"
+	"blog-service/models"
+	"blog-service/models/schema"
+)
 
 // BlogListReq  is the request body for blog list
 type BlogListReq struct {
@@ -54,3 +60,66 @@ func isValidBlogSortField(field string) bool {
 	}
 	return false
 }
+
+// maxBlogTitleLength mirrors the size of the blogs.title column
+const maxBlogTitleLength = 255
+
+// BlogCreateReq is the request body for creating a blog.
+// AuthorID is never read from the body; it is set from the authenticated principal.
+type BlogCreateReq struct {
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	AuthorID uint   `json:"-"`
+}
+
+// Validate validates the request
+func (r *BlogCreateReq) Validate() error {
+	return validateBlogFields(r.Title, r.Content, r.AuthorID)
+}
+
+// ToSchema converts the request to a Blog schema
+func (r *BlogCreateReq) ToSchema() *schema.Blog {
+	return &schema.Blog{
+		Title:    strings.TrimSpace(r.Title),
+		Content:  r.Content,
+		AuthorID: r.AuthorID,
+	}
+}
+
+// BlogUpdateReq is the request body for updating a blog.
+// AuthorID is never read from the body; it is set from the authenticated principal.
+type BlogUpdateReq struct {
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	AuthorID uint   `json:"-"`
+}
+
+// Validate validates the request
+func (r *BlogUpdateReq) Validate() error {
+	return validateBlogFields(r.Title, r.Content, r.AuthorID)
+}
+
+// ToSchema converts the request to a Blog schema for the given blog ID
+func (r *BlogUpdateReq) ToSchema(blogID int64) *schema.Blog {
+	return &schema.Blog{
+		ID:       uint(blogID),
+		Title:    strings.TrimSpace(r.Title),
+		Content:  r.Content,
+		AuthorID: r.AuthorID,
+	}
+}
+
+// validateBlogFields checks the fields shared by the create and update requests
+func validateBlogFields(title, content string, authorID uint) error {
+	title = strings.TrimSpace(title)
+	if title == "" || len(title) > maxBlogTitleLength {
+		return models.ErrInvalidTitle
+	}
+	if strings.TrimSpace(content) == "" {
+		return models.ErrInvalidContent
+	}
+	if authorID == 0 {
+		return models.ErrInvalidAuthorID
+	}
+	return nil
+}
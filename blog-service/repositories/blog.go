@@ -19,6 +19,7 @@ type BlogRepository interface {
 	CreateBlog(ctx context.Context, blog *schema.Blog) error
 	GetAllBlogs(ctx context.Context, pageReq request.PaginationRequest) ([]schema.Blog, int64, error)
 	GetBlogsByAuthorID(ctx context.Context, authorId int64, pageReq request.PaginationRequest) ([]schema.Blog, int64, error)
+	GetAllBlogsByAuthorID(ctx context.Context, authorId int64) ([]schema.Blog, error)
 
 	GetBlogCount(ctx context.Context) (int64, error)
 
@@ -41,12 +42,14 @@ func NewBlogRepository(db *sql.DB, log *logger.AppLogger) BlogRepository {
 	}
 }
 
-// CreateBlog adds a new blog to the repository.
+// CreateBlog adds a new blog to the repository and fills in the generated ID and timestamps.
 func (repo *blogRepository) CreateBlog(ctx context.Context, blog *schema.Blog) error {
 	repo.log.Infof("Creating new blog: %+v", blog)
-	query := `INSERT INTO blogs (title, content, author_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+	query := `INSERT INTO blogs (title, content, author_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`
 
-	_, err := repo.db.ExecContext(ctx, query, blog.Title, blog.Content, blog.AuthorID, time.Now(), time.Now())
+	now := time.Now()
+	err := repo.db.QueryRowContext(ctx, query, blog.Title, blog.Content, blog.AuthorID, now, now).
+		Scan(&blog.ID, &blog.CreatedAt, &blog.UpdatedAt)
 	if err != nil {
 		repo.log.Errorf("Failed to create blog: %v", err)
 		return fmt.Errorf("creating blog: %w", err)
@@ -151,6 +154,41 @@ func (repo *blogRepository) GetBlogsByAuthorID(ctx context.Context, authorId int
 
 }
 
+// GetAllBlogsByAuthorID retrieves every blog of an author, oldest first, e.g. for a data export.
+func (repo *blogRepository) GetAllBlogsByAuthorID(ctx context.Context, authorId int64) ([]schema.Blog, error) {
+	repo.log.Infof("Fetching all blogs by author ID: %d", authorId)
+
+	var blogs = make([]schema.Blog, 0)
+	query := `SELECT id, title, content, author_id, created_at, updated_at FROM blogs WHERE author_id = $1 ORDER BY id`
+	rows, err := repo.db.QueryContext(ctx, query, authorId)
+	if err != nil {
+		repo.log.Errorf("Failed to fetch blogs: %v", err)
+		return blogs, fmt.Errorf("fetching blogs: %w", err)
+	}
+
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			repo.log.Errorf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var blog schema.Blog
+		if err := rows.Scan(&blog.ID, &blog.Title, &blog.Content, &blog.AuthorID, &blog.CreatedAt, &blog.UpdatedAt); err != nil {
+			repo.log.Errorf("Failed to scan blog: %v", err)
+			return []schema.Blog{}, fmt.Errorf("scanning blog: %w", err)
+		}
+		blogs = append(blogs, blog)
+	}
+	if err := rows.Err(); err != nil {
+		repo.log.Errorf("Row iteration error: %v", err)
+		return []schema.Blog{}, fmt.Errorf("iterating rows: %w", err)
+	}
+
+	return blogs, nil
+}
+
 // GetBlogCount retrieves the total number of blogs
 func (repo *blogRepository) GetBlogCount(ctx context.Context) (int64, error) {
 	var count int64
@@ -166,7 +204,7 @@ func (repo *blogRepository) GetBlogCount(ctx context.Context) (int64, error) {
 // GetBlogByID retrieves a blog by its ID.
 func (repo *blogRepository) GetBlogByID(ctx context.Context, blogId int64) (*schema.Blog, error) {
 	repo.log.Infof("Fetching blog by ID: %d", blogId)
-	query := `SELECT id, title, content, author_id, created_at, updated_at FROM blogs WHERE id = ?`
+	query := `SELECT id, title, content, author_id, created_at, updated_at FROM blogs WHERE id = $1`
 	var blog schema.Blog
 
 	if err := repo.db.QueryRowContext(ctx, query, blogId).Scan(&blog.ID, &blog.Title, &blog.Content, &blog.AuthorID, &blog.CreatedAt, &blog.UpdatedAt); err != nil {
@@ -180,13 +218,18 @@ func (repo *blogRepository) GetBlogByID(ctx context.Context, blogId int64) (*sch
 	return &blog, nil
 }
 
-// UpdateBlog modifies an existing blog in the repository.
+// UpdateBlog modifies an existing blog in the repository and refreshes its timestamps.
 func (repo *blogRepository) UpdateBlog(ctx context.Context, blog *schema.Blog) error {
 	repo.log.Infof("Updating blog: %+v", blog)
-	query := `UPDATE blogs SET title = ?, content = ?, author_id = ?, updated_at = ? WHERE id = ?`
+	query := `UPDATE blogs SET title = $1, content = $2, author_id = $3, updated_at = $4 WHERE id = $5 RETURNING created_at, updated_at`
 
-	_, err := repo.db.ExecContext(ctx, query, blog.Title, blog.Content, blog.AuthorID, time.Now(), blog.ID)
+	err := repo.db.QueryRowContext(ctx, query, blog.Title, blog.Content, blog.AuthorID, time.Now(), blog.ID).
+		Scan(&blog.CreatedAt, &blog.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			repo.log.Warnf("Blog not found with ID: %d", blog.ID)
+			return models.ErrBlogNotFound
+		}
 		repo.log.Errorf("Failed to update blog: %v", err)
 		return fmt.Errorf("updating blog: %w", err)
 	}
@@ -196,12 +239,22 @@ func (repo *blogRepository) UpdateBlog(ctx context.Context, blog *schema.Blog) e
 // DeleteBlog removes a blog from the repository.
 func (repo *blogRepository) DeleteBlog(ctx context.Context, blogId int64) error {
 	repo.log.Infof("Deleting blog with ID: %d", blogId)
-	query := `DELETE FROM blogs WHERE id = ?`
+	query := `DELETE FROM blogs WHERE id = $1`
 
-	_, err := repo.db.ExecContext(ctx, query, blogId)
+	result, err := repo.db.ExecContext(ctx, query, blogId)
 	if err != nil {
 		repo.log.Errorf("Failed to delete blog: %v", err)
 		return fmt.Errorf("deleting blog: %w", err)
 	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		repo.log.Errorf("Failed to read affected rows: %v", err)
+		return fmt.Errorf("deleting blog: %w", err)
+	}
+	if affected == 0 {
+		repo.log.Warnf("Blog not found with ID: %d", blogId)
+		return models.ErrBlogNotFound
+	}
 	return nil
 }
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"blog-service/logger"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// EventRepository keeps track of the auth-service events blog-service has handled and applies
+// their effects on blogs, each together with moving the cursor past the event.
+type EventRepository interface {
+	GetCursor(ctx context.Context, consumer string) (int64, error)
+	SaveCursor(ctx context.Context, consumer string, eventID int64) error
+	DeleteAuthorBlogs(ctx context.Context, consumer string, eventID, authorId int64) (int64, error)
+}
+
+// eventRepository is a concrete implementation of EventRepository.
+type eventRepository struct {
+	db  *sql.DB
+	log *logger.AppLogger
+}
+
+// NewEventRepository creates a new instance of EventRepository.
+func NewEventRepository(db *sql.DB, log *logger.AppLogger) EventRepository {
+	return &eventRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetCursor returns the id of the last event consumer handled, or zero if it has handled none.
+func (repo *eventRepository) GetCursor(ctx context.Context, consumer string) (int64, error) {
+	var eventID int64
+	query := `SELECT last_event_id FROM event_cursors WHERE consumer = $1`
+	if err := repo.db.QueryRowContext(ctx, query, consumer).Scan(&eventID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		repo.log.Errorf("Failed to fetch event cursor of %s: %v", consumer, err)
+		return 0, fmt.Errorf("fetching event cursor: %w", err)
+	}
+	return eventID, nil
+}
+
+// SaveCursor records that consumer handled every event up to eventID.
+func (repo *eventRepository) SaveCursor(ctx context.Context, consumer string, eventID int64) error {
+	if err := saveCursor(ctx, repo.db, consumer, eventID); err != nil {
+		repo.log.Errorf("Failed to save event cursor of %s: %v", consumer, err)
+		return fmt.Errorf("saving event cursor: %w", err)
+	}
+	return nil
+}
+
+// DeleteAuthorBlogs removes every blog of an author and moves the cursor of consumer to eventID in
+// one transaction, so the blogs are removed exactly when the event counts as handled. Returns the
+// number of blogs removed.
+func (repo *eventRepository) DeleteAuthorBlogs(ctx context.Context, consumer string, eventID, authorId int64) (int64, error) {
+	repo.log.Infof("Deleting blogs of author ID: %d for event %d", authorId, eventID)
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		repo.log.Errorf("Failed to begin transaction: %v", err)
+		return 0, fmt.Errorf("deleting blogs of author: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM blogs WHERE author_id = $1`, authorId)
+	if err != nil {
+		repo.log.Errorf("Failed to delete blogs of author: %v", err)
+		return 0, fmt.Errorf("deleting blogs of author: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		repo.log.Errorf("Failed to read affected rows: %v", err)
+		return 0, fmt.Errorf("deleting blogs of author: %w", err)
+	}
+
+	if err := saveCursor(ctx, tx, consumer, eventID); err != nil {
+		repo.log.Errorf("Failed to save event cursor of %s: %v", consumer, err)
+		return 0, fmt.Errorf("saving event cursor: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		repo.log.Errorf("Failed to commit transaction: %v", err)
+		return 0, fmt.Errorf("deleting blogs of author: %w", err)
+	}
+	return deleted, nil
+}
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// saveCursor upserts the cursor of consumer. The cursor never moves backwards.
+func saveCursor(ctx context.Context, db execer, consumer string, eventID int64) error {
+	query := `INSERT INTO event_cursors (consumer, last_event_id, updated_at) VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (consumer) DO UPDATE SET last_event_id = GREATEST(event_cursors.last_event_id, EXCLUDED.last_event_id),
+		updated_at = CURRENT_TIMESTAMP`
+	_, err := db.ExecContext(ctx, query, consumer, eventID)
+	return err
+}
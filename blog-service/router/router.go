@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 
+	"blog-service/constants"
 	"blog-service/controllers"
 	"blog-service/middleware"
 )
@@ -16,6 +17,10 @@ const (
 	blogsPath = "/blogs"
 	// blogDetailPath is the path for accessing a specific blog by its ID.
 	blogDetailPath = "/blogs/{id}"
+	// myBlogsPath is the path for all blogs of the authenticated user.
+	myBlogsPath = "/me/blogs"
+	// adminBlogDetailPath is the path moderators delete blogs of any author at.
+	adminBlogDetailPath = "/admin/blogs/{id}"
 )
 
 // Middleware defines a function type for HTTP middleware.
@@ -23,13 +28,19 @@ const (
 type Middleware func(http.Handler) http.Handler
 
 // route represents an API endpoint configuration.
-// It contains the HTTP method, version, path, handler, and a human-readable name.
+// It contains the HTTP method, version, path, handler, a human-readable name,
+// whether the endpoint requires an authenticated caller, the scope scoped tokens need, the
+// permission the caller's roles must grant and whether admins impersonating a user are refused.
 type route struct {
-	method  string       // HTTP method (GET, POST, PUT, DELETE)
-	version string       // API version this route belongs to
-	path    string       // URL path for the endpoint
-	handler http.Handler // HTTP handler function for this route
-	name    string       // Human-readable name for the route
+	method      string       // HTTP method (GET, POST, PUT, DELETE)
+	version     string       // API version this route belongs to
+	path        string       // URL path for the endpoint
+	handler     http.Handler // HTTP handler function for this route
+	name        string       // Human-readable name for the route
+	protected   bool         // Whether the route requires a valid access token
+	scope       string       // Scope a scoped token must carry; only checked on protected routes
+	permission  string       // Permission the caller's roles must grant; only checked on protected routes
+	destructive bool         // Whether impersonation tokens are refused; only checked on protected routes
 }
 
 // createVersionPath constructs a complete endpoint path by combining the API version and the specified path.
@@ -56,10 +67,11 @@ func createPattern(method, version, path string) string {
 //
 // Parameters:
 //   - blogCtrl: An instance of BlogController that implements all handler methods.
+//   - authMiddleware: Middleware applied to protected routes to authenticate the caller.
 //
 // Returns:
 //   - *http.ServeMux: A configured HTTP router with all routes registered.
-func Init(blogCtrl controllers.BlogController) *http.ServeMux {
+func Init(blogCtrl controllers.BlogController, authMiddleware Middleware) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Define the routes for version 1 of the API.
@@ -67,37 +79,64 @@ func Init(blogCtrl controllers.BlogController) *http.ServeMux {
 		{
 			method:  http.MethodGet,
 			path:    blogsPath,
-			handler: Middleware(middleware.RequestIDMiddleware)(http.HandlerFunc(blogCtrl.GetBlogList)),
+			handler: http.HandlerFunc(blogCtrl.GetBlogList),
 			version: V1,
 			name:    "List Blogs",
 		},
 		{
-			method:  http.MethodPost,
-			path:    blogsPath,
-			handler: Middleware(middleware.RequestIDMiddleware)(http.HandlerFunc(blogCtrl.CreateBlog)),
-			version: V1,
-			name:    "Create Blog",
+			method:    http.MethodPost,
+			path:      blogsPath,
+			handler:   http.HandlerFunc(blogCtrl.CreateBlog),
+			version:   V1,
+			name:      "Create Blog",
+			protected: true,
+			scope:     constants.ScopeBlogWrite,
 		},
 		{
 			method:  http.MethodGet,
 			path:    blogDetailPath,
-			handler: Middleware(middleware.RequestIDMiddleware)(http.HandlerFunc(blogCtrl.GetBlogByID)),
+			handler: http.HandlerFunc(blogCtrl.GetBlogByID),
 			version: V1,
 			name:    "Get Blog Detail",
 		},
 		{
-			method:  http.MethodPut,
-			path:    blogDetailPath,
-			handler: Middleware(middleware.RequestIDMiddleware)(http.HandlerFunc(blogCtrl.UpdateBlog)),
-			version: V1,
-			name:    "Update Blog Detail",
+			method:    http.MethodPut,
+			path:      blogDetailPath,
+			handler:   http.HandlerFunc(blogCtrl.UpdateBlog),
+			version:   V1,
+			name:      "Update Blog Detail",
+			protected: true,
+			scope:     constants.ScopeBlogWrite,
 		},
 		{
-			method:  http.MethodDelete,
-			path:    blogDetailPath,
-			handler: Middleware(middleware.RequestIDMiddleware)(http.HandlerFunc(blogCtrl.DeleteBlog)),
-			version: V1,
-			name:    "Delete Blog Detail",
+			method:      http.MethodDelete,
+			path:        blogDetailPath,
+			handler:     http.HandlerFunc(blogCtrl.DeleteBlog),
+			version:     V1,
+			name:        "Delete Blog Detail",
+			protected:   true,
+			scope:       constants.ScopeBlogWrite,
+			destructive: true,
+		},
+		{
+			method:      http.MethodDelete,
+			path:        adminBlogDetailPath,
+			handler:     http.HandlerFunc(blogCtrl.DeleteBlog),
+			version:     V1,
+			name:        "Delete Any Blog",
+			protected:   true,
+			scope:       constants.ScopeBlogWrite,
+			permission:  constants.PermissionBlogDeleteAny,
+			destructive: true,
+		},
+		{
+			method:    http.MethodGet,
+			path:      myBlogsPath,
+			handler:   http.HandlerFunc(blogCtrl.GetMyBlogs),
+			version:   V1,
+			name:      "List My Blogs",
+			protected: true,
+			scope:     constants.ScopeBlogRead,
 		},
 	}
 
@@ -106,10 +145,30 @@ func Init(blogCtrl controllers.BlogController) *http.ServeMux {
 	log.Println("Registering routes.....")
 	for _, route := range routes {
 		pattern := createPattern(route.method, route.version, route.path)
-		log.Println(pattern)
+		log.Println(pattern, "protected:", route.protected, "scope:", route.scope, "permission:", route.permission, "destructive:", route.destructive)
 
-		mux.Handle(pattern, route.handler)
+		mux.Handle(pattern, route.chain(authMiddleware))
 	}
 
 	return mux
 }
+
+// chain wraps the route handler with the middlewares it needs.
+// The request ID middleware is outermost so authentication failures are still traceable,
+// and the scope, permission and impersonation checks run once the caller is authenticated.
+func (rt route) chain(authMiddleware Middleware) http.Handler {
+	handler := rt.handler
+	if rt.protected {
+		if rt.destructive {
+			handler = middleware.RejectImpersonation(handler)
+		}
+		if rt.scope != "" {
+			handler = middleware.RequireScope(rt.scope)(handler)
+		}
+		if rt.permission != "" {
+			handler = middleware.RequirePermission(rt.permission)(handler)
+		}
+		handler = authMiddleware(handler)
+	}
+	return Middleware(middleware.RequestIDMiddleware)(handler)
+}
@@ -3,12 +3,12 @@ package services
 import (
 	"blog-service/constants"
 	"blog-service/logger"
+	"blog-service/models"
 	"blog-service/models/request"
 	"blog-service/models/resp"
 	"blog-service/models/schema"
 	"blog-service/repositories"
 	"context"
-	"errors"
 	"fmt"
 )
 
@@ -17,9 +17,10 @@ type BlogService interface {
 	GetAllBlogs(ctx context.Context, pageReq request.PaginationRequest) (*resp.BlogListPaginatedResp, error)
 	CreateBlog(ctx context.Context, blog *schema.Blog) error
 	UpdateBlog(ctx context.Context, blog *schema.Blog) error
-	DeleteBlog(ctx context.Context, id int64, authUserID uint) error
+	DeleteBlog(ctx context.Context, id int64, principal *models.Principal) error
 	GetBlogById(ctx context.Context, blogId int64) (*schema.Blog, error)
 	GetBlogsByAuthorID(ctx context.Context, authorID int64, pageReq request.PaginationRequest) (*resp.BlogListPaginatedResp, error)
+	GetAllBlogsByAuthorID(ctx context.Context, authorID int64) (schema.BlogList, error)
 }
 
 // blogService is a concrete implementation of BlogService.
@@ -76,7 +77,7 @@ func (s *blogService) CreateBlog(ctx context.Context, blog *schema.Blog) error {
 	}
 
 	s.log.Infof("Creating new blog: %+v", blog)
-	if err := s.blogRepo.CreateBlog(context.Background(), blog); err != nil {
+	if err := s.blogRepo.CreateBlog(ctx, blog); err != nil {
 		s.log.WithError(err).Error("Failed to create blog")
 		return fmt.Errorf("could not create blog: %w", err)
 	}
@@ -84,14 +85,25 @@ func (s *blogService) CreateBlog(ctx context.Context, blog *schema.Blog) error {
 	return nil
 }
 
-// UpdateBlog modifies an existing blog in the repository.
+// UpdateBlog modifies an existing blog in the repository if blog.AuthorID is the author.
 func (s *blogService) UpdateBlog(ctx context.Context, blog *schema.Blog) error {
 	if err := validateBlog(blog); err != nil {
 		return err
 	}
 
+	existing, err := s.blogRepo.GetBlogByID(ctx, int64(blog.ID))
+	if err != nil {
+		s.log.WithError(err).Error("Failed to retrieve blog for update")
+		return fmt.Errorf("could not find blog: %w", err)
+	}
+
+	if existing.AuthorID != blog.AuthorID {
+		s.log.Warn(ctx, "Unauthorized attempt to update blog")
+		return models.ErrBlogForbidden
+	}
+
 	s.log.Infof("Updating blog: %+v", blog)
-	if err := s.blogRepo.UpdateBlog(context.Background(), blog); err != nil {
+	if err := s.blogRepo.UpdateBlog(ctx, blog); err != nil {
 		s.log.WithError(err).Error("Failed to update blog")
 		return fmt.Errorf("could not update blog: %w", err)
 	}
@@ -99,21 +111,25 @@ func (s *blogService) UpdateBlog(ctx context.Context, blog *schema.Blog) error {
 	return nil
 }
 
-// DeleteBlog removes a blog from the repository if the authenticated user is the author.
-func (s *blogService) DeleteBlog(ctx context.Context, id int64, authUserID uint) error {
-	blog, err := s.blogRepo.GetBlogByID(context.Background(), id)
+// DeleteBlog removes a blog from the repository if the authenticated user is the author
+// or has a role that may delete any post (admins and moderators).
+func (s *blogService) DeleteBlog(ctx context.Context, id int64, principal *models.Principal) error {
+	blog, err := s.blogRepo.GetBlogByID(ctx, id)
 	if err != nil {
 		s.log.WithError(err).Error("Failed to retrieve blog for deletion")
 		return fmt.Errorf("could not find blog: %w", err)
 	}
 
-	if blog.AuthorID != authUserID {
-		s.log.Warn(ctx, "Unauthorized attempt to delete blog")
-		return errors.New("unauthorized to delete this blog")
+	if blog.AuthorID != principal.UserID {
+		if !principal.HasPermission(constants.PermissionBlogDeleteAny) {
+			s.log.Warn(ctx, "Unauthorized attempt to delete blog")
+			return models.ErrBlogForbidden
+		}
+		s.log.Info(ctx, "User %d deleting blog %d of author %d as moderator", principal.UserID, id, blog.AuthorID)
 	}
 
 	s.log.Infof("Deleting blog with ID: %d", id)
-	if err := s.blogRepo.DeleteBlog(context.Background(), id); err != nil {
+	if err := s.blogRepo.DeleteBlog(ctx, id); err != nil {
 		s.log.WithError(err).Error("Failed to delete blog")
 		return fmt.Errorf("could not delete blog: %w", err)
 	}
@@ -124,7 +140,7 @@ func (s *blogService) DeleteBlog(ctx context.Context, id int64, authUserID uint)
 // GetBlogById retrieves a blog by its ID.
 func (s *blogService) GetBlogById(ctx context.Context, blogId int64) (*schema.Blog, error) {
 	s.log.Infof("Fetching blog with ID: %d", blogId)
-	blog, err := s.blogRepo.GetBlogByID(context.Background(), blogId)
+	blog, err := s.blogRepo.GetBlogByID(ctx, blogId)
 	if err != nil {
 		s.log.Error(ctx, "Failed to fetch blog by ID")
 		return nil, fmt.Errorf("could not retrieve blog: %w", err)
@@ -163,13 +179,30 @@ func (s *blogService) GetBlogsByAuthorID(ctx context.Context, authorID int64, pa
 	return paginatedResponse, nil
 }
 
+// GetAllBlogsByAuthorID retrieves every blog of an author without pagination.
+func (s *blogService) GetAllBlogsByAuthorID(ctx context.Context, authorID int64) (schema.BlogList, error) {
+	s.log.Infof("Fetching all blogs for author ID: %d", authorID)
+
+	blogs, err := s.blogRepo.GetAllBlogsByAuthorID(ctx, authorID)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to fetch all blogs by author from repository")
+		return nil, fmt.Errorf("could not retrieve blogs for author: %w", err)
+	}
+
+	s.log.Infof("Successfully fetched %d blogs for author ID: %d", len(blogs), authorID)
+	return blogs, nil
+}
+
 // validateBlog checks if the blog data is valid.
 func validateBlog(blog *schema.Blog) error {
 	if blog.Title == "" {
-		return errors.New("blog title cannot be empty")
+		return models.ErrInvalidTitle
 	}
 	if blog.Content == "" {
-		return errors.New("blog content cannot be empty")
+		return models.ErrInvalidContent
+	}
+	if blog.AuthorID == 0 {
+		return models.ErrInvalidAuthorID
 	}
 	return nil
 }
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"blog-service/constants"
+	"blog-service/logger"
+	"blog-service/models"
+	"blog-service/models/schema"
+	"blog-service/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBlogRepository holds blogs in memory and records which were deleted
+type fakeBlogRepository struct {
+	repositories.BlogRepository
+	blogs   map[int64]schema.Blog
+	deleted []int64
+}
+
+// GetBlogByID returns a stored blog
+func (r *fakeBlogRepository) GetBlogByID(_ context.Context, blogId int64) (*schema.Blog, error) {
+	blog, ok := r.blogs[blogId]
+	if !ok {
+		return nil, models.ErrBlogNotFound
+	}
+	return &blog, nil
+}
+
+// DeleteBlog records the deletion
+func (r *fakeBlogRepository) DeleteBlog(_ context.Context, blogId int64) error {
+	r.deleted = append(r.deleted, blogId)
+	return nil
+}
+
+func TestBlogService_DeleteBlog(t *testing.T) {
+	const authorID, otherID = 7, 8
+
+	tests := []struct {
+		name        string
+		blogID      int64
+		principal   *models.Principal
+		wantErr     error
+		wantDeleted []int64
+	}{
+		{
+			name:        "author",
+			blogID:      1,
+			principal:   &models.Principal{UserID: authorID},
+			wantDeleted: []int64{1},
+		},
+		{
+			name:      "another user",
+			blogID:    1,
+			principal: &models.Principal{UserID: otherID},
+			wantErr:   models.ErrBlogForbidden,
+		},
+		{
+			name:      "another user with a role without the permission",
+			blogID:    1,
+			principal: &models.Principal{UserID: otherID, Roles: []string{"editor"}, Permissions: []string{"blog:publish"}},
+			wantErr:   models.ErrBlogForbidden,
+		},
+		{
+			name:        "moderator",
+			blogID:      1,
+			principal:   &models.Principal{UserID: otherID, Roles: []string{"moderator"}, Permissions: []string{constants.PermissionBlogDeleteAny}},
+			wantDeleted: []int64{1},
+		},
+		{
+			name:      "missing blog",
+			blogID:    2,
+			principal: &models.Principal{UserID: authorID},
+			wantErr:   models.ErrBlogNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBlogRepository{blogs: map[int64]schema.Blog{1: {ID: 1, AuthorID: authorID}}}
+
+			err := NewBlogService(repo, logger.Log).DeleteBlog(context.Background(), tt.blogID, tt.principal)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantDeleted, repo.deleted)
+		})
+	}
+}
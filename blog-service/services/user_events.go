@@ -0,0 +1,104 @@
+package services
+
+import (
+	"blog-service/constants"
+	"blog-service/logger"
+	"blog-service/models"
+	"blog-service/repositories"
+	"blog-service/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// UserEventConsumer applies the user events of auth-service to blogs: the blogs of deleted users
+// are removed. Events are handled in order and at least once; handling one twice does no harm.
+type UserEventConsumer interface {
+	Poll(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+// userEventConsumer is a concrete implementation of UserEventConsumer.
+type userEventConsumer struct {
+	source    utils.EventSource
+	eventRepo repositories.EventRepository
+	log       *logger.AppLogger
+}
+
+// NewUserEventConsumer creates a new instance of UserEventConsumer.
+func NewUserEventConsumer(source utils.EventSource, repo repositories.EventRepository, logger *logger.AppLogger) UserEventConsumer {
+	return &userEventConsumer{
+		source:    source,
+		eventRepo: repo,
+		log:       logger,
+	}
+}
+
+// Poll handles the events published since the last handled one, until none are left, and returns
+// how many were handled. An event that fails stops the poll, so it is retried by the next one.
+func (c *userEventConsumer) Poll(ctx context.Context) (int, error) {
+	cursor, err := c.eventRepo.GetCursor(ctx, constants.EventConsumerAuthService)
+	if err != nil {
+		return 0, fmt.Errorf("could not read event cursor: %w", err)
+	}
+
+	handled := 0
+	for {
+		events, err := c.source.Fetch(ctx, cursor, constants.EventBatchSize)
+		if err != nil {
+			return handled, fmt.Errorf("could not fetch events: %w", err)
+		}
+		for _, event := range events {
+			if err := c.handle(ctx, event); err != nil {
+				return handled, fmt.Errorf("could not handle event %d: %w", event.ID, err)
+			}
+			cursor = event.ID
+			handled++
+		}
+		if len(events) < constants.EventBatchSize {
+			return handled, nil
+		}
+	}
+}
+
+// Run polls the auth-service outbox every EventPollInterval until ctx is cancelled.
+func (c *userEventConsumer) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.EventPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if handled, err := c.Poll(ctx); err != nil {
+			c.log.WithError(err).Error("Failed to consume auth-service events")
+		} else if handled > 0 {
+			c.log.Infof("Handled %d auth-service events", handled)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handle applies an event and moves the cursor past it. Events of other types are only skipped.
+func (c *userEventConsumer) handle(ctx context.Context, event models.Event) error {
+	if event.Type != constants.EventUserDeleted {
+		return c.eventRepo.SaveCursor(ctx, constants.EventConsumerAuthService, event.ID)
+	}
+
+	var payload models.UserDeletedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.UserID <= 0 {
+		// a malformed event would block every later one, so it is logged and skipped
+		c.log.Errorf("Skipping malformed %s event %d: %s", event.Type, event.ID, event.Payload)
+		return c.eventRepo.SaveCursor(ctx, constants.EventConsumerAuthService, event.ID)
+	}
+
+	deleted, err := c.eventRepo.DeleteAuthorBlogs(ctx, constants.EventConsumerAuthService, event.ID, payload.UserID)
+	if err != nil {
+		return err
+	}
+	c.log.Infof("Deleted %d blogs of deleted user %d", deleted, payload.UserID)
+	return nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"blog-service/constants"
+	"blog-service/logger"
+	"blog-service/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEventSource serves events from memory
+type fakeEventSource struct {
+	events []models.Event
+	err    error
+}
+
+// Fetch returns up to limit events after afterID
+func (s *fakeEventSource) Fetch(_ context.Context, afterID int64, limit int) ([]models.Event, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	page := []models.Event{}
+	for _, event := range s.events {
+		if event.ID > afterID && len(page) < limit {
+			page = append(page, event)
+		}
+	}
+	return page, nil
+}
+
+// fakeEventRepository keeps the cursor in memory and records the authors whose blogs were deleted
+type fakeEventRepository struct {
+	cursor         int64
+	deletedAuthors []int64
+	deleteErr      error
+}
+
+// GetCursor returns the saved cursor
+func (r *fakeEventRepository) GetCursor(context.Context, string) (int64, error) {
+	return r.cursor, nil
+}
+
+// SaveCursor saves the cursor
+func (r *fakeEventRepository) SaveCursor(_ context.Context, _ string, eventID int64) error {
+	r.cursor = eventID
+	return nil
+}
+
+// DeleteAuthorBlogs records the author and moves the cursor, unless deleteErr is set
+func (r *fakeEventRepository) DeleteAuthorBlogs(_ context.Context, _ string, eventID, authorId int64) (int64, error) {
+	if r.deleteErr != nil {
+		return 0, r.deleteErr
+	}
+	r.deletedAuthors = append(r.deletedAuthors, authorId)
+	r.cursor = eventID
+	return 1, nil
+}
+
+// userDeleted returns a user.deleted event
+func userDeleted(id, userID int64) models.Event {
+	payload, _ := json.Marshal(models.UserDeletedPayload{UserID: userID})
+	return models.Event{ID: id, Type: constants.EventUserDeleted, Payload: payload}
+}
+
+func TestUserEventConsumer_Poll(t *testing.T) {
+	manyEvents := make([]models.Event, 0, constants.EventBatchSize+1)
+	for id := int64(1); id <= constants.EventBatchSize+1; id++ {
+		manyEvents = append(manyEvents, userDeleted(id, id))
+	}
+
+	tests := []struct {
+		name        string
+		cursor      int64
+		source      *fakeEventSource
+		deleteErr   error
+		wantHandled int
+		wantErr     bool
+		wantCursor  int64
+		wantAuthors []int64
+	}{
+		{
+			name:        "deletes the blogs of deleted users",
+			source:      &fakeEventSource{events: []models.Event{userDeleted(1, 7), userDeleted(2, 8)}},
+			wantHandled: 2,
+			wantCursor:  2,
+			wantAuthors: []int64{7, 8},
+		},
+		{
+			name:        "starts after the saved cursor",
+			cursor:      1,
+			source:      &fakeEventSource{events: []models.Event{userDeleted(1, 7), userDeleted(2, 8)}},
+			wantHandled: 1,
+			wantCursor:  2,
+			wantAuthors: []int64{8},
+		},
+		{
+			name:        "skips events of other types",
+			source:      &fakeEventSource{events: []models.Event{{ID: 1, Type: "user.renamed", Payload: json.RawMessage(`{}`)}, userDeleted(2, 8)}},
+			wantHandled: 2,
+			wantCursor:  2,
+			wantAuthors: []int64{8},
+		},
+		{
+			name:        "skips malformed events",
+			source:      &fakeEventSource{events: []models.Event{{ID: 1, Type: constants.EventUserDeleted, Payload: json.RawMessage(`{"user_id":0}`)}, userDeleted(2, 8)}},
+			wantHandled: 2,
+			wantCursor:  2,
+			wantAuthors: []int64{8},
+		},
+		{
+			name:        "fetches until a batch is not full",
+			source:      &fakeEventSource{events: manyEvents},
+			wantHandled: constants.EventBatchSize + 1,
+			wantCursor:  constants.EventBatchSize + 1,
+		},
+		{
+			name:       "failed event stops the poll without moving the cursor",
+			cursor:     1,
+			source:     &fakeEventSource{events: []models.Event{userDeleted(2, 8)}},
+			deleteErr:  errors.New("connection refused"),
+			wantErr:    true,
+			wantCursor: 1,
+		},
+		{
+			name:       "unreachable auth-service",
+			cursor:     1,
+			source:     &fakeEventSource{err: errors.New("connection refused")},
+			wantErr:    true,
+			wantCursor: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEventRepository{cursor: tt.cursor, deleteErr: tt.deleteErr}
+
+			handled, err := NewUserEventConsumer(tt.source, repo, logger.Log).Poll(context.Background())
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantHandled, handled)
+			assert.Equal(t, tt.wantCursor, repo.cursor)
+			if tt.wantAuthors != nil {
+				assert.Equal(t, tt.wantAuthors, repo.deletedAuthors)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"blog-service/constants"
+	"blog-service/models"
+)
+
+// EventSource returns the events auth-service published after a given event.
+type EventSource interface {
+	Fetch(ctx context.Context, afterID int64, limit int) ([]models.Event, error)
+}
+
+// eventsResponse is the body of the auth-service outbox endpoint.
+type eventsResponse struct {
+	Data struct {
+		Events []models.Event `json:"events"`
+	} `json:"data"`
+}
+
+// RemoteEventSource reads the auth-service outbox endpoint, authenticating as a confidential client.
+type RemoteEventSource struct {
+	url          string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+// NewRemoteEventSource returns an event source for the outbox endpoint at url.
+func NewRemoteEventSource(url, clientID, clientSecret string) *RemoteEventSource {
+	return &RemoteEventSource{
+		url:          url,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: constants.EventFetchTimeout},
+	}
+}
+
+// Fetch returns up to limit events with an ID greater than afterID, oldest first.
+func (s *RemoteEventSource) Fetch(ctx context.Context, afterID int64, limit int) ([]models.Event, error) {
+	endpoint, err := url.Parse(s.url)
+	if err != nil {
+		return nil, err
+	}
+	query := endpoint.Query()
+	query.Set("after", strconv.FormatInt(afterID, 10))
+	query.Set("limit", strconv.Itoa(limit))
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("events: unexpected status %d", resp.StatusCode)
+	}
+
+	var body eventsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Data.Events, nil
+}
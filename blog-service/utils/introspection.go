@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-service/constants"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// TokenIntrospector resolves opaque tokens, such as personal access tokens, to their claims, and
+// checks that access tokens verified offline were not revoked since they were issued.
+type TokenIntrospector interface {
+	Introspect(ctx context.Context, token string) (*TokenClaims, error)
+	CheckActive(ctx context.Context, token string) error
+}
+
+// introspectionResponse is the part of an RFC 7662 response of auth-service blog-service reads.
+type introspectionResponse struct {
+	Active      bool     `json:"active"`
+	Subject     string   `json:"sub"`
+	Issuer      string   `json:"iss"`
+	Email       string   `json:"email"`
+	ExpiresAt   int64    `json:"exp"`
+	IssuedAt    int64    `json:"iat"`
+	Scope       string   `json:"scope"`
+	ClientID    string   `json:"client_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// cachedIntrospection is an introspection result and when it must be asked for again.
+type cachedIntrospection struct {
+	claims    *TokenClaims
+	expiresAt time.Time
+}
+
+// RemoteIntrospector asks the auth-service introspection endpoint about tokens, authenticating as a
+// confidential client. Active results are cached for IntrospectionCacheTTL, keyed by the token hash.
+type RemoteIntrospector struct {
+	url          string
+	clientID     string
+	clientSecret string
+	issuer       string
+	client       *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedIntrospection
+}
+
+// NewRemoteIntrospector returns an introspector for the endpoint at url. Tokens must have been
+// issued by issuer.
+func NewRemoteIntrospector(url, clientID, clientSecret, issuer string) *RemoteIntrospector {
+	return &RemoteIntrospector{
+		url:          url,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		issuer:       issuer,
+		client:       &http.Client{Timeout: constants.IntrospectionTimeout},
+		cache:        map[string]cachedIntrospection{},
+	}
+}
+
+// Introspect returns the claims of an active token, or an error if auth-service reports it inactive.
+func (i *RemoteIntrospector) Introspect(ctx context.Context, token string) (*TokenClaims, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	i.mu.Lock()
+	cached, ok := i.cache[key]
+	i.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.claims, nil
+	}
+
+	resp, err := i.fetch(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Active {
+		return nil, errors.New(constants.TokenInactive)
+	}
+	if resp.Issuer != i.issuer {
+		return nil, errors.New(constants.TokenInvalidIssuer)
+	}
+
+	userID, err := strconv.ParseInt(resp.Subject, 10, 64)
+	if err != nil || resp.ClientID != "" {
+		// personal access tokens always belong to a user
+		return nil, errors.New(constants.TokenInvalid)
+	}
+	claims := &TokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   resp.Subject,
+			Issuer:    resp.Issuer,
+			ExpiresAt: resp.ExpiresAt,
+			IssuedAt:  resp.IssuedAt,
+		},
+		UserID:      userID,
+		Email:       resp.Email,
+		Scope:       resp.Scope,
+		Roles:       resp.Roles,
+		Permissions: resp.Permissions,
+	}
+
+	expiresAt := now.Add(constants.IntrospectionCacheTTL)
+	if resp.ExpiresAt != 0 && time.Unix(resp.ExpiresAt, 0).Before(expiresAt) {
+		expiresAt = time.Unix(resp.ExpiresAt, 0)
+	}
+	i.store(key, cachedIntrospection{claims: claims, expiresAt: expiresAt}, now)
+	return claims, nil
+}
+
+// CheckActive returns an error unless auth-service reports the token active. Its result is not
+// cached, so that logging out, revoking a session or disabling a user takes effect immediately.
+func (i *RemoteIntrospector) CheckActive(ctx context.Context, token string) error {
+	resp, err := i.fetch(ctx, token)
+	if err != nil {
+		return err
+	}
+	if !resp.Active {
+		return errors.New(constants.TokenInactive)
+	}
+	return nil
+}
+
+// fetch posts the token to the introspection endpoint.
+func (i *RemoteIntrospector) fetch(ctx context.Context, token string) (introspectionResponse, error) {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return introspectionResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return introspectionResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return introspectionResponse{}, fmt.Errorf("introspection: unexpected status %d", resp.StatusCode)
+	}
+
+	var body introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return introspectionResponse{}, err
+	}
+	return body, nil
+}
+
+// store caches a result. When the cache is full, expired entries are dropped, and if that is not
+// enough the whole cache is cleared.
+func (i *RemoteIntrospector) store(key string, entry cachedIntrospection, now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if len(i.cache) >= constants.IntrospectionCacheSize {
+		for k, cached := range i.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(i.cache, k)
+			}
+		}
+		if len(i.cache) >= constants.IntrospectionCacheSize {
+			i.cache = map[string]cachedIntrospection{}
+		}
+	}
+	i.cache[key] = entry
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blog-service/constants"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// introspectionServer answers with the response stored for each token and counts the requests
+// it gets
+func introspectionServer(t *testing.T, responses map[string]introspectionResponse) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != "blog-service" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(responses[r.PostFormValue("token")])
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRemoteIntrospector_Introspect(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	active := introspectionResponse{
+		Active:      true,
+		Subject:     "7",
+		Issuer:      "auth-service",
+		Email:       "asif@example.com",
+		ExpiresAt:   expiresAt,
+		Scope:       constants.ScopeBlogRead,
+		Roles:       []string{"moderator"},
+		Permissions: []string{constants.PermissionBlogDeleteAny},
+	}
+	otherIssuer := active
+	otherIssuer.Issuer = "someone-else"
+	clientToken := active
+	clientToken.ClientID = "app"
+
+	tests := []struct {
+		name       string
+		response   introspectionResponse
+		wantErr    string
+		wantClaims *TokenClaims
+	}{
+		{
+			name:     "active personal access token",
+			response: active,
+			wantClaims: &TokenClaims{
+				UserID:      7,
+				Email:       "asif@example.com",
+				Scope:       constants.ScopeBlogRead,
+				Roles:       []string{"moderator"},
+				Permissions: []string{constants.PermissionBlogDeleteAny},
+			},
+		},
+		{
+			name:     "revoked or expired token",
+			response: introspectionResponse{Active: false},
+			wantErr:  constants.TokenInactive,
+		},
+		{
+			name:     "token of another issuer",
+			response: otherIssuer,
+			wantErr:  constants.TokenInvalidIssuer,
+		},
+		{
+			name:     "token of an OAuth client",
+			response: clientToken,
+			wantErr:  constants.TokenInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := introspectionServer(t, map[string]introspectionResponse{"pat_abc": tt.response})
+			introspector := NewRemoteIntrospector(server.URL, "blog-service", "secret", "auth-service")
+
+			claims, err := introspector.Introspect(context.Background(), "pat_abc")
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClaims.UserID, claims.UserID)
+			assert.Equal(t, tt.wantClaims.Email, claims.Email)
+			assert.Equal(t, tt.wantClaims.Scope, claims.Scope)
+			assert.Equal(t, tt.wantClaims.Roles, claims.Roles)
+			assert.Equal(t, tt.wantClaims.Permissions, claims.Permissions)
+			assert.Equal(t, expiresAt, claims.ExpiresAt)
+		})
+	}
+
+	t.Run("active result is cached", func(t *testing.T) {
+		server, requests := introspectionServer(t, map[string]introspectionResponse{"pat_abc": active})
+		introspector := NewRemoteIntrospector(server.URL, "blog-service", "secret", "auth-service")
+
+		_, err := introspector.Introspect(context.Background(), "pat_abc")
+		require.NoError(t, err)
+		_, err = introspector.Introspect(context.Background(), "pat_abc")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, *requests)
+	})
+}
+
+func TestRemoteIntrospector_CheckActive(t *testing.T) {
+	server, requests := introspectionServer(t, map[string]introspectionResponse{
+		"active":  {Active: true},
+		"revoked": {Active: false},
+	})
+	introspector := NewRemoteIntrospector(server.URL, "blog-service", "secret", "auth-service")
+
+	assert.NoError(t, introspector.CheckActive(context.Background(), "active"))
+	assert.EqualError(t, introspector.CheckActive(context.Background(), "revoked"), constants.TokenInactive)
+	// revocations take effect at once, so nothing is cached
+	assert.NoError(t, introspector.CheckActive(context.Background(), "active"))
+	assert.Equal(t, 3, *requests)
+
+	wrongSecret := NewRemoteIntrospector(server.URL, "blog-service", "wrong", "auth-service")
+	assert.Error(t, wrongSecret.CheckActive(context.Background(), "active"))
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"blog-service/constants"
+)
+
+// jwk is a public JSON Web Key as published by auth-service.
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+}
+
+// publicKey is a parsed JWK together with the algorithm it may be used with.
+type publicKey struct {
+	key       crypto.PublicKey
+	algorithm string
+}
+
+// RemoteKeySet verifies tokens with the keys auth-service publishes at its JWKS endpoint.
+// Keys are cached and refetched periodically, or early when a token names an unknown kid.
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewRemoteKeySet returns a key set backed by the JWKS at url.
+func NewRemoteKeySet(url string) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:    url,
+		client: &http.Client{Timeout: constants.JWKSFetchTimeout},
+		keys:   map[string]publicKey{},
+	}
+}
+
+// PublicKey returns the key and algorithm registered for kid, refreshing the cache when needed.
+func (s *RemoteKeySet) PublicKey(kid string) (crypto.PublicKey, string, bool) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	stale := time.Since(s.fetchedAt) > constants.JWKSRefreshInterval
+	s.mu.RUnlock()
+
+	if ok && !stale {
+		return key.key, key.algorithm, true
+	}
+
+	s.refresh()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok = s.keys[kid]
+	return key.key, key.algorithm, ok
+}
+
+// Fetch loads the key set, so a misconfigured URL is noticed at startup.
+func (s *RemoteKeySet) Fetch() error {
+	keys, err := s.fetch()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.lastAttempt = s.fetchedAt
+	s.mu.Unlock()
+	return nil
+}
+
+// refresh refetches the key set unless that was attempted very recently. On failure the
+// previous keys stay in use so an auth-service outage does not reject every request.
+func (s *RemoteKeySet) refresh() {
+	s.mu.Lock()
+	if time.Since(s.lastAttempt) < constants.JWKSMinRefreshInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastAttempt = time.Now()
+	s.mu.Unlock()
+
+	_ = s.Fetch()
+}
+
+// fetch downloads and parses the key set. Keys of unsupported types are skipped.
+func (s *RemoteKeySet) fetch() (map[string]publicKey, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]publicKey, len(body.Keys))
+	for _, k := range body.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.KeyID] = publicKey{key: key, algorithm: k.Algorithm}
+	}
+	return keys, nil
+}
+
+// publicKey decodes the JWK into an RSA or Ed25519 public key.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.KeyType == "RSA" && k.Algorithm == "RS256":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.KeyType == "OKP" && k.Curve == "Ed25519" && k.Algorithm == "EdDSA":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwks: invalid Ed25519 key %s", k.KeyID)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("jwks: unsupported key %s", k.KeyID)
+	}
+}